@@ -24,10 +24,71 @@ Details about Procfile conventions can be found here: https://devcenter.heroku.c
 Deploy from an image:
   ketch app deploy <app name> -i myregistry/myimage:latest
 
+Set resource requests and limits of a process:
+  ketch app deploy <app name> -i myregistry/myimage:latest --process web --cpu 250m --memory 128Mi --memory-limit 512Mi
+
+Set health checks of routable processes, they replace the probes from ketch.yaml:
+  ketch app deploy <app name> -i myregistry/myimage:latest --readiness-http /healthz:8080 --liveness-tcp 8080,period=10,failure=3
+  Each probe flag accepts comma separated options after the target: delay, period, timeout, success and failure.
+  Quote exec commands with spaces or commas: --liveness-exec "sh -c 'pg_isready -h localhost',period=10"
+
+Expose an app with a named ingress profile created by "ketch ingress set --profile internal":
+  ketch app deploy <app name> -i myregistry/myimage:latest --ingress-profile internal
+
 Users can deploy from image or source code by passing a filename such as app.yaml containing fields like:
 	name: test
 	image: gcr.io/shipa-ci/sample-go-app:latest
 	namespace: mynamespace
+	ingressProfile: internal
+
+When deploying from source, the file can also configure processes:
+	processes:
+	  - name: web
+	    units: 2
+	    resources:
+	      requests:
+	        cpu: 250m
+	        memory: 128Mi
+	      limits:
+	        memory: 512Mi
+	    scheduling:
+	      priorityClassName: high-priority
+	    disruptionBudget:
+	      minAvailable: 50%
+	    strategy:
+	      maxSurge: 1
+	      maxUnavailable: 0
+	      minReadySeconds: 10
+	      progressDeadlineSeconds: 300
+	    initContainers:
+	      - name: wait-for-db
+	        image: busybox:1.36
+	        command: ["sh", "-c", "until nc -z db 5432; do sleep 1; done"]
+	    sidecars:
+	      - name: log-shipper
+	        image: fluent/fluent-bit:2.1
+
+A process running more than one unit gets a PodDisruptionBudget with maxUnavailable=1 by default,
+it can be turned off with "disruptionBudget: {disabled: true}".
+
+Probes can be set with a top-level "probes" field:
+	probes:
+	  readiness:
+	    http: /healthz:8080
+	    periodSeconds: 10
+	  liveness:
+	    tcp: "8080"
+	    failureThreshold: 3
+
+Default scheduling options for all processes can be set with a top-level "scheduling" field:
+	scheduling:
+	  tolerations:
+	    - key: spot
+	      operator: Exists
+	  topologySpreadConstraints:
+	    - maxSkew: 1
+	      topologyKey: topology.kubernetes.io/zone
+	      whenUnsatisfiable: ScheduleAnyway
 `
 )
 
@@ -80,6 +141,24 @@ func newAppDeployCmd(cfg config, params *deploy.Services, configDefaultBuilder s
 	cmd.Flags().IntVar(&options.Version, deploy.FlagVersion, 1, "Specify version whose units to update. Must be used with units flag!")
 	cmd.Flags().StringVar(&options.Process, deploy.FlagProcess, "", "Specify process whose units to update. Must be used with units flag!")
 
+	cmd.Flags().StringVar(&options.IngressProfile, deploy.FlagIngressProfile, "", "Name of an ingress profile to expose the app with, the default ingress is used if not set.")
+
+	cmd.Flags().StringVar(&options.TargetProcess, deploy.FlagTargetProcess, "", "Specify process whose resources to update. All processes are updated if not set.")
+	cmd.Flags().StringVar(&options.CPU, deploy.FlagCPU, "", "CPU request of the process, e.g. 250m.")
+	cmd.Flags().StringVar(&options.Memory, deploy.FlagMemory, "", "Memory request of the process, e.g. 128Mi.")
+	cmd.Flags().StringVar(&options.CPULimit, deploy.FlagCPULimit, "", "CPU limit of the process, e.g. 1.")
+	cmd.Flags().StringVar(&options.MemoryLimit, deploy.FlagMemoryLimit, "", "Memory limit of the process, e.g. 512Mi.")
+
+	cmd.Flags().StringVar(&options.ReadinessHTTP, deploy.FlagReadinessHTTP, "", "Readiness probe sending an http request to PATH:PORT, e.g. /healthz:8080.")
+	cmd.Flags().StringVar(&options.ReadinessTCP, deploy.FlagReadinessTCP, "", "Readiness probe opening a tcp connection to PORT.")
+	cmd.Flags().StringVar(&options.ReadinessExec, deploy.FlagReadinessExec, "", "Readiness probe running a command inside the container, arguments and commas can be quoted like in a shell.")
+	cmd.Flags().StringVar(&options.LivenessHTTP, deploy.FlagLivenessHTTP, "", "Liveness probe sending an http request to PATH:PORT, e.g. /healthz:8080.")
+	cmd.Flags().StringVar(&options.LivenessTCP, deploy.FlagLivenessTCP, "", "Liveness probe opening a tcp connection to PORT.")
+	cmd.Flags().StringVar(&options.LivenessExec, deploy.FlagLivenessExec, "", "Liveness probe running a command inside the container, arguments and commas can be quoted like in a shell.")
+	cmd.Flags().StringVar(&options.StartupHTTP, deploy.FlagStartupHTTP, "", "Startup probe sending an http request to PATH:PORT, e.g. /healthz:8080.")
+	cmd.Flags().StringVar(&options.StartupTCP, deploy.FlagStartupTCP, "", "Startup probe opening a tcp connection to PORT.")
+	cmd.Flags().StringVar(&options.StartupExec, deploy.FlagStartupExec, "", "Startup probe running a command inside the container, arguments and commas can be quoted like in a shell.")
+
 	cmd.RegisterFlagCompletionFunc(deploy.FlagNamespace, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return autoCompleteNamespaces(cfg, toComplete)
 	})
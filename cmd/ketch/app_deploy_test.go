@@ -13,6 +13,7 @@ import (
 	registryv1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -97,6 +98,14 @@ func (m *mockClient) Update(ctx context.Context, obj client.Object, opts ...clie
 	panic("unhandled type")
 }
 
+func (m *mockClient) List(_ context.Context, list client.ObjectList, _ ...client.ListOption) error {
+	switch list.(type) {
+	case *ketchv1.AppList:
+		return nil
+	}
+	panic("unhandled type")
+}
+
 type packMocker struct{}
 
 func (packMocker) BuildAndPushImage(ctx context.Context, req pack.BuildRequest) error {
@@ -460,7 +469,7 @@ func TestNewCommand(t *testing.T) {
 							Processes:       nil,
 							KetchYaml:       nil,
 							Labels:          nil,
-							RoutingSettings: ketchv1.RoutingSettings{},
+							RoutingSettings: ketchv1.RoutingSettings{Weight: 100},
 							ExposedPorts:    nil,
 						},
 					}
@@ -928,6 +937,65 @@ func TestNewCommand(t *testing.T) {
 				Writer:         &bytes.Buffer{},
 			},
 		},
+		{
+			name: "happy path with --ingress-profile build from image",
+			arguments: []string{
+				"myapp",
+				"--image", "shipa/go-sample:latest",
+				"--namespace", "initialnamespace",
+				"--ingress-profile", "internal",
+			},
+			validate: func(t *testing.T, mock *mockClient) {
+				want := ketchv1.IngressControllerSpec{
+					ClassName:       "nginx-internal",
+					ServiceEndpoint: "10.0.0.1",
+					IngressType:     ketchv1.NginxIngressControllerType,
+					Profile:         "internal",
+				}
+				require.Equal(t, want, mock.app.Spec.Ingress.Controller)
+			},
+			params: &deploy.Services{
+				Client: func() *mockClient {
+					m := newMockClient()
+					m.get[2] = func(_ *mockClient, obj runtime.Object) error {
+						configmap := obj.(*corev1.ConfigMap)
+						configmap.Name = "ketch-ingress-internal"
+						configmap.Data = map[string]string{"className": "nginx-internal", "serviceEndpoint": "10.0.0.1", "ingressType": "nginx"}
+						return nil
+					}
+					return m
+				}(),
+				KubeClient:     fake.NewSimpleClientset(),
+				Builder:        build.GetSourceHandler(&packMocker{}),
+				GetImageConfig: getImageConfig,
+				Wait:           nil,
+				Writer:         &bytes.Buffer{},
+			},
+		},
+		{
+			name:      "unknown ingress profile",
+			wantError: true,
+			arguments: []string{
+				"myapp",
+				"--image", "shipa/go-sample:latest",
+				"--namespace", "initialnamespace",
+				"--ingress-profile", "internal",
+			},
+			params: &deploy.Services{
+				Client: func() *mockClient {
+					m := newMockClient()
+					m.get[2] = func(_ *mockClient, _ runtime.Object) error {
+						return errors.NewNotFound(v1.Resource("configmaps"), "ketch-ingress-internal")
+					}
+					return m
+				}(),
+				KubeClient:     fake.NewSimpleClientset(),
+				Builder:        build.GetSourceHandler(&packMocker{}),
+				GetImageConfig: getImageConfig,
+				Wait:           nil,
+				Writer:         &bytes.Buffer{},
+			},
+		},
 	}
 
 	for _, tc := range tt {
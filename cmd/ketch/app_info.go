@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"html/template"
 	"io"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	corev1 "k8s.io/api/core/v1"
@@ -16,6 +18,7 @@ import (
 
 	"github.com/theketchio/ketch/cmd/ketch/output"
 	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
+	"github.com/theketchio/ketch/internal/chart"
 	"github.com/theketchio/ketch/internal/utils"
 )
 
@@ -38,6 +41,24 @@ Address: {{ $address }}
 {{- else }}
 The default cname hasn't assigned yet because cluster doesn't have ingress service endpoint.
 {{- end }}
+{{- if .Routes }}
+URL map:
+{{- range .Routes }}
+  {{ .URL }} -> {{ .Process }}
+{{- end }}
+{{- end }}
+{{- if .Certificates }}
+Certificates:
+{{- range .Certificates }}
+  {{ .Cname }} ({{ .SecretName }}) expires {{ .Expires }}
+{{- end }}
+{{- end }}
+{{- if .Endpoints }}
+Endpoints:
+{{- range .Endpoints }}
+  {{ .Process }} ({{ .Mode }}) -> {{ .Endpoint }}
+{{- end }}
+{{- end }}
 {{- if .App.Spec.DockerRegistry.SecretName }}
 Secret name to pull application's images: {{ .App.Spec.DockerRegistry.SecretName }}
 {{- end }}
@@ -53,9 +74,32 @@ No environment variables.
 )
 
 type appInfoContext struct {
-	App         ketchv1.App `json:"app" yaml:"app"`
-	Cnames      []string    `json:"cnames" yaml:"cnames"`
-	NoProcesses bool        `json:"noProcesses" yaml:"noProcesses"`
+	App          ketchv1.App        `json:"app" yaml:"app"`
+	Cnames       []string           `json:"cnames" yaml:"cnames"`
+	Routes       []urlRoute         `json:"routes,omitempty" yaml:"routes,omitempty"`
+	Certificates []cnameCertificate `json:"certificates,omitempty" yaml:"certificates,omitempty"`
+	Endpoints    []processEndpoint  `json:"endpoints,omitempty" yaml:"endpoints,omitempty"`
+	NoProcesses  bool               `json:"noProcesses" yaml:"noProcesses"`
+}
+
+// processEndpoint shows where a process exposed with an exposure mode can be reached.
+type processEndpoint struct {
+	Process  string `json:"process" yaml:"process"`
+	Mode     string `json:"mode" yaml:"mode"`
+	Endpoint string `json:"endpoint" yaml:"endpoint"`
+}
+
+// cnameCertificate shows when a user provided certificate of a cname expires.
+type cnameCertificate struct {
+	Cname      string `json:"cname" yaml:"cname"`
+	SecretName string `json:"secretName" yaml:"secretName"`
+	Expires    string `json:"expires" yaml:"expires"`
+}
+
+// urlRoute shows which process handles requests to a url.
+type urlRoute struct {
+	URL     string `json:"url" yaml:"url"`
+	Process string `json:"process" yaml:"process"`
 }
 
 type appInfoOutput struct {
@@ -69,6 +113,8 @@ type deploymentOutput struct {
 	ProcessName       string `json:"processName" yaml:"processName"`
 	Weight            string `json:"weight" yaml:"weight"`
 	State             string `json:"state" yaml:"state"`
+	Requests          string `json:"requests" yaml:"requests"`
+	Limits            string `json:"limits" yaml:"limits"`
 	Cmd               string `json:"cmd" yaml:"cmd"`
 }
 
@@ -111,7 +157,7 @@ func appInfo(ctx context.Context, cfg config, options appInfoOptions, out io.Wri
 		return err
 	}
 
-	data := generateAppInfoOutput(app, appPods)
+	data := generateAppInfoOutput(app, appPods, cnameCertificates(ctx, cfg, app), processEndpoints(ctx, cfg, app))
 
 	buf := bytes.Buffer{}
 	t := template.Must(template.New("app-info").Parse(appInfoTemplate))
@@ -124,27 +170,34 @@ func appInfo(ctx context.Context, cfg config, options appInfoOptions, out io.Wri
 
 }
 
-func generateAppInfoOutput(app ketchv1.App, appPods *v1.PodList) appInfoOutput {
+func generateAppInfoOutput(app ketchv1.App, appPods *v1.PodList, certificates []cnameCertificate, endpoints []processEndpoint) appInfoOutput {
 	noProcesses := true
 	var deployments []deploymentOutput
 	for _, deployment := range app.Spec.Deployments {
 		for _, process := range deployment.Processes {
 			noProcesses = false
-			state := appState(filterProcessDeploymentPods(appPods.Items, deployment.Version.String(), process.Name))
+			pods := filterProcessDeploymentPods(appPods.Items, deployment.Version.String(), process.Name)
+			state := appState(pods)
+			resources := effectiveResources(process, pods)
 			deployments = append(deployments, deploymentOutput{
 				DeploymentVersion: deployment.Version.String(),
 				Image:             deployment.Image,
 				ProcessName:       process.Name,
 				Weight:            fmt.Sprintf("%v%%", deployment.RoutingSettings.Weight),
 				State:             state,
+				Requests:          formatResourceList(resources.Requests),
+				Limits:            formatResourceList(resources.Limits),
 				Cmd:               strings.Join(process.Cmd, " "),
 			})
 		}
 	}
 	infoContext := appInfoContext{
-		App:         app,
-		Cnames:      app.CNames(),
-		NoProcesses: noProcesses,
+		App:          app,
+		Cnames:       app.CNames(),
+		Routes:       urlMap(app),
+		Certificates: certificates,
+		Endpoints:    endpoints,
+		NoProcesses:  noProcesses,
 	}
 
 	return appInfoOutput{
@@ -152,6 +205,171 @@ func generateAppInfoOutput(app ketchv1.App, appPods *v1.PodList) appInfoOutput {
 	}
 }
 
+// urlMap returns processes handling requests to each url of the app.
+// It returns nil if no cname of the app has routes, because all requests are handled by the routable process.
+func urlMap(app ketchv1.App) []urlRoute {
+	hasRoutes := false
+	for _, cname := range app.Spec.Ingress.Cnames {
+		if len(cname.Routes) > 0 {
+			hasRoutes = true
+		}
+	}
+	if !hasRoutes {
+		return nil
+	}
+	routableProcess := "-"
+	if len(app.Spec.Deployments) > 0 {
+		procfile, err := chart.ProcfileFromProcesses(app.Spec.Deployments[len(app.Spec.Deployments)-1].Processes)
+		if err == nil {
+			routableProcess = procfile.RoutableProcessName
+		}
+	}
+	var routes []urlRoute
+	if url := app.DefaultCnameURL(); len(url) > 0 {
+		routes = append(routes, urlRoute{URL: url + "/", Process: routableProcess})
+	}
+	for _, cname := range app.Spec.Ingress.Cnames {
+		scheme := "http"
+		if cname.Secure {
+			scheme = "https"
+		}
+		for _, route := range cname.OrderedRoutes(routableProcess) {
+			process := route.Process
+			if route.Port > 0 {
+				process = fmt.Sprintf("%s:%d", route.Process, route.Port)
+			}
+			routes = append(routes, urlRoute{
+				URL:     fmt.Sprintf("%s://%s%s", scheme, cname.Name, route.PathPrefix),
+				Process: process,
+			})
+		}
+	}
+	return routes
+}
+
+// cnameCertificates returns expiry dates of certificates stored in secrets of the app's cnames.
+// A secret that can't be read is reported instead of failing the whole command.
+func cnameCertificates(ctx context.Context, cfg config, app ketchv1.App) []cnameCertificate {
+	var certificates []cnameCertificate
+	for _, cname := range app.Spec.Ingress.Cnames {
+		if !cname.Secure || len(cname.SecretName) == 0 {
+			continue
+		}
+		expires := "unknown"
+		if cert, err := getSecretCertificate(ctx, cfg, app.Spec.Namespace, cname.SecretName); err == nil {
+			expires = cert.NotAfter.UTC().Format(time.RFC3339)
+		}
+		certificates = append(certificates, cnameCertificate{
+			Cname:      cname.Name,
+			SecretName: cname.SecretName,
+			Expires:    expires,
+		})
+	}
+	return certificates
+}
+
+// processEndpoints returns where each process of the most recent deployment exposed with an exposure mode can be reached.
+// Addresses assigned by the cluster are read from the process's Service, "pending" is reported until they are known.
+func processEndpoints(ctx context.Context, cfg config, app ketchv1.App) []processEndpoint {
+	if len(app.Spec.Deployments) == 0 {
+		return nil
+	}
+	latest := app.Spec.Deployments[len(app.Spec.Deployments)-1]
+	var endpoints []processEndpoint
+	for _, process := range latest.Processes {
+		exposure := latest.ProcessExposure(process.Name)
+		if exposure == nil {
+			continue
+		}
+		name := chart.ExposedServiceName(app.Name, process.Name)
+		service := corev1.Service{}
+		var ports []corev1.ServicePort
+		if err := cfg.Client().Get(ctx, types.NamespacedName{Namespace: app.Spec.Namespace, Name: name}, &service); err == nil {
+			ports = service.Spec.Ports
+		}
+		endpoint := "pending"
+		switch exposure.Mode {
+		case ketchv1.ExposureNone:
+			endpoint = "-"
+		case ketchv1.ExposureClusterInternal:
+			endpoint = fmt.Sprintf("%s.%s.svc", name, app.Spec.Namespace)
+			if len(ports) > 0 {
+				endpoint = fmt.Sprintf("%s:%d", endpoint, ports[0].Port)
+			}
+		case ketchv1.ExposureLoadBalancer:
+			if lbs := service.Status.LoadBalancer.Ingress; len(lbs) > 0 && len(ports) > 0 {
+				address := lbs[0].IP
+				if len(address) == 0 {
+					address = lbs[0].Hostname
+				}
+				endpoint = fmt.Sprintf("%s:%d", address, ports[0].Port)
+			}
+		case ketchv1.ExposureNodePort:
+			if len(ports) > 0 && ports[0].NodePort > 0 {
+				endpoint = fmt.Sprintf("NODE_IP:%d", ports[0].NodePort)
+			}
+		case ketchv1.ExposureTCPRoute, ketchv1.ExposureUDPRoute:
+			scheme := "tcp"
+			if exposure.Mode == ketchv1.ExposureUDPRoute {
+				scheme = "udp"
+			}
+			address := app.Spec.Ingress.Controller.ServiceEndpoint
+			if len(address) == 0 {
+				address = "INGRESS_IP"
+			}
+			if exposure.ListenPort > 0 {
+				endpoint = fmt.Sprintf("%s://%s:%d", scheme, address, exposure.ListenPort)
+			} else {
+				endpoint = fmt.Sprintf("%s://%s (entry point %s)", scheme, address, exposure.EntryPoint)
+			}
+		}
+		endpoints = append(endpoints, processEndpoint{
+			Process:  process.Name,
+			Mode:     string(exposure.Mode),
+			Endpoint: endpoint,
+		})
+	}
+	return endpoints
+}
+
+// effectiveResources returns the resources of the app container of a running pod,
+// they include defaults applied by the cluster (e.g. by a LimitRange).
+// If there is no pod, the resources from the process spec are returned.
+func effectiveResources(process ketchv1.ProcessSpec, pods []corev1.Pod) corev1.ResourceRequirements {
+	for _, pod := range pods {
+		containerName, err := ketchContainerName(pod)
+		if err != nil {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			if container.Name == *containerName {
+				return container.Resources
+			}
+		}
+	}
+	if process.Resources != nil {
+		return *process.Resources
+	}
+	return corev1.ResourceRequirements{}
+}
+
+func formatResourceList(list corev1.ResourceList) string {
+	if len(list) == 0 {
+		return "-"
+	}
+	names := make([]string, 0, len(list))
+	for name := range list {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+	items := make([]string, 0, len(names))
+	for _, name := range names {
+		quantity := list[corev1.ResourceName(name)]
+		items = append(items, fmt.Sprintf("%s=%s", name, quantity.String()))
+	}
+	return strings.Join(items, ",")
+}
+
 func filterProcessDeploymentPods(appPods []corev1.Pod, version, process string) []corev1.Pod {
 	var pods []corev1.Pod
 	for _, pod := range appPods {
@@ -5,8 +5,11 @@ import (
 	"context"
 	"io/ioutil"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
@@ -51,6 +54,15 @@ func Test_appInfo(t *testing.T) {
 						{
 							Name: "web",
 							Cmd:  []string{"docker-entrypoint.sh", "npm", "start"},
+							Resources: &corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("250m"),
+									corev1.ResourceMemory: resource.MustParse("128Mi"),
+								},
+								Limits: corev1.ResourceList{
+									corev1.ResourceMemory: resource.MustParse("512Mi"),
+								},
+							},
 						},
 						{
 							Name: "worker",
@@ -98,6 +110,36 @@ func Test_appInfo(t *testing.T) {
 			},
 		},
 	}
+	goAppWithRoutes := goApp.DeepCopy()
+	goAppWithRoutes.Spec.Ingress.Cnames = ketchv1.CnameList{
+		{Name: "theketch.io", Routes: []ketchv1.CnameRoute{{PathPrefix: "/jobs", Process: "worker"}}},
+		{Name: "worker.theketch.io", Secure: true, Routes: []ketchv1.CnameRoute{{PathPrefix: "/", Process: "worker", Port: 9090}}},
+	}
+	goAppWithCertificate := goApp.DeepCopy()
+	goAppWithCertificate.Spec.Ingress.Cnames = ketchv1.CnameList{
+		{Name: "www.theketch.io", Secure: true, SecretName: "www-theketch-io"},
+		{Name: "api.theketch.io", Secure: true, SecretName: "api-theketch-io"},
+	}
+	certPEM, keyPEM := newTestCertificate(t, []string{"www.theketch.io"},
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	certificateSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "www-theketch-io", Namespace: "aws"},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{corev1.TLSCertKey: certPEM, corev1.TLSPrivateKeyKey: keyPEM},
+	}
+	goAppWithExposure := goApp.DeepCopy()
+	goAppWithExposure.Spec.Deployments[0].Processes[0].Exposure = &ketchv1.ExposureSpec{Mode: ketchv1.ExposureLoadBalancer}
+	goAppWithExposure.Spec.Deployments[0].Processes[1].Exposure = &ketchv1.ExposureSpec{Mode: ketchv1.ExposureTCPRoute, ListenPort: 5432}
+	loadBalancerService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "go-app-web-exposed", Namespace: "aws"},
+		Spec: corev1.ServiceSpec{
+			Type:  corev1.ServiceTypeLoadBalancer,
+			Ports: []corev1.ServicePort{{Port: 8080}},
+		},
+		Status: corev1.ServiceStatus{
+			LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{{IP: "34.1.2.3"}}},
+		},
+	}
 	tests := []struct {
 		name               string
 		cfg                config
@@ -135,6 +177,36 @@ func Test_appInfo(t *testing.T) {
 			},
 			wantOutputFilename: "./testdata/app-info/go-app-secret-name.output",
 		},
+		{
+			name: "cnames with routes",
+			cfg: &mocks.Configuration{
+				CtrlClientObjects: []runtime.Object{goAppWithRoutes},
+			},
+			options: appInfoOptions{
+				name: "go-app",
+			},
+			wantOutputFilename: "./testdata/app-info/go-app-routes.output",
+		},
+		{
+			name: "cnames with certificates",
+			cfg: &mocks.Configuration{
+				CtrlClientObjects: []runtime.Object{goAppWithCertificate, certificateSecret},
+			},
+			options: appInfoOptions{
+				name: "go-app",
+			},
+			wantOutputFilename: "./testdata/app-info/go-app-certificates.output",
+		},
+		{
+			name: "processes with exposure",
+			cfg: &mocks.Configuration{
+				CtrlClientObjects: []runtime.Object{goAppWithExposure, loadBalancerService},
+			},
+			options: appInfoOptions{
+				name: "go-app",
+			},
+			wantOutputFilename: "./testdata/app-info/go-app-exposure.output",
+		},
 		{
 			name: "app with builder",
 			cfg: &mocks.Configuration{
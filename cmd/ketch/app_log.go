@@ -51,6 +51,7 @@ func newAppLogCmd(cfg config, out io.Writer, appLog appLogFn) *cobra.Command {
 	}
 	cmd.Flags().StringVarP(&options.processName, "process", "p", "", "Process name")
 	cmd.Flags().IntVarP(&options.deploymentVersion, "version", "v", 0, "Deployment version")
+	cmd.Flags().StringVarP(&options.container, "container", "c", "", "Container name, logs of the app container are shown by default")
 	cmd.Flags().BoolVarP(&options.follow, "follow", "f", false, "Specify if the logs should be streamed")
 	cmd.Flags().BoolVar(&options.ignoreErrors, "ignore-errors", false, "If watching / following pod logs, allow for any errors that occur to be non-fatal")
 	cmd.Flags().BoolVar(&options.prefix, "prefix", false, "Prefix each log line with the log source (pod name and container name)")
@@ -63,6 +64,7 @@ type appLogOptions struct {
 	appName           string
 	processName       string
 	deploymentVersion int
+	container         string
 	follow            bool
 	ignoreErrors      bool
 	timestamps        bool
@@ -89,6 +91,7 @@ func appLog(ctx context.Context, cfg config, options appLogOptions, out io.Write
 	opts := watchOptions{
 		namespace:    app.Spec.Namespace,
 		selector:     s,
+		container:    options.container,
 		follow:       options.follow,
 		ignoreErrors: options.ignoreErrors,
 		timestamps:   options.timestamps,
@@ -101,6 +104,7 @@ func appLog(ctx context.Context, cfg config, options appLogOptions, out io.Write
 type watchOptions struct {
 	namespace    string
 	selector     labels.Selector
+	container    string
 	follow       bool
 	ignoreErrors bool
 	timestamps   bool
@@ -113,17 +117,36 @@ type watchOptions struct {
 // The others can be injected by istio, vault, etc.
 func ketchContainerName(pod corev1.Pod) (*string, error) {
 	// this is an application pod.
-	// it has one app container, we don't care about the other containers.
-	for _, c := range pod.Spec.Containers {
-		if strings.HasPrefix(pod.Name, c.Name) {
-			return &c.Name, nil
+	// its name starts with the name of the app container, we don't care about the other containers.
+	// if a sidecar's name is a prefix of the pod's name too, the app container has the longest one.
+	var name *string
+	for i, c := range pod.Spec.Containers {
+		if strings.HasPrefix(pod.Name, c.Name) && (name == nil || len(c.Name) > len(*name)) {
+			name = &pod.Spec.Containers[i].Name
 		}
 	}
-	return nil, fmt.Errorf("pod %s doesn't have an app container", pod.Name)
+	if name == nil {
+		return nil, fmt.Errorf("pod %s doesn't have an app container", pod.Name)
+	}
+	return name, nil
+}
+
+// containerName returns a name of the container to show logs of.
+// It's the app container unless another container is requested.
+func (o watchOptions) containerName(pod corev1.Pod) (*string, error) {
+	if len(o.container) == 0 {
+		return ketchContainerName(pod)
+	}
+	for _, c := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+		if c.Name == o.container {
+			return &o.container, nil
+		}
+	}
+	return nil, fmt.Errorf("pod %s doesn't have a container %s", pod.Name, o.container)
 }
 
 func isContainerRunning(pod corev1.Pod, containerName string) bool {
-	for _, container := range pod.Status.ContainerStatuses {
+	for _, container := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
 		if container.Name == containerName {
 			return container.State.Running != nil
 		}
@@ -142,7 +165,7 @@ func watchLogs(cli kubernetes.Interface, options watchOptions, readLogs readLogs
 	// we are going to read logs from all running pods, just read without streaming.
 	msgChs := make(map[types.UID]chan logMessage, len(pods.Items))
 	for _, pod := range pods.Items {
-		containerName, err := ketchContainerName(pod)
+		containerName, err := options.containerName(pod)
 		if err != nil {
 			return err
 		}
@@ -218,7 +241,7 @@ func watchLogs(cli kubernetes.Interface, options watchOptions, readLogs readLogs
 				if _, ok := doneChannels[pod.UID]; ok {
 					continue
 				}
-				containerName, err := ketchContainerName(*pod)
+				containerName, err := options.containerName(*pod)
 				if err != nil {
 					if !options.ignoreErrors {
 						return err
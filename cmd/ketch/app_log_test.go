@@ -184,6 +184,32 @@ func Test_watchLogs(t *testing.T) {
 			},
 			wantErr: "pod hello-web-1-random doesn't have an app container",
 		},
+		{
+			description: "happy path - logs from a sidecar container, + prefix",
+			options: watchOptions{
+				namespace: "default",
+				selector:  labels.Everything(),
+				container: "log-shipper",
+				prefix:    true,
+			},
+			pods: []*corev1.Pod{
+				createPod("default", "hello-web-1-random", map[string]bool{"hello-web-1": true, "log-shipper": true}, startDate),
+				createPod("default", "hello-web-2-random", map[string]bool{"hello-web-2": true, "log-shipper": true}, startDate.Add(time.Second)),
+			},
+			wantOutputFilename: "./testdata/app-log/6.output",
+		},
+		{
+			description: "requested container not found",
+			options: watchOptions{
+				namespace: "default",
+				selector:  labels.Everything(),
+				container: "log-shipper",
+			},
+			pods: []*corev1.Pod{
+				createPod("default", "hello-web-1-random", map[string]bool{"hello-web-1": true}, startDate),
+			},
+			wantErr: "pod hello-web-1-random doesn't have a container log-shipper",
+		},
 		{
 			description: "happy path with streaming: prefix + timestamps",
 			options: watchOptions{
@@ -262,6 +288,22 @@ func Test_watchLogs(t *testing.T) {
 	}
 }
 
+func Test_ketchContainerName(t *testing.T) {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "hello-web-1-6b7d4c9f5-x2x8w"},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "hello"},
+				{Name: "hello-web-1"},
+				{Name: "istio-proxy"},
+			},
+		},
+	}
+	name, err := ketchContainerName(pod)
+	require.Nil(t, err)
+	require.Equal(t, "hello-web-1", *name)
+}
+
 func Test_appLog(t *testing.T) {
 	dashboard := &ketchv1.App{
 		ObjectMeta: metav1.ObjectMeta{
@@ -353,6 +395,21 @@ func Test_appLog(t *testing.T) {
 			options: appLogOptions{appName: "dashboard"},
 			wantErr: `failed to get app instance: apps.theketch.io "dashboard" not found`,
 		},
+		{
+			description: "happy path: app selector + container",
+			cfg: &mocks.Configuration{
+				CtrlClientObjects: []runtime.Object{dashboard},
+			},
+			options:    appLogOptions{appName: "dashboard", container: "log-shipper"},
+			wantCalled: true,
+			wantWatchOptions: watchOptions{
+				namespace: "ketch-gke",
+				selector: labels.SelectorFromSet(map[string]string{
+					utils.KetchAppNameLabel: "dashboard",
+				}),
+				container: "log-shipper",
+			},
+		},
 		{
 			description: "error from watchLog",
 			cfg: &mocks.Configuration{
@@ -451,6 +508,14 @@ func Test_newAppLogCmd(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			description: "happy path: container",
+			args:        []string{"ketch", "dashboard", "--container=log-shipper"},
+			appLog: func(ctx context.Context, c config, options appLogOptions, writer io.Writer, fn watchLogsFn) error {
+				require.Equal(t, appLogOptions{container: "log-shipper", appName: "dashboard"}, options)
+				return nil
+			},
+		},
 		{
 			description: "bad app name",
 			args:        []string{"ketch", "_._"},
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
+	"github.com/theketchio/ketch/internal/utils"
+)
+
+const (
+	// nginxBasicAuthKey is the key of a Secret nginx reads htpasswd users from.
+	nginxBasicAuthKey = "auth"
+	// traefikBasicAuthKey is the key of a Secret traefik reads htpasswd users from.
+	traefikBasicAuthKey = "users"
+)
+
+// cnameBasicAuthSecretName returns a name of a secret to store htpasswd users of the cname.
+func cnameBasicAuthSecretName(appName, cname string) string {
+	return fmt.Sprintf("%s-basic-auth-%s", appName, regexp.MustCompile("[^a-z0-9]+").ReplaceAllString(cname, "-"))
+}
+
+// loadHtpasswd reads a htpasswd file and checks that each line has the "user:hash" format.
+func loadHtpasswd(filename string) ([]byte, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read htpasswd file: %w", err)
+	}
+	users := 0
+	for i, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		user, hash, found := strings.Cut(line, ":")
+		if !found || len(user) == 0 || len(hash) == 0 {
+			return nil, fmt.Errorf("invalid htpasswd file: line %d should have the user:hash format", i+1)
+		}
+		users++
+	}
+	if users == 0 {
+		return nil, fmt.Errorf("invalid htpasswd file: no users found")
+	}
+	return bytes.TrimSpace(content), nil
+}
+
+// applyBasicAuthSecret creates or updates a secret with htpasswd users under the keys read by nginx and traefik.
+// Only secrets labelled for the app are updated, so htpasswd users of other apps aren't overwritten.
+func applyBasicAuthSecret(ctx context.Context, cfg config, app ketchv1.App, name string, htpasswd []byte) error {
+	secret := corev1.Secret{}
+	err := cfg.Client().Get(ctx, types.NamespacedName{Namespace: app.Spec.Namespace, Name: name}, &secret)
+	if k8serrors.IsNotFound(err) {
+		secret = corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: app.Spec.Namespace,
+				Labels:    map[string]string{utils.KetchAppNameLabel: app.Name},
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: map[string][]byte{
+				nginxBasicAuthKey:   htpasswd,
+				traefikBasicAuthKey: htpasswd,
+			},
+		}
+		if err := cfg.Client().Create(ctx, &secret); err != nil {
+			return fmt.Errorf("failed to create secret %s/%s: %w", app.Spec.Namespace, name, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get secret %s/%s: %w", app.Spec.Namespace, name, err)
+	}
+	if secret.Type != corev1.SecretTypeOpaque {
+		return fmt.Errorf("secret %s/%s has type %s, expected %s", app.Spec.Namespace, name, secret.Type, corev1.SecretTypeOpaque)
+	}
+	if secret.Labels[utils.KetchAppNameLabel] != app.Name {
+		return fmt.Errorf("secret %s/%s doesn't belong to the app %s, choose another secret name", app.Spec.Namespace, name, app.Name)
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[nginxBasicAuthKey] = htpasswd
+	secret.Data[traefikBasicAuthKey] = htpasswd
+	if err := cfg.Client().Update(ctx, &secret); err != nil {
+		return fmt.Errorf("failed to update secret %s/%s: %w", app.Spec.Namespace, name, err)
+	}
+	return nil
+}
+
+// checkBasicAuthSecret returns an error if the secret doesn't exist or has neither nginx nor traefik htpasswd users.
+func checkBasicAuthSecret(ctx context.Context, cfg config, namespace, name string) error {
+	secret := corev1.Secret{}
+	if err := cfg.Client().Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &secret); err != nil {
+		return fmt.Errorf("failed to get secret %s/%s: %w", namespace, name, err)
+	}
+	if len(secret.Data[nginxBasicAuthKey]) == 0 && len(secret.Data[traefikBasicAuthKey]) == 0 {
+		return fmt.Errorf("secret %s/%s doesn't have htpasswd users in the %q or %q key", namespace, name, nginxBasicAuthKey, traefikBasicAuthKey)
+	}
+	return nil
+}
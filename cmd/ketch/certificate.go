@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
+	"github.com/theketchio/ketch/internal/utils"
+)
+
+// tlsCertificate is a certificate and its private key provided by a user to serve a cname.
+type tlsCertificate struct {
+	certPEM []byte
+	keyPEM  []byte
+	leaf    *x509.Certificate
+}
+
+// loadTLSCertificate reads a PEM encoded certificate chain and its private key from files
+// and checks that the private key matches the certificate.
+func loadTLSCertificate(certFile, keyFile string) (*tlsCertificate, error) {
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the certificate: %w", err)
+	}
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the private key: %w", err)
+	}
+	if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		return nil, fmt.Errorf("invalid certificate or private key: %w", err)
+	}
+	leaf, err := parseCertificate(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	return &tlsCertificate{certPEM: certPEM, keyPEM: keyPEM, leaf: leaf}, nil
+}
+
+// parseCertificate returns the first certificate of a PEM encoded certificate chain.
+func parseCertificate(certPEM []byte) (*x509.Certificate, error) {
+	for {
+		var block *pem.Block
+		block, certPEM = pem.Decode(certPEM)
+		if block == nil {
+			return nil, errors.New("no certificate found in PEM data")
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the certificate: %w", err)
+		}
+		return cert, nil
+	}
+}
+
+// validateCertificate checks that the certificate is valid for the hostname at the given time.
+func validateCertificate(cert *x509.Certificate, hostname string, now time.Time) error {
+	if err := cert.VerifyHostname(hostname); err != nil {
+		return fmt.Errorf("certificate is not valid for %s: %w", hostname, err)
+	}
+	if now.After(cert.NotAfter) {
+		return fmt.Errorf("certificate for %s expired on %s", hostname, cert.NotAfter.UTC().Format(time.RFC3339))
+	}
+	if now.Before(cert.NotBefore) {
+		return fmt.Errorf("certificate for %s is not valid before %s", hostname, cert.NotBefore.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// getSecretCertificate returns the certificate stored in a TLS secret.
+func getSecretCertificate(ctx context.Context, cfg config, namespace, name string) (*x509.Certificate, error) {
+	secret := corev1.Secret{}
+	if err := cfg.Client().Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &secret); err != nil {
+		return nil, fmt.Errorf("failed to get secret %s/%s: %w", namespace, name, err)
+	}
+	certPEM, ok := secret.Data[corev1.TLSCertKey]
+	if !ok {
+		return nil, fmt.Errorf("secret %s/%s doesn't contain %s", namespace, name, corev1.TLSCertKey)
+	}
+	cert, err := parseCertificate(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("secret %s/%s: %w", namespace, name, err)
+	}
+	return cert, nil
+}
+
+// applyTLSSecret creates or updates a TLS secret with the certificate in the namespace of the app.
+// Only secrets labelled for the app are updated, so certificates of other apps and cert-manager aren't overwritten.
+func applyTLSSecret(ctx context.Context, cfg config, app ketchv1.App, name string, cert *tlsCertificate) error {
+	data := map[string][]byte{
+		corev1.TLSCertKey:       cert.certPEM,
+		corev1.TLSPrivateKeyKey: cert.keyPEM,
+	}
+	secret := corev1.Secret{}
+	err := cfg.Client().Get(ctx, types.NamespacedName{Namespace: app.Spec.Namespace, Name: name}, &secret)
+	if k8serrors.IsNotFound(err) {
+		secret = corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: app.Spec.Namespace,
+				Labels:    map[string]string{utils.KetchAppNameLabel: app.Name},
+			},
+			Type: corev1.SecretTypeTLS,
+			Data: data,
+		}
+		if err := cfg.Client().Create(ctx, &secret); err != nil {
+			return fmt.Errorf("failed to create secret %s/%s: %w", app.Spec.Namespace, name, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get secret %s/%s: %w", app.Spec.Namespace, name, err)
+	}
+	if secret.Type != corev1.SecretTypeTLS {
+		return fmt.Errorf("secret %s/%s has type %s, expected %s", app.Spec.Namespace, name, secret.Type, corev1.SecretTypeTLS)
+	}
+	if secret.Labels[utils.KetchAppNameLabel] != app.Name {
+		return fmt.Errorf("secret %s/%s doesn't belong to the app %s, choose another secret name", app.Spec.Namespace, name, app.Name)
+	}
+	secret.Data = data
+	if err := cfg.Client().Update(ctx, &secret); err != nil {
+		return fmt.Errorf("failed to update secret %s/%s: %w", app.Spec.Namespace, name, err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCertificate returns a PEM encoded self-signed certificate for the hosts and its private key.
+func newTestCertificate(t *testing.T, hosts []string, notBefore, notAfter time.Time) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: hosts[0]},
+		DNSNames:     hosts,
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	require.Nil(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.Nil(t, err)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM
+}
+
+// writeTestCertificate writes a certificate and its private key to files in a temporary directory.
+func writeTestCertificate(t *testing.T, certPEM, keyPEM []byte) (string, string) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	require.Nil(t, os.WriteFile(certFile, certPEM, 0600))
+	require.Nil(t, os.WriteFile(keyFile, keyPEM, 0600))
+	return certFile, keyFile
+}
+
+func TestValidateCertificate(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	certPEM, _ := newTestCertificate(t, []string{"*.theketch.io"}, now.AddDate(0, -1, 0), now.AddDate(0, 1, 0))
+	cert, err := parseCertificate(certPEM)
+	require.Nil(t, err)
+
+	require.Nil(t, validateCertificate(cert, "www.theketch.io", now))
+	require.EqualError(t, validateCertificate(cert, "theketch.io", now), "certificate is not valid for theketch.io: x509: certificate is valid for *.theketch.io, not theketch.io")
+	require.EqualError(t, validateCertificate(cert, "www.theketch.io", now.AddDate(0, 2, 0)), "certificate for www.theketch.io expired on 2024-07-01T00:00:00Z")
+	require.EqualError(t, validateCertificate(cert, "www.theketch.io", now.AddDate(0, -2, 0)), "certificate for www.theketch.io is not valid before 2024-05-01T00:00:00Z")
+}
+
+func TestLoadTLSCertificate(t *testing.T) {
+	now := time.Now()
+	certPEM, keyPEM := newTestCertificate(t, []string{"theketch.io"}, now.Add(-time.Hour), now.Add(time.Hour))
+	_, otherKeyPEM := newTestCertificate(t, []string{"theketch.io"}, now.Add(-time.Hour), now.Add(time.Hour))
+
+	certFile, keyFile := writeTestCertificate(t, certPEM, keyPEM)
+	cert, err := loadTLSCertificate(certFile, keyFile)
+	require.Nil(t, err)
+	require.Equal(t, []string{"theketch.io"}, cert.leaf.DNSNames)
+
+	certFile, keyFile = writeTestCertificate(t, certPEM, otherKeyPEM)
+	_, err = loadTLSCertificate(certFile, keyFile)
+	require.EqualError(t, err, "invalid certificate or private key: tls: private key does not match public key")
+}
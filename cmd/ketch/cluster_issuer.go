@@ -2,20 +2,47 @@ package main
 
 import (
 	"context"
+	"io"
 
+	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 )
 
-func clusterIssuerExist(iface dynamic.Interface, ctx context.Context, clusterIssuerName string) (bool, error) {
-	gvr := schema.GroupVersionResource{
-		Group:    "cert-manager.io",
-		Version:  "v1",
-		Resource: "clusterissuers",
+// clusterIssuerGVR identifies cert-manager ClusterIssuers.
+var clusterIssuerGVR = schema.GroupVersionResource{
+	Group:    "cert-manager.io",
+	Version:  "v1",
+	Resource: "clusterissuers",
+}
+
+const clusterIssuerCmdHelp = `
+Manage cert-manager ClusterIssuers.
+
+A ClusterIssuer obtains certificates of secure CNAMEs, an ingress controller uses it once it is set with:
+
+  ketch ingress set --cluster-issuer <name>
+`
+
+func newClusterIssuerCmd(cfg config, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cluster-issuer",
+		Short: "Manage cert-manager ClusterIssuers",
+		Long:  clusterIssuerCmdHelp,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return cmd.Usage()
+		},
 	}
-	_, err := iface.Resource(gvr).Get(ctx, clusterIssuerName, metav1.GetOptions{})
+	cmd.AddCommand(newClusterIssuerCreateCmd(cfg, out))
+	cmd.AddCommand(newClusterIssuerListCmd(cfg, out))
+	cmd.AddCommand(newClusterIssuerRemoveCmd(cfg, out))
+	return cmd
+}
+
+func clusterIssuerExist(iface dynamic.Interface, ctx context.Context, clusterIssuerName string) (bool, error) {
+	_, err := iface.Resource(clusterIssuerGVR).Get(ctx, clusterIssuerName, metav1.GetOptions{})
 	if errors.IsNotFound(err) {
 		return false, nil
 	}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// letsencryptServer is the ACME directory of Let's Encrypt.
+	letsencryptServer = "https://acme-v02.api.letsencrypt.org/directory"
+	// letsencryptStagingServer is the ACME directory of the Let's Encrypt staging environment,
+	// its certificates are not trusted by browsers but it has much higher rate limits.
+	letsencryptStagingServer = "https://acme-staging-v02.api.letsencrypt.org/directory"
+
+	// solverHTTP01 is the ACME HTTP-01 challenge solved by serving a token through an ingress controller.
+	solverHTTP01 = "http01"
+)
+
+const clusterIssuerCreateHelp = `
+Create a cert-manager ClusterIssuer that obtains certificates from Let's Encrypt.
+
+Let's Encrypt sends expiration notices to the email. Use --staging to try the setup out
+without hitting rate limits of the production environment, certificates of the staging environment aren't trusted by browsers.
+Challenges are solved with HTTP-01 through the ingress controller of the given ingress class:
+
+  ketch cluster-issuer create letsencrypt --email admin@example.com --solver http01 --ingress-class nginx
+  ketch ingress set --cluster-issuer letsencrypt
+`
+
+func newClusterIssuerCreateCmd(cfg config, out io.Writer) *cobra.Command {
+	options := clusterIssuerCreateOptions{}
+	cmd := &cobra.Command{
+		Use:   "create NAME",
+		Args:  cobra.ExactArgs(1),
+		Short: "Create a Let's Encrypt ClusterIssuer.",
+		Long:  clusterIssuerCreateHelp,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.name = args[0]
+			return clusterIssuerCreate(cmd.Context(), cfg, options, out)
+		},
+	}
+	cmd.Flags().StringVar(&options.email, "email", "", "Email address to register the ACME account with.")
+	cmd.MarkFlagRequired("email")
+	cmd.Flags().BoolVar(&options.staging, "staging", false, "Use the Let's Encrypt staging environment.")
+	cmd.Flags().StringVar(&options.solver, "solver", solverHTTP01, "ACME challenge solver: http01.")
+	cmd.Flags().StringVar(&options.ingressClass, "ingress-class", "", "Ingress class of the ingress controller solving HTTP-01 challenges.")
+	return cmd
+}
+
+type clusterIssuerCreateOptions struct {
+	name         string
+	email        string
+	staging      bool
+	solver       string
+	ingressClass string
+}
+
+func (o clusterIssuerCreateOptions) validate() error {
+	if errs := validation.IsDNS1123Subdomain(o.name); len(errs) > 0 {
+		return fmt.Errorf("invalid cluster issuer name %q: %s", o.name, errs[0])
+	}
+	if len(o.email) == 0 {
+		return errors.New("email is required")
+	}
+	if _, err := mail.ParseAddress(o.email); err != nil {
+		return fmt.Errorf("invalid email %q: %w", o.email, err)
+	}
+	if o.solver != solverHTTP01 {
+		return fmt.Errorf("unsupported solver %q, supported solvers: %s", o.solver, solverHTTP01)
+	}
+	return nil
+}
+
+func clusterIssuerCreate(ctx context.Context, cfg config, options clusterIssuerCreateOptions, out io.Writer) error {
+	if err := options.validate(); err != nil {
+		return err
+	}
+	exists, err := clusterIssuerExist(cfg.DynamicClient(), ctx, options.name)
+	if err != nil {
+		return fmt.Errorf("failed to get cluster issuer: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("cluster issuer %s already exists", options.name)
+	}
+	if _, err := cfg.DynamicClient().Resource(clusterIssuerGVR).Create(ctx, newLetsencryptClusterIssuer(options), metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create cluster issuer: %w", err)
+	}
+	fmt.Fprintf(out, "Successfully created cluster issuer %s!\n", options.name)
+	return nil
+}
+
+// newLetsencryptClusterIssuer returns a ClusterIssuer obtaining certificates from Let's Encrypt with the HTTP-01 challenge.
+func newLetsencryptClusterIssuer(options clusterIssuerCreateOptions) *unstructured.Unstructured {
+	server := letsencryptServer
+	if options.staging {
+		server = letsencryptStagingServer
+	}
+	ingress := map[string]interface{}{}
+	if len(options.ingressClass) > 0 {
+		ingress["ingressClassName"] = options.ingressClass
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": clusterIssuerGVR.GroupVersion().String(),
+		"kind":       "ClusterIssuer",
+		"metadata": map[string]interface{}{
+			"name": options.name,
+			"labels": map[string]interface{}{
+				"app.kubernetes.io/managed-by": "ketch",
+			},
+		},
+		"spec": map[string]interface{}{
+			"acme": map[string]interface{}{
+				"email":  options.email,
+				"server": server,
+				"privateKeySecretRef": map[string]interface{}{
+					"name": options.name + "-account-key",
+				},
+				"solvers": []interface{}{
+					map[string]interface{}{
+						"http01": map[string]interface{}{
+							"ingress": ingress,
+						},
+					},
+				},
+			},
+		},
+	}}
+}
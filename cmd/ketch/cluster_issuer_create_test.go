@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/theketchio/ketch/internal/mocks"
+)
+
+// newTestClusterIssuer returns a ClusterIssuer with the given spec.
+func newTestClusterIssuer(name string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "cert-manager.io/v1",
+		"kind":       "ClusterIssuer",
+		"metadata":   map[string]interface{}{"name": name},
+		"spec":       spec,
+	}}
+}
+
+func TestClusterIssuerCreate(t *testing.T) {
+	existing := newTestClusterIssuer("letsencrypt", map[string]interface{}{"selfSigned": map[string]interface{}{}})
+	tests := []struct {
+		name       string
+		options    clusterIssuerCreateOptions
+		wantSpec   map[string]interface{}
+		wantOutput string
+		wantErr    string
+	}{
+		{
+			name:    "staging issuer with an ingress class",
+			options: clusterIssuerCreateOptions{name: "letsencrypt-staging", email: "admin@theketch.io", staging: true, solver: "http01", ingressClass: "nginx"},
+			wantSpec: map[string]interface{}{
+				"acme": map[string]interface{}{
+					"email":               "admin@theketch.io",
+					"server":              "https://acme-staging-v02.api.letsencrypt.org/directory",
+					"privateKeySecretRef": map[string]interface{}{"name": "letsencrypt-staging-account-key"},
+					"solvers": []interface{}{
+						map[string]interface{}{"http01": map[string]interface{}{"ingress": map[string]interface{}{"ingressClassName": "nginx"}}},
+					},
+				},
+			},
+			wantOutput: "Successfully created cluster issuer letsencrypt-staging!\n",
+		},
+		{
+			name:    "production issuer",
+			options: clusterIssuerCreateOptions{name: "letsencrypt-prod", email: "admin@theketch.io", solver: "http01"},
+			wantSpec: map[string]interface{}{
+				"acme": map[string]interface{}{
+					"email":               "admin@theketch.io",
+					"server":              "https://acme-v02.api.letsencrypt.org/directory",
+					"privateKeySecretRef": map[string]interface{}{"name": "letsencrypt-prod-account-key"},
+					"solvers": []interface{}{
+						map[string]interface{}{"http01": map[string]interface{}{"ingress": map[string]interface{}{}}},
+					},
+				},
+			},
+			wantOutput: "Successfully created cluster issuer letsencrypt-prod!\n",
+		},
+		{
+			name:    "issuer exists",
+			options: clusterIssuerCreateOptions{name: "letsencrypt", email: "admin@theketch.io", solver: "http01"},
+			wantErr: "cluster issuer letsencrypt already exists",
+		},
+		{
+			name:    "invalid email",
+			options: clusterIssuerCreateOptions{name: "letsencrypt-prod", email: "admin", solver: "http01"},
+			wantErr: `invalid email "admin": mail: missing '@' or angle-addr`,
+		},
+		{
+			name:    "unsupported solver",
+			options: clusterIssuerCreateOptions{name: "letsencrypt-prod", email: "admin@theketch.io", solver: "dns01"},
+			wantErr: `unsupported solver "dns01", supported solvers: http01`,
+		},
+		{
+			name:    "invalid name",
+			options: clusterIssuerCreateOptions{name: "Letsencrypt", email: "admin@theketch.io", solver: "http01"},
+			wantErr: `invalid cluster issuer name "Letsencrypt": a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &mocks.Configuration{
+				DynamicClientObjects: []runtime.Object{existing.DeepCopy()},
+			}
+			out := &bytes.Buffer{}
+			err := clusterIssuerCreate(context.Background(), cfg, tt.options, out)
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.wantOutput, out.String())
+
+			got, err := cfg.DynamicClient().Resource(clusterIssuerGVR).Get(context.Background(), tt.options.name, metav1.GetOptions{})
+			require.Nil(t, err)
+			require.Equal(t, tt.wantSpec, got.Object["spec"])
+			require.Equal(t, map[string]string{"app.kubernetes.io/managed-by": "ketch"}, got.GetLabels())
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/theketchio/ketch/cmd/ketch/output"
+)
+
+const clusterIssuerListHelp = `
+List cert-manager ClusterIssuers of the cluster.
+`
+
+// clusterIssuerTypes are the kinds of issuers supported by cert-manager, each of them is a field of a ClusterIssuer spec.
+var clusterIssuerTypes = []string{"acme", "ca", "selfSigned", "vault", "venafi"}
+
+type clusterIssuerListOutput struct {
+	Name   string `column:"NAME"`
+	Type   string `column:"TYPE"`
+	Server string `column:"SERVER"`
+	Ready  string `column:"READY"`
+}
+
+func newClusterIssuerListCmd(cfg config, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Args:  cobra.NoArgs,
+		Short: "List ClusterIssuers.",
+		Long:  clusterIssuerListHelp,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return clusterIssuerList(cmd.Context(), cfg, out)
+		},
+	}
+	return cmd
+}
+
+func clusterIssuerList(ctx context.Context, cfg config, out io.Writer) error {
+	issuers, err := cfg.DynamicClient().Resource(clusterIssuerGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list cluster issuers: %w", err)
+	}
+	rows := make([]clusterIssuerListOutput, 0, len(issuers.Items))
+	for _, issuer := range issuers.Items {
+		rows = append(rows, newClusterIssuerListOutput(issuer))
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].Name < rows[j].Name
+	})
+	return output.Write(rows, out, "column")
+}
+
+func newClusterIssuerListOutput(issuer unstructured.Unstructured) clusterIssuerListOutput {
+	row := clusterIssuerListOutput{Name: issuer.GetName(), Type: "-", Server: "-", Ready: "Unknown"}
+	for _, issuerType := range clusterIssuerTypes {
+		if _, found, _ := unstructured.NestedMap(issuer.Object, "spec", issuerType); found {
+			row.Type = issuerType
+			break
+		}
+	}
+	if server, found, _ := unstructured.NestedString(issuer.Object, "spec", "acme", "server"); found {
+		row.Server = server
+	}
+	conditions, _, _ := unstructured.NestedSlice(issuer.Object, "status", "conditions")
+	for _, c := range conditions {
+		if condition, ok := c.(map[string]interface{}); ok && condition["type"] == "Ready" {
+			if status, ok := condition["status"].(string); ok {
+				row.Ready = status
+			}
+		}
+	}
+	return row
+}
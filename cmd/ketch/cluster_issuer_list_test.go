@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/theketchio/ketch/internal/mocks"
+)
+
+func TestClusterIssuerList(t *testing.T) {
+	letsencrypt := newTestClusterIssuer("letsencrypt", map[string]interface{}{
+		"acme": map[string]interface{}{"server": "https://acme-v02.api.letsencrypt.org/directory"},
+	})
+	letsencrypt.Object["status"] = map[string]interface{}{
+		"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+	}
+	selfSigned := newTestClusterIssuer("self-signed", map[string]interface{}{"selfSigned": map[string]interface{}{}})
+	tests := []struct {
+		name    string
+		objects []runtime.Object
+		want    string
+	}{
+		{
+			name:    "cluster issuers",
+			objects: []runtime.Object{selfSigned, letsencrypt},
+			want: `NAME           TYPE          SERVER                                            READY
+letsencrypt    acme          https://acme-v02.api.letsencrypt.org/directory    True
+self-signed    selfSigned    -                                                 Unknown
+`,
+		},
+		{
+			name: "no cluster issuers",
+			want: "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &mocks.Configuration{DynamicClientObjects: tt.objects}
+			out := &bytes.Buffer{}
+			require.Nil(t, clusterIssuerList(context.Background(), cfg, out))
+			require.Equal(t, tt.want, out.String())
+		})
+	}
+}
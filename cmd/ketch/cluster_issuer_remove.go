@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
+)
+
+const clusterIssuerRemoveHelp = `
+Remove a cert-manager ClusterIssuer.
+
+A ClusterIssuer used by an ingress profile can't be removed,
+set another one with "ketch ingress set --cluster-issuer" first.
+`
+
+func newClusterIssuerRemoveCmd(cfg config, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove NAME",
+		Args:  cobra.ExactArgs(1),
+		Short: "Remove a ClusterIssuer.",
+		Long:  clusterIssuerRemoveHelp,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return clusterIssuerRemove(cmd.Context(), cfg, args[0], out)
+		},
+	}
+	return cmd
+}
+
+func clusterIssuerRemove(ctx context.Context, cfg config, name string, out io.Writer) error {
+	var configmaps v1.ConfigMapList
+	if err := cfg.Client().List(ctx, &configmaps, client.InNamespace(ketchv1.IngressConfigmapNamespace)); err != nil {
+		return fmt.Errorf("failed to list ingress profiles: %w", err)
+	}
+	for _, configmap := range configmaps.Items {
+		if _, ok := ketchv1.IngressProfileFromConfigmapName(configmap.Name); !ok {
+			continue
+		}
+		if configmap.Data["clusterIssuer"] == name {
+			return fmt.Errorf("cluster issuer %s is used by ingress configmap %s", name, configmap.Name)
+		}
+	}
+	err := cfg.DynamicClient().Resource(clusterIssuerGVR).Delete(ctx, name, metav1.DeleteOptions{})
+	if errors.IsNotFound(err) {
+		return ErrClusterIssuerNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove cluster issuer: %w", err)
+	}
+	fmt.Fprintf(out, "Successfully removed cluster issuer %s!\n", name)
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
+	"github.com/theketchio/ketch/internal/mocks"
+)
+
+func TestClusterIssuerRemove(t *testing.T) {
+	ingressConfigmap := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "ketch-ingress-public", Namespace: ketchv1.IngressConfigmapNamespace},
+		Data:       map[string]string{"clusterIssuer": "letsencrypt"},
+	}
+	tests := []struct {
+		name       string
+		issuer     string
+		wantOutput string
+		wantErr    string
+	}{
+		{
+			name:       "unused cluster issuer",
+			issuer:     "letsencrypt-staging",
+			wantOutput: "Successfully removed cluster issuer letsencrypt-staging!\n",
+		},
+		{
+			name:    "cluster issuer used by an ingress profile",
+			issuer:  "letsencrypt",
+			wantErr: "cluster issuer letsencrypt is used by ingress configmap ketch-ingress-public",
+		},
+		{
+			name:    "cluster issuer not found",
+			issuer:  "self-signed",
+			wantErr: ErrClusterIssuerNotFound.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &mocks.Configuration{
+				CtrlClientObjects: []runtime.Object{ingressConfigmap},
+				DynamicClientObjects: []runtime.Object{
+					newTestClusterIssuer("letsencrypt", map[string]interface{}{}),
+					newTestClusterIssuer("letsencrypt-staging", map[string]interface{}{}),
+				},
+			}
+			out := &bytes.Buffer{}
+			err := clusterIssuerRemove(context.Background(), cfg, tt.issuer, out)
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.wantOutput, out.String())
+			exists, err := clusterIssuerExist(cfg.DynamicClient(), context.Background(), tt.issuer)
+			require.Nil(t, err)
+			require.False(t, exists)
+		})
+	}
+}
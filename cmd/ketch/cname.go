@@ -17,5 +17,6 @@ func newCnameCmd(cfg config, out io.Writer) *cobra.Command {
 	}
 	cmd.AddCommand(newCnameAddCmd(cfg, out))
 	cmd.AddCommand(newCnameRemoveCmd(cfg, out))
+	cmd.AddCommand(newCnameVerifyCmd(cfg, out))
 	return cmd
 }
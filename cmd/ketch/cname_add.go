@@ -2,11 +2,17 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
 	"github.com/theketchio/ketch/internal/deploy"
@@ -15,6 +21,49 @@ import (
 
 const cnameAddHelp = `
 Add a new CNAME to an application.
+
+Requests to the CNAME are sent to the routable process of the application ("web" or the first process in alphabetical order).
+Use --route to send requests with a path prefix to another process, the flag can be repeated:
+
+  ketch cname add example.com -a myapp --route /admin=admin --route /api=api:8080
+
+A route with the "/" prefix sends all other requests of the CNAME to the given process.
+
+Use --path to handle only requests with a path prefix, so that several applications can share a CNAME.
+With --strip-path, the prefix is removed from requests before they are sent to the application:
+
+  ketch cname add api.example.com -a orders --path /orders --strip-path
+  ketch cname add api.example.com -a users --path /users
+
+With nginx, --strip-path makes nginx match paths of all applications sharing the CNAME as regular expressions.
+
+Paths of routes are relative to the path of the CNAME.
+Two applications can't claim the same CNAME and path.
+Running the command for an existing CNAME with --route or --path replaces its routes or path.
+
+With --secure, the certificate of the CNAME is issued by the cluster issuer of the app's ingress controller.
+To bring your own certificate, use --cert and --key to store it in a TLS secret in the app's namespace,
+or --secret to use a TLS secret that already exists there:
+
+  ketch cname add www.example.com -a myapp --cert cert.pem --key key.pem
+  ketch cname add www.example.com -a myapp --secret www-example-com-tls
+
+Running the command again with a new certificate updates the secret, secrets not created by ketch for the app are never overwritten.
+Running the command again with a new certificate updates the secret.
+
+Requests to the CNAME can require authentication. nginx and traefik support basic auth with users of a htpasswd file,
+stored in a secret in the app's namespace, and forward auth to a service like oauth2-proxy:
+
+  ketch cname add tools.example.com -a myapp --htpasswd ./htpasswd
+  ketch cname add tools.example.com -a myapp --forward-auth-url https://oauth2.example.com/oauth2/auth \
+    --forward-auth-signin-url https://oauth2.example.com/oauth2/start --forward-auth-response-header X-Auth-Request-Email
+
+istio supports JSON Web Tokens:
+
+  ketch cname add tools.example.com -a myapp --jwt-issuer https://accounts.example.com \
+    --jwt-jwks-uri https://accounts.example.com/.well-known/jwks.json
+
+Running the command for an existing CNAME with auth flags replaces its authentication.
 `
 
 func newCnameAddCmd(cfg config, out io.Writer) *cobra.Command {
@@ -32,6 +81,20 @@ func newCnameAddCmd(cfg config, out io.Writer) *cobra.Command {
 	cmd.Flags().StringVarP(&options.appName, deploy.FlagApp, deploy.FlagAppShort, "", "The name of the app.")
 	cmd.MarkFlagRequired("app")
 	cmd.Flags().BoolVar(&options.secure, "secure", false, "Whether the CName should be https")
+	cmd.Flags().StringArrayVar(&options.routes, "route", nil, "Route requests with a path prefix to a process, in the PATH=PROCESS[:PORT] format.")
+	cmd.Flags().StringVar(&options.path, "path", "", "Handle only requests with the path prefix.")
+	cmd.Flags().BoolVar(&options.stripPath, "strip-path", false, "Remove the path prefix from requests before they are sent to the app.")
+	cmd.Flags().StringVar(&options.cert, "cert", "", "Path to a PEM encoded certificate to serve the CName with, requires --key.")
+	cmd.Flags().StringVar(&options.key, "key", "", "Path to the PEM encoded private key of the certificate.")
+	cmd.Flags().StringVar(&options.secret, "secret", "", "Name of a TLS secret in the app's namespace with a certificate to serve the CName with. With --cert and --key, the secret is created or updated.")
+	cmd.Flags().StringVar(&options.basicAuthSecret, "basic-auth-secret", "", "Name of a secret in the app's namespace with htpasswd users. With --htpasswd, the secret is created or updated.")
+	cmd.Flags().StringVar(&options.htpasswd, "htpasswd", "", "Path to a htpasswd file with users allowed to access the CName.")
+	cmd.Flags().StringVar(&options.forwardAuthURL, "forward-auth-url", "", "URL of a service authenticating requests to the CName.")
+	cmd.Flags().StringVar(&options.forwardAuthSignInURL, "forward-auth-signin-url", "", "URL unauthenticated users are redirected to, nginx only.")
+	cmd.Flags().StringArrayVar(&options.forwardAuthResponseHeaders, "forward-auth-response-header", nil, "Header copied from the response of the auth service to the request, can be repeated.")
+	cmd.Flags().StringVar(&options.jwtIssuer, "jwt-issuer", "", "Issuer of JSON Web Tokens required to access the CName, istio only.")
+	cmd.Flags().StringVar(&options.jwtJWKSURI, "jwt-jwks-uri", "", "URL of the public keys of the JWT issuer.")
+	cmd.Flags().StringArrayVar(&options.jwtAudiences, "jwt-audience", nil, "Audience a JSON Web Token must be issued for, can be repeated.")
 
 	cmd.RegisterFlagCompletionFunc(deploy.FlagApp, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return autoCompleteAppNames(cfg, toComplete)
@@ -40,30 +103,222 @@ func newCnameAddCmd(cfg config, out io.Writer) *cobra.Command {
 }
 
 type cnameAddOptions struct {
-	appName string
-	cname   string
-	secure  bool
+	appName   string
+	cname     string
+	secure    bool
+	routes    []string
+	path      string
+	stripPath bool
+	cert      string
+	key       string
+	secret    string
+
+	basicAuthSecret            string
+	htpasswd                   string
+	forwardAuthURL             string
+	forwardAuthSignInURL       string
+	forwardAuthResponseHeaders []string
+	jwtIssuer                  string
+	jwtJWKSURI                 string
+	jwtAudiences               []string
+}
+
+// auth returns authentication of the cname set by the options or nil if no auth option is set.
+func (o cnameAddOptions) auth() *ketchv1.IngressAuth {
+	auth := ketchv1.IngressAuth{BasicAuthSecret: o.basicAuthSecret}
+	if len(o.htpasswd) > 0 && len(auth.BasicAuthSecret) == 0 {
+		auth.BasicAuthSecret = cnameBasicAuthSecretName(o.appName, o.cname)
+	}
+	if len(o.forwardAuthURL) > 0 || len(o.forwardAuthSignInURL) > 0 || len(o.forwardAuthResponseHeaders) > 0 {
+		auth.ForwardAuth = &ketchv1.ForwardAuth{
+			URL:             o.forwardAuthURL,
+			SignInURL:       o.forwardAuthSignInURL,
+			ResponseHeaders: o.forwardAuthResponseHeaders,
+		}
+	}
+	if len(o.jwtIssuer) > 0 || len(o.jwtJWKSURI) > 0 || len(o.jwtAudiences) > 0 {
+		auth.JWT = &ketchv1.JWTAuth{Issuer: o.jwtIssuer, JWKSURI: o.jwtJWKSURI, Audiences: o.jwtAudiences}
+	}
+	if len(auth.BasicAuthSecret) == 0 && auth.ForwardAuth == nil && auth.JWT == nil {
+		return nil
+	}
+	return &auth
+}
+
+// cnameTLSSecretName returns a name of a secret to store a user provided certificate of the cname.
+func cnameTLSSecretName(appName, cname string) string {
+	return fmt.Sprintf("%s-tls-%s", appName, regexp.MustCompile("[^a-z0-9]+").ReplaceAllString(cname, "-"))
 }
 
 func cnameAdd(ctx context.Context, cfg config, options cnameAddOptions, out io.Writer) error {
 	if err := validation.ValidateCname(options.cname); err != nil {
 		return err
 	}
+	if (len(options.cert) > 0) != (len(options.key) > 0) {
+		return errors.New("--cert and --key must be used together")
+	}
+	newCname := ketchv1.Cname{Name: options.cname, Secure: options.secure, Path: options.path, StripPath: options.stripPath}
+	var certificate *tlsCertificate
+	if len(options.cert) > 0 {
+		var err error
+		if certificate, err = loadTLSCertificate(options.cert, options.key); err != nil {
+			return err
+		}
+		if err := validateCertificate(certificate.leaf, options.cname, time.Now()); err != nil {
+			return err
+		}
+		newCname.SecretName = options.secret
+		if len(newCname.SecretName) == 0 {
+			newCname.SecretName = cnameTLSSecretName(options.appName, options.cname)
+		}
+	} else if len(options.secret) > 0 {
+		newCname.SecretName = options.secret
+	}
+	if len(newCname.SecretName) > 0 {
+		newCname.Secure = true
+	}
+	for _, value := range options.routes {
+		route, err := parseCnameRoute(value)
+		if err != nil {
+			return err
+		}
+		newCname.Routes = append(newCname.Routes, route)
+	}
+	if err := newCname.Validate(); err != nil {
+		return err
+	}
+	var htpasswd []byte
+	if len(options.htpasswd) > 0 {
+		var err error
+		if htpasswd, err = loadHtpasswd(options.htpasswd); err != nil {
+			return err
+		}
+	}
 	app := ketchv1.App{}
 	if err := cfg.Client().Get(ctx, types.NamespacedName{Name: options.appName}, &app); err != nil {
 		return fmt.Errorf("failed to get the app: %w", err)
 	}
-	for _, cname := range app.Spec.Ingress.Cnames {
-		if cname.Name == options.cname {
+	if auth := options.auth(); auth != nil {
+		if err := auth.Validate(app.Spec.Ingress.Controller.IngressType); err != nil {
+			return fmt.Errorf("cname %s: %w", options.cname, err)
+		}
+		newCname.Policies = &ketchv1.IngressPolicies{Auth: auth}
+	}
+	if err := validateRouteProcesses(app, newCname.Routes); err != nil {
+		return err
+	}
+	if certificate == nil && len(newCname.SecretName) > 0 {
+		cert, err := getSecretCertificate(ctx, cfg, app.Spec.Namespace, newCname.SecretName)
+		if err != nil {
+			return err
+		}
+		if err := validateCertificate(cert, options.cname, time.Now()); err != nil {
+			return err
+		}
+	}
+	if htpasswd == nil && len(options.basicAuthSecret) > 0 {
+		if err := checkBasicAuthSecret(ctx, cfg, app.Spec.Namespace, options.basicAuthSecret); err != nil {
+			return err
+		}
+	}
+	existing := false
+	for i, cname := range app.Spec.Ingress.Cnames {
+		if cname.Name != options.cname {
+			continue
+		}
+		if len(newCname.Routes) == 0 && len(newCname.Path) == 0 && len(newCname.SecretName) == 0 && newCname.Policies == nil {
 			return nil
 		}
+		if len(newCname.Routes) > 0 {
+			app.Spec.Ingress.Cnames[i].Routes = newCname.Routes
+		}
+		if len(newCname.Path) > 0 {
+			app.Spec.Ingress.Cnames[i].Path = newCname.Path
+			app.Spec.Ingress.Cnames[i].StripPath = newCname.StripPath
+		}
+		if len(newCname.SecretName) > 0 {
+			app.Spec.Ingress.Cnames[i].Secure = true
+			app.Spec.Ingress.Cnames[i].SecretName = newCname.SecretName
+		}
+		if newCname.Policies != nil {
+			if app.Spec.Ingress.Cnames[i].Policies == nil {
+				app.Spec.Ingress.Cnames[i].Policies = &ketchv1.IngressPolicies{}
+			}
+			app.Spec.Ingress.Cnames[i].Policies.Auth = newCname.Policies.Auth
+		}
+		existing = true
+	}
+	if !existing {
+		if newCname.Secure && len(newCname.SecretName) == 0 && len(app.Spec.Ingress.Controller.ClusterIssuer) == 0 {
+			return ErrClusterIssuerRequired
+		}
+		app.Spec.Ingress.Cnames = append(app.Spec.Ingress.Cnames, newCname)
 	}
-	if options.secure && len(app.Spec.Ingress.Controller.ClusterIssuer) == 0 {
-		return ErrClusterIssuerRequired
+	if err := app.Validate(); err != nil {
+		return err
+	}
+	var apps ketchv1.AppList
+	if err := cfg.Client().List(ctx, &apps); err != nil {
+		return fmt.Errorf("failed to list apps: %w", err)
+	}
+	if err := app.CnameConflict(apps.Items); err != nil {
+		return err
+	}
+	// the app is validated by the api server before secrets are written, so a rejected update doesn't leave them behind.
+	if err := cfg.Client().Update(ctx, app.DeepCopy(), client.DryRunAll); err != nil {
+		return fmt.Errorf("failed to update the app: %w", err)
+	}
+	if certificate != nil {
+		if err := applyTLSSecret(ctx, cfg, app, newCname.SecretName, certificate); err != nil {
+			return err
+		}
+	}
+	if htpasswd != nil {
+		if err := applyBasicAuthSecret(ctx, cfg, app, newCname.Policies.Auth.BasicAuthSecret, htpasswd); err != nil {
+			return err
+		}
 	}
-	app.Spec.Ingress.Cnames = append(app.Spec.Ingress.Cnames, ketchv1.Cname{Name: options.cname, Secure: options.secure})
 	if err := cfg.Client().Update(ctx, &app); err != nil {
 		return fmt.Errorf("failed to update the app: %w", err)
 	}
 	return nil
 }
+
+// parseCnameRoute parses a route in the PATH=PROCESS[:PORT] format.
+func parseCnameRoute(value string) (ketchv1.CnameRoute, error) {
+	path, target, found := strings.Cut(value, "=")
+	if !found {
+		return ketchv1.CnameRoute{}, fmt.Errorf("route %q should have PATH=PROCESS[:PORT] format", value)
+	}
+	route := ketchv1.CnameRoute{PathPrefix: path, Process: target}
+	if process, port, found := strings.Cut(target, ":"); found {
+		n, err := strconv.ParseInt(port, 10, 32)
+		if err != nil || n < 1 || n > 65535 {
+			return ketchv1.CnameRoute{}, fmt.Errorf("route %q has an invalid port %q", value, port)
+		}
+		route.Process = process
+		route.Port = int32(n)
+	}
+	return route, nil
+}
+
+// validateRouteProcesses checks that the routes send requests to processes of the most recent deployment of the app.
+func validateRouteProcesses(app ketchv1.App, routes []ketchv1.CnameRoute) error {
+	if len(app.Spec.Deployments) == 0 {
+		return nil
+	}
+	latest := app.Spec.Deployments[len(app.Spec.Deployments)-1]
+	for _, route := range routes {
+		found := false
+		for _, process := range latest.Processes {
+			if process.Name == route.Process {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("route %s: app %s doesn't have a process %q", route.PathPrefix, app.Name, route.Process)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,437 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
+	"github.com/theketchio/ketch/internal/mocks"
+	"github.com/theketchio/ketch/internal/utils"
+)
+
+func TestCnameAdd(t *testing.T) {
+	newApp := func() *ketchv1.App {
+		return &ketchv1.App{
+			ObjectMeta: metav1.ObjectMeta{Name: "go-app"},
+			Spec: ketchv1.AppSpec{
+				Deployments: []ketchv1.AppDeploymentSpec{
+					{
+						Version:         1,
+						RoutingSettings: ketchv1.RoutingSettings{Weight: 100},
+						Processes: []ketchv1.ProcessSpec{
+							{Name: "web"},
+							{Name: "admin"},
+						},
+					},
+				},
+				Ingress: ketchv1.IngressSpec{
+					Cnames: ketchv1.CnameList{{Name: "theketch.io"}},
+				},
+			},
+		}
+	}
+	otherApp := &ketchv1.App{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders"},
+		Spec: ketchv1.AppSpec{
+			Ingress: ketchv1.IngressSpec{
+				Cnames: ketchv1.CnameList{{Name: "api.theketch.io", Path: "/orders"}},
+			},
+		},
+	}
+	tests := []struct {
+		name       string
+		options    cnameAddOptions
+		wantCnames ketchv1.CnameList
+		wantErr    string
+	}{
+		{
+			name:    "new cname",
+			options: cnameAddOptions{appName: "go-app", cname: "www.theketch.io"},
+			wantCnames: ketchv1.CnameList{
+				{Name: "theketch.io"},
+				{Name: "www.theketch.io"},
+			},
+		},
+		{
+			name:    "new cname with routes",
+			options: cnameAddOptions{appName: "go-app", cname: "admin.theketch.io", routes: []string{"/=admin:9090", "/api=web"}},
+			wantCnames: ketchv1.CnameList{
+				{Name: "theketch.io"},
+				{Name: "admin.theketch.io", Routes: []ketchv1.CnameRoute{
+					{PathPrefix: "/", Process: "admin", Port: 9090},
+					{PathPrefix: "/api", Process: "web"},
+				}},
+			},
+		},
+		{
+			name:    "existing cname with routes",
+			options: cnameAddOptions{appName: "go-app", cname: "theketch.io", routes: []string{"/admin=admin"}},
+			wantCnames: ketchv1.CnameList{
+				{Name: "theketch.io", Routes: []ketchv1.CnameRoute{{PathPrefix: "/admin", Process: "admin"}}},
+			},
+		},
+		{
+			name:       "existing cname",
+			options:    cnameAddOptions{appName: "go-app", cname: "theketch.io"},
+			wantCnames: ketchv1.CnameList{{Name: "theketch.io"}},
+		},
+		{
+			name:    "new cname with path",
+			options: cnameAddOptions{appName: "go-app", cname: "api.theketch.io", path: "/users", stripPath: true},
+			wantCnames: ketchv1.CnameList{
+				{Name: "theketch.io"},
+				{Name: "api.theketch.io", Path: "/users", StripPath: true},
+			},
+		},
+		{
+			name:    "existing cname with path",
+			options: cnameAddOptions{appName: "go-app", cname: "theketch.io", path: "/go"},
+			wantCnames: ketchv1.CnameList{
+				{Name: "theketch.io", Path: "/go"},
+			},
+		},
+		{
+			name:    "strip path without path",
+			options: cnameAddOptions{appName: "go-app", cname: "api.theketch.io", stripPath: true},
+			wantErr: `cname api.theketch.io: stripPath requires a path`,
+		},
+		{
+			name:    "path claimed by another app",
+			options: cnameAddOptions{appName: "go-app", cname: "api.theketch.io", path: "/orders/"},
+			wantErr: `cname conflict: api.theketch.io/orders is used by app orders`,
+		},
+		{
+			name:    "invalid route",
+			options: cnameAddOptions{appName: "go-app", cname: "theketch.io", routes: []string{"/admin"}},
+			wantErr: `route "/admin" should have PATH=PROCESS[:PORT] format`,
+		},
+		{
+			name:    "invalid route port",
+			options: cnameAddOptions{appName: "go-app", cname: "theketch.io", routes: []string{"/admin=admin:http"}},
+			wantErr: `route "/admin=admin:http" has an invalid port "http"`,
+		},
+		{
+			name:    "invalid path prefix",
+			options: cnameAddOptions{appName: "go-app", cname: "theketch.io", routes: []string{"admin=admin"}},
+			wantErr: `cname theketch.io: invalid path prefix "admin", it should start with / and contain only letters, digits and -._~%/ characters`,
+		},
+		{
+			name:    "unknown process",
+			options: cnameAddOptions{appName: "go-app", cname: "theketch.io", routes: []string{"/jobs=worker"}},
+			wantErr: `route /jobs: app go-app doesn't have a process "worker"`,
+		},
+		{
+			name:    "secure cname without cluster issuer",
+			options: cnameAddOptions{appName: "go-app", cname: "www.theketch.io", secure: true},
+			wantErr: ErrClusterIssuerRequired.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &mocks.Configuration{
+				CtrlClientObjects: []runtime.Object{newApp(), otherApp},
+			}
+			err := cnameAdd(context.Background(), cfg, tt.options, &bytes.Buffer{})
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+
+			gotApp := ketchv1.App{}
+			require.Nil(t, cfg.Client().Get(context.Background(), types.NamespacedName{Name: "go-app"}, &gotApp))
+			require.Equal(t, tt.wantCnames, gotApp.Spec.Ingress.Cnames)
+		})
+	}
+}
+
+func TestCnameAdd_certificate(t *testing.T) {
+	now := time.Now()
+	certPEM, keyPEM := newTestCertificate(t, []string{"www.theketch.io"}, now.Add(-time.Hour), now.Add(time.Hour))
+	certFile, keyFile := writeTestCertificate(t, certPEM, keyPEM)
+	expiredCertPEM, expiredKeyPEM := newTestCertificate(t, []string{"www.theketch.io"}, now.Add(-2*time.Hour), now.Add(-time.Hour))
+	expiredCertFile, expiredKeyFile := writeTestCertificate(t, expiredCertPEM, expiredKeyPEM)
+
+	app := &ketchv1.App{
+		ObjectMeta: metav1.ObjectMeta{Name: "go-app"},
+		Spec: ketchv1.AppSpec{
+			Namespace: "ketch-go-app",
+			Ingress: ketchv1.IngressSpec{
+				Cnames: ketchv1.CnameList{{Name: "www.theketch.io"}},
+			},
+		},
+	}
+	existingSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "www-theketch-io",
+			Namespace: "ketch-go-app",
+			Labels:    map[string]string{utils.KetchAppNameLabel: "go-app"},
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: map[string][]byte{corev1.TLSCertKey: []byte("old"), corev1.TLSPrivateKeyKey: []byte("old")},
+	}
+	foreignSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cert-manager-tls", Namespace: "ketch-go-app"},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{corev1.TLSCertKey: []byte("old"), corev1.TLSPrivateKeyKey: []byte("old")},
+	}
+	tests := []struct {
+		name           string
+		options        cnameAddOptions
+		wantCnames     ketchv1.CnameList
+		wantSecretName string
+		wantErr        string
+	}{
+		{
+			name:    "new cname with a certificate",
+			options: cnameAddOptions{appName: "go-app", cname: "www.theketch.io", cert: certFile, key: keyFile},
+			wantCnames: ketchv1.CnameList{
+				{Name: "www.theketch.io", Secure: true, SecretName: "go-app-tls-www-theketch-io"},
+			},
+			wantSecretName: "go-app-tls-www-theketch-io",
+		},
+		{
+			name:    "certificate updates an existing secret",
+			options: cnameAddOptions{appName: "go-app", cname: "www.theketch.io", cert: certFile, key: keyFile, secret: "www-theketch-io"},
+			wantCnames: ketchv1.CnameList{
+				{Name: "www.theketch.io", Secure: true, SecretName: "www-theketch-io"},
+			},
+			wantSecretName: "www-theketch-io",
+		},
+		{
+			name:    "certificate doesn't overwrite a secret of someone else",
+			options: cnameAddOptions{appName: "go-app", cname: "www.theketch.io", cert: certFile, key: keyFile, secret: "cert-manager-tls"},
+			wantErr: "secret ketch-go-app/cert-manager-tls doesn't belong to the app go-app, choose another secret name",
+		},
+		{
+			name:    "existing secret",
+			options: cnameAddOptions{appName: "go-app", cname: "www.theketch.io", secret: "valid-tls"},
+			wantCnames: ketchv1.CnameList{
+				{Name: "www.theketch.io", Secure: true, SecretName: "valid-tls"},
+			},
+		},
+		{
+			name:    "existing secret not found",
+			options: cnameAddOptions{appName: "go-app", cname: "www.theketch.io", secret: "missing-tls"},
+			wantErr: `failed to get secret ketch-go-app/missing-tls: secrets "missing-tls" not found`,
+		},
+		{
+			name:    "certificate for another host",
+			options: cnameAddOptions{appName: "go-app", cname: "api.theketch.io", cert: certFile, key: keyFile},
+			wantErr: "certificate is not valid for api.theketch.io: x509: certificate is valid for www.theketch.io, not api.theketch.io",
+		},
+		{
+			name:    "expired certificate",
+			options: cnameAddOptions{appName: "go-app", cname: "www.theketch.io", cert: expiredCertFile, key: expiredKeyFile},
+			wantErr: "certificate for www.theketch.io expired on " + now.Add(-time.Hour).UTC().Format(time.RFC3339),
+		},
+		{
+			name:    "cert without key",
+			options: cnameAddOptions{appName: "go-app", cname: "www.theketch.io", cert: certFile},
+			wantErr: "--cert and --key must be used together",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			validSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "valid-tls", Namespace: "ketch-go-app"},
+				Type:       corev1.SecretTypeTLS,
+				Data:       map[string][]byte{corev1.TLSCertKey: certPEM, corev1.TLSPrivateKeyKey: keyPEM},
+			}
+			cfg := &mocks.Configuration{
+				CtrlClientObjects: []runtime.Object{app.DeepCopy(), existingSecret.DeepCopy(), foreignSecret.DeepCopy(), validSecret},
+			}
+			err := cnameAdd(context.Background(), cfg, tt.options, &bytes.Buffer{})
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+
+			gotApp := ketchv1.App{}
+			require.Nil(t, cfg.Client().Get(context.Background(), types.NamespacedName{Name: "go-app"}, &gotApp))
+			require.Equal(t, tt.wantCnames, gotApp.Spec.Ingress.Cnames)
+			if len(tt.wantSecretName) > 0 {
+				gotSecret := corev1.Secret{}
+				require.Nil(t, cfg.Client().Get(context.Background(), types.NamespacedName{Namespace: "ketch-go-app", Name: tt.wantSecretName}, &gotSecret))
+				require.Equal(t, corev1.SecretTypeTLS, gotSecret.Type)
+				require.Equal(t, certPEM, gotSecret.Data[corev1.TLSCertKey])
+				require.Equal(t, keyPEM, gotSecret.Data[corev1.TLSPrivateKeyKey])
+			}
+		})
+	}
+}
+
+func TestCnameAdd_auth(t *testing.T) {
+	htpasswd := "admin:$apr1$Wm0kNxRz$Kqs2TqwJb8Z8.Q3Bm0HqA/\n"
+	htpasswdFile := filepath.Join(t.TempDir(), "htpasswd")
+	require.Nil(t, os.WriteFile(htpasswdFile, []byte(htpasswd), 0600))
+	invalidHtpasswdFile := filepath.Join(t.TempDir(), "htpasswd")
+	require.Nil(t, os.WriteFile(invalidHtpasswdFile, []byte("admin\n"), 0600))
+
+	app := &ketchv1.App{
+		ObjectMeta: metav1.ObjectMeta{Name: "go-app"},
+		Spec: ketchv1.AppSpec{
+			Namespace: "ketch-go-app",
+			Ingress: ketchv1.IngressSpec{
+				Cnames: ketchv1.CnameList{{
+					Name:     "tools.theketch.io",
+					Policies: &ketchv1.IngressPolicies{IPAllowlist: []string{"10.0.0.0/8"}},
+				}},
+				Controller: ketchv1.IngressControllerSpec{IngressType: ketchv1.NginxIngressControllerType},
+			},
+		},
+	}
+	usersSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "tools-users", Namespace: "ketch-go-app"},
+		Type:       corev1.SecretTypeOpaque,
+		Data:       map[string][]byte{"auth": []byte(htpasswd)},
+	}
+	ownSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "go-app-basic-auth-admin-theketch-io",
+			Namespace: "ketch-go-app",
+			Labels:    map[string]string{utils.KetchAppNameLabel: "go-app"},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{"auth": []byte("old:$apr1$Wm0kNxRz$Kqs2TqwJb8Z8.Q3Bm0HqA/")},
+	}
+	foreignSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "go-app-basic-auth-docs-theketch-io", Namespace: "ketch-go-app"},
+		Type:       corev1.SecretTypeOpaque,
+		Data:       map[string][]byte{"auth": []byte(htpasswd)},
+	}
+	tests := []struct {
+		name           string
+		options        cnameAddOptions
+		secrets        []runtime.Object
+		ingressType    ketchv1.IngressControllerType
+		wantCnames     ketchv1.CnameList
+		wantSecretName string
+		wantErr        string
+	}{
+		{
+			name:    "new cname with htpasswd users",
+			options: cnameAddOptions{appName: "go-app", cname: "admin.theketch.io", htpasswd: htpasswdFile},
+			wantCnames: ketchv1.CnameList{
+				{Name: "tools.theketch.io", Policies: &ketchv1.IngressPolicies{IPAllowlist: []string{"10.0.0.0/8"}}},
+				{Name: "admin.theketch.io", Policies: &ketchv1.IngressPolicies{Auth: &ketchv1.IngressAuth{BasicAuthSecret: "go-app-basic-auth-admin-theketch-io"}}},
+			},
+			wantSecretName: "go-app-basic-auth-admin-theketch-io",
+		},
+		{
+			name:    "htpasswd users update a secret of the app",
+			options: cnameAddOptions{appName: "go-app", cname: "admin.theketch.io", htpasswd: htpasswdFile},
+			secrets: []runtime.Object{ownSecret},
+			wantCnames: ketchv1.CnameList{
+				{Name: "tools.theketch.io", Policies: &ketchv1.IngressPolicies{IPAllowlist: []string{"10.0.0.0/8"}}},
+				{Name: "admin.theketch.io", Policies: &ketchv1.IngressPolicies{Auth: &ketchv1.IngressAuth{BasicAuthSecret: "go-app-basic-auth-admin-theketch-io"}}},
+			},
+			wantSecretName: "go-app-basic-auth-admin-theketch-io",
+		},
+		{
+			name:    "htpasswd users don't overwrite a secret of someone else",
+			options: cnameAddOptions{appName: "go-app", cname: "docs.theketch.io", htpasswd: htpasswdFile},
+			secrets: []runtime.Object{foreignSecret},
+			wantErr: "secret ketch-go-app/go-app-basic-auth-docs-theketch-io doesn't belong to the app go-app, choose another secret name",
+		},
+		{
+			name:    "existing cname with an existing secret",
+			options: cnameAddOptions{appName: "go-app", cname: "tools.theketch.io", basicAuthSecret: "tools-users"},
+			wantCnames: ketchv1.CnameList{
+				{Name: "tools.theketch.io", Policies: &ketchv1.IngressPolicies{
+					IPAllowlist: []string{"10.0.0.0/8"},
+					Auth:        &ketchv1.IngressAuth{BasicAuthSecret: "tools-users"},
+				}},
+			},
+		},
+		{
+			name: "forward auth",
+			options: cnameAddOptions{
+				appName:                    "go-app",
+				cname:                      "tools.theketch.io",
+				forwardAuthURL:             "https://oauth2.theketch.io/oauth2/auth",
+				forwardAuthSignInURL:       "https://oauth2.theketch.io/oauth2/start",
+				forwardAuthResponseHeaders: []string{"X-Auth-Request-Email"},
+			},
+			wantCnames: ketchv1.CnameList{
+				{Name: "tools.theketch.io", Policies: &ketchv1.IngressPolicies{
+					IPAllowlist: []string{"10.0.0.0/8"},
+					Auth: &ketchv1.IngressAuth{ForwardAuth: &ketchv1.ForwardAuth{
+						URL:             "https://oauth2.theketch.io/oauth2/auth",
+						SignInURL:       "https://oauth2.theketch.io/oauth2/start",
+						ResponseHeaders: []string{"X-Auth-Request-Email"},
+					}},
+				}},
+			},
+		},
+		{
+			name:    "missing secret",
+			options: cnameAddOptions{appName: "go-app", cname: "tools.theketch.io", basicAuthSecret: "missing-users"},
+			wantErr: `failed to get secret ketch-go-app/missing-users: secrets "missing-users" not found`,
+		},
+		{
+			name:    "invalid htpasswd file",
+			options: cnameAddOptions{appName: "go-app", cname: "tools.theketch.io", htpasswd: invalidHtpasswdFile},
+			wantErr: "invalid htpasswd file: line 1 should have the user:hash format",
+		},
+		{
+			name:    "two auth methods",
+			options: cnameAddOptions{appName: "go-app", cname: "tools.theketch.io", htpasswd: htpasswdFile, forwardAuthURL: "https://oauth2.theketch.io/oauth2/auth"},
+			wantErr: "cname tools.theketch.io: auth: exactly one of basicAuthSecret, forwardAuth and jwt must be set",
+		},
+		{
+			name:    "jwt with nginx",
+			options: cnameAddOptions{appName: "go-app", cname: "tools.theketch.io", jwtIssuer: "https://accounts.theketch.io", jwtJWKSURI: "https://accounts.theketch.io/jwks.json"},
+			wantErr: "cname tools.theketch.io: auth: jwt is not supported by the nginx ingress controller, use forwardAuth",
+		},
+		{
+			name:        "basic auth with istio",
+			options:     cnameAddOptions{appName: "go-app", cname: "tools.theketch.io", basicAuthSecret: "missing-users"},
+			ingressType: ketchv1.IstioIngressControllerType,
+			wantErr:     "cname tools.theketch.io: auth: basic auth is not supported by the istio ingress controller",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testApp := app.DeepCopy()
+			if len(tt.ingressType) > 0 {
+				testApp.Spec.Ingress.Controller.IngressType = tt.ingressType
+			}
+			objects := []runtime.Object{testApp, usersSecret.DeepCopy()}
+			for _, secret := range tt.secrets {
+				objects = append(objects, secret.DeepCopyObject())
+			}
+			cfg := &mocks.Configuration{
+				CtrlClientObjects: objects,
+			}
+			err := cnameAdd(context.Background(), cfg, tt.options, &bytes.Buffer{})
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+
+			gotApp := ketchv1.App{}
+			require.Nil(t, cfg.Client().Get(context.Background(), types.NamespacedName{Name: "go-app"}, &gotApp))
+			require.Equal(t, tt.wantCnames, gotApp.Spec.Ingress.Cnames)
+			if len(tt.wantSecretName) > 0 {
+				gotSecret := corev1.Secret{}
+				require.Nil(t, cfg.Client().Get(context.Background(), types.NamespacedName{Namespace: "ketch-go-app", Name: tt.wantSecretName}, &gotSecret))
+				require.Equal(t, strings.TrimSpace(htpasswd), string(gotSecret.Data["auth"]))
+				require.Equal(t, strings.TrimSpace(htpasswd), string(gotSecret.Data["users"]))
+			}
+		})
+	}
+}
@@ -6,14 +6,20 @@ import (
 	"io"
 
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
 	"github.com/theketchio/ketch/internal/deploy"
+	"github.com/theketchio/ketch/internal/utils"
 )
 
 const cnameRemoveHelp = `
 Remove a CNAME from an application.
+
+TLS and basic auth secrets created by "ketch cname add" for the CNAME are deleted unless another CNAME of the application uses them.
 `
 
 func newCnameRemoveCmd(cfg config, out io.Writer) *cobra.Command {
@@ -47,8 +53,10 @@ func cnameRemove(ctx context.Context, cfg config, options cnameRemoveOptions, ou
 		return fmt.Errorf("failed to get the app: %w", err)
 	}
 	cnames := make(ketchv1.CnameList, 0, len(app.Spec.Ingress.Cnames))
+	var removed ketchv1.CnameList
 	for _, cname := range app.Spec.Ingress.Cnames {
 		if cname.Name == options.cname {
+			removed = append(removed, cname)
 			continue
 		}
 		cnames = append(cnames, cname)
@@ -57,5 +65,53 @@ func cnameRemove(ctx context.Context, cfg config, options cnameRemoveOptions, ou
 	if err := cfg.Client().Update(ctx, &app); err != nil {
 		return fmt.Errorf("failed to update the app: %w", err)
 	}
+	secrets := []string{cnameTLSSecretName(app.Name, options.cname), cnameBasicAuthSecretName(app.Name, options.cname)}
+	for _, cname := range removed {
+		secrets = append(secrets, cname.SecretName)
+		if cname.Policies != nil && cname.Policies.Auth != nil {
+			secrets = append(secrets, cname.Policies.Auth.BasicAuthSecret)
+		}
+	}
+	deleted := map[string]bool{"": true}
+	for _, name := range secrets {
+		if deleted[name] {
+			continue
+		}
+		deleted[name] = true
+		if err := deleteCnameSecret(ctx, cfg, app, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteCnameSecret deletes a secret created by "cname add" for the app,
+// secrets of other apps and secrets used by remaining cnames of the app are kept.
+func deleteCnameSecret(ctx context.Context, cfg config, app ketchv1.App, name string) error {
+	if policies := app.Spec.Ingress.Policies; policies != nil && policies.Auth != nil && policies.Auth.BasicAuthSecret == name {
+		return nil
+	}
+	for _, cname := range app.Spec.Ingress.Cnames {
+		if cname.SecretName == name {
+			return nil
+		}
+		if cname.Policies != nil && cname.Policies.Auth != nil && cname.Policies.Auth.BasicAuthSecret == name {
+			return nil
+		}
+	}
+	secret := corev1.Secret{}
+	err := cfg.Client().Get(ctx, types.NamespacedName{Namespace: app.Spec.Namespace, Name: name}, &secret)
+	if k8serrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get secret %s/%s: %w", app.Spec.Namespace, name, err)
+	}
+	if secret.Labels[utils.KetchAppNameLabel] != app.Name {
+		return nil
+	}
+	if err := cfg.Client().Delete(ctx, &secret); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete secret %s/%s: %w", app.Spec.Namespace, name, err)
+	}
 	return nil
 }
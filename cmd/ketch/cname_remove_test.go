@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
+	"github.com/theketchio/ketch/internal/mocks"
+	"github.com/theketchio/ketch/internal/utils"
+)
+
+func TestCnameRemove(t *testing.T) {
+	app := &ketchv1.App{
+		ObjectMeta: metav1.ObjectMeta{Name: "go-app"},
+		Spec: ketchv1.AppSpec{
+			Namespace: "ketch-go-app",
+			Ingress: ketchv1.IngressSpec{
+				Cnames: ketchv1.CnameList{
+					{Name: "www.theketch.io", Secure: true, SecretName: "go-app-tls-www-theketch-io"},
+					{Name: "api.theketch.io", Secure: true, SecretName: "go-app-tls-api-theketch-io"},
+					{
+						Name:       "theketch.io",
+						Secure:     true,
+						SecretName: "go-app-tls-api-theketch-io",
+						Policies:   &ketchv1.IngressPolicies{Auth: &ketchv1.IngressAuth{BasicAuthSecret: "go-app-basic-auth-api-theketch-io"}},
+					},
+					{
+						Name:       "docs.theketch.io",
+						Secure:     true,
+						SecretName: "docs-tls",
+						Policies:   &ketchv1.IngressPolicies{Auth: &ketchv1.IngressAuth{BasicAuthSecret: "docs-users"}},
+					},
+				},
+			},
+		},
+	}
+	newSecret := func(name, appName string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "ketch-go-app",
+				Labels:    map[string]string{utils.KetchAppNameLabel: appName},
+			},
+			Type: corev1.SecretTypeTLS,
+		}
+	}
+	tests := []struct {
+		name          string
+		cname         string
+		secrets       []runtime.Object
+		wantCnames    []string
+		wantDeleted   []string
+		wantRemaining []string
+	}{
+		{
+			name:        "secret created by cname add is deleted",
+			cname:       "www.theketch.io",
+			secrets:     []runtime.Object{newSecret("go-app-tls-www-theketch-io", "go-app")},
+			wantCnames:  []string{"api.theketch.io", "theketch.io", "docs.theketch.io"},
+			wantDeleted: []string{"go-app-tls-www-theketch-io"},
+		},
+		{
+			name:          "secret of another app is kept",
+			cname:         "www.theketch.io",
+			secrets:       []runtime.Object{newSecret("go-app-tls-www-theketch-io", "orders")},
+			wantCnames:    []string{"api.theketch.io", "theketch.io", "docs.theketch.io"},
+			wantRemaining: []string{"go-app-tls-www-theketch-io"},
+		},
+		{
+			name:          "secret used by another cname is kept",
+			cname:         "api.theketch.io",
+			secrets:       []runtime.Object{newSecret("go-app-tls-api-theketch-io", "go-app")},
+			wantCnames:    []string{"www.theketch.io", "theketch.io", "docs.theketch.io"},
+			wantRemaining: []string{"go-app-tls-api-theketch-io"},
+		},
+		{
+			name:  "basic auth secret created by cname add is deleted",
+			cname: "www.theketch.io",
+			secrets: []runtime.Object{
+				newSecret("go-app-tls-www-theketch-io", "go-app"),
+				newSecret("go-app-basic-auth-www-theketch-io", "go-app"),
+			},
+			wantCnames:  []string{"api.theketch.io", "theketch.io", "docs.theketch.io"},
+			wantDeleted: []string{"go-app-tls-www-theketch-io", "go-app-basic-auth-www-theketch-io"},
+		},
+		{
+			name:          "basic auth secret used by another cname is kept",
+			cname:         "api.theketch.io",
+			secrets:       []runtime.Object{newSecret("go-app-basic-auth-api-theketch-io", "go-app")},
+			wantCnames:    []string{"www.theketch.io", "theketch.io", "docs.theketch.io"},
+			wantRemaining: []string{"go-app-basic-auth-api-theketch-io"},
+		},
+		{
+			name:        "secret set with --secret is deleted",
+			cname:       "docs.theketch.io",
+			secrets:     []runtime.Object{newSecret("docs-tls", "go-app")},
+			wantCnames:  []string{"www.theketch.io", "api.theketch.io", "theketch.io"},
+			wantDeleted: []string{"docs-tls"},
+		},
+		{
+			name:          "secret set with --secret without the app label is kept",
+			cname:         "docs.theketch.io",
+			secrets:       []runtime.Object{newSecret("docs-tls", "")},
+			wantCnames:    []string{"www.theketch.io", "api.theketch.io", "theketch.io"},
+			wantRemaining: []string{"docs-tls"},
+		},
+		{
+			name:        "basic auth secret set with --basic-auth-secret is deleted",
+			cname:       "docs.theketch.io",
+			secrets:     []runtime.Object{newSecret("docs-users", "go-app")},
+			wantCnames:  []string{"www.theketch.io", "api.theketch.io", "theketch.io"},
+			wantDeleted: []string{"docs-users"},
+		},
+		{
+			name:          "basic auth secret set with --basic-auth-secret without the app label is kept",
+			cname:         "docs.theketch.io",
+			secrets:       []runtime.Object{newSecret("docs-users", "")},
+			wantCnames:    []string{"www.theketch.io", "api.theketch.io", "theketch.io"},
+			wantRemaining: []string{"docs-users"},
+		},
+		{
+			name:       "no secret",
+			cname:      "www.theketch.io",
+			wantCnames: []string{"api.theketch.io", "theketch.io", "docs.theketch.io"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &mocks.Configuration{
+				CtrlClientObjects: append([]runtime.Object{app.DeepCopy()}, tt.secrets...),
+			}
+			err := cnameRemove(context.Background(), cfg, cnameRemoveOptions{appName: "go-app", cname: tt.cname}, &bytes.Buffer{})
+			require.Nil(t, err)
+
+			gotApp := ketchv1.App{}
+			require.Nil(t, cfg.Client().Get(context.Background(), types.NamespacedName{Name: "go-app"}, &gotApp))
+			var gotCnames []string
+			for _, cname := range gotApp.Spec.Ingress.Cnames {
+				gotCnames = append(gotCnames, cname.Name)
+			}
+			require.Equal(t, tt.wantCnames, gotCnames)
+			for _, name := range tt.wantDeleted {
+				err := cfg.Client().Get(context.Background(), types.NamespacedName{Namespace: "ketch-go-app", Name: name}, &corev1.Secret{})
+				require.True(t, k8serrors.IsNotFound(err))
+			}
+			for _, name := range tt.wantRemaining {
+				require.Nil(t, cfg.Client().Get(context.Background(), types.NamespacedName{Namespace: "ketch-go-app", Name: name}, &corev1.Secret{}))
+			}
+		})
+	}
+}
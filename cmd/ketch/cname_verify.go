@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/theketchio/ketch/cmd/ketch/output"
+	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
+	"github.com/theketchio/ketch/internal/deploy"
+)
+
+const cnameVerifyHelp = `
+Verify that CNAMEs of an application point at the service endpoint of its ingress controller
+and show the state of their certificates.
+
+Without arguments, all CNAMEs of the application are verified:
+
+  ketch cname verify -a myapp
+  ketch cname verify www.example.com -a myapp
+
+The command fails if a CNAME doesn't resolve to the service endpoint.
+`
+
+// errCnameVerificationFailed is returned when a cname doesn't resolve to the service endpoint of the ingress controller.
+var errCnameVerificationFailed = errors.New("some cnames don't resolve to the service endpoint of the ingress controller")
+
+// hostResolver looks up addresses of a host, net.DefaultResolver implements it.
+type hostResolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+func newCnameVerifyCmd(cfg config, out io.Writer) *cobra.Command {
+	options := cnameVerifyOptions{}
+	cmd := &cobra.Command{
+		Use:   "verify [CNAME]",
+		Args:  cobra.MaximumNArgs(1),
+		Short: "Verify DNS records and certificates of CNAMEs of an application.",
+		Long:  cnameVerifyHelp,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				options.cname = args[0]
+			}
+			return cnameVerify(cmd.Context(), cfg, options, net.DefaultResolver, out)
+		},
+	}
+	cmd.Flags().StringVarP(&options.appName, deploy.FlagApp, deploy.FlagAppShort, "", "The name of the app.")
+	cmd.MarkFlagRequired(deploy.FlagApp)
+	cmd.RegisterFlagCompletionFunc(deploy.FlagApp, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return autoCompleteAppNames(cfg, toComplete)
+	})
+	return cmd
+}
+
+type cnameVerifyOptions struct {
+	appName string
+	cname   string
+}
+
+type cnameVerifyOutput struct {
+	Cname       string `column:"CNAME"`
+	DNS         string `column:"DNS"`
+	Certificate string `column:"CERTIFICATE"`
+}
+
+func cnameVerify(ctx context.Context, cfg config, options cnameVerifyOptions, resolver hostResolver, out io.Writer) error {
+	app := ketchv1.App{}
+	if err := cfg.Client().Get(ctx, types.NamespacedName{Name: options.appName}, &app); err != nil {
+		return fmt.Errorf("failed to get the app: %w", err)
+	}
+	var cnames []ketchv1.Cname
+	for _, cname := range app.Spec.Ingress.Cnames {
+		if len(options.cname) == 0 || cname.Name == options.cname {
+			cnames = append(cnames, cname)
+		}
+	}
+	if len(cnames) == 0 {
+		if len(options.cname) > 0 {
+			return fmt.Errorf("app %s doesn't have cname %s", app.Name, options.cname)
+		}
+		return fmt.Errorf("app %s doesn't have cnames", app.Name)
+	}
+	endpoint := app.Spec.Ingress.Controller.ServiceEndpoint
+	if len(endpoint) == 0 {
+		return fmt.Errorf("the ingress controller of app %s doesn't have a service endpoint", app.Name)
+	}
+	endpointAddrs, err := lookupEndpoint(ctx, resolver, endpoint)
+	if err != nil {
+		return fmt.Errorf("failed to resolve service endpoint %s: %w", endpoint, err)
+	}
+
+	failed := false
+	rows := make([]cnameVerifyOutput, 0, len(cnames))
+	for _, cname := range cnames {
+		dns, ok := verifyCnameDNS(ctx, resolver, cname.Name, endpointAddrs)
+		if !ok {
+			failed = true
+		}
+		rows = append(rows, cnameVerifyOutput{
+			Cname:       cname.Name,
+			DNS:         dns,
+			Certificate: cnameCertificateState(ctx, cfg, app, cname),
+		})
+	}
+	if err := output.Write(rows, out, "column"); err != nil {
+		return err
+	}
+	if failed {
+		return errCnameVerificationFailed
+	}
+	return nil
+}
+
+// lookupEndpoint returns addresses of a service endpoint which is either an IP address or a hostname of a load balancer.
+func lookupEndpoint(ctx context.Context, resolver hostResolver, endpoint string) ([]string, error) {
+	if net.ParseIP(endpoint) != nil {
+		return []string{endpoint}, nil
+	}
+	return resolver.LookupHost(ctx, endpoint)
+}
+
+// verifyCnameDNS returns a description of the DNS state of a cname and whether it resolves to one of the endpoint addresses.
+func verifyCnameDNS(ctx context.Context, resolver hostResolver, cname string, endpointAddrs []string) (string, bool) {
+	addrs, err := resolver.LookupHost(ctx, cname)
+	if err != nil {
+		return fmt.Sprintf("lookup failed: %v", err), false
+	}
+	for _, addr := range addrs {
+		for _, endpointAddr := range endpointAddrs {
+			if addr == endpointAddr {
+				return "ok", true
+			}
+		}
+	}
+	return fmt.Sprintf("resolves to %s, expected %s", strings.Join(addrs, ","), strings.Join(endpointAddrs, ",")), false
+}
+
+// cnameCertificateState describes the certificate of a cname.
+// A user provided certificate is read from its secret, the state of a cert-manager certificate is recorded by the controller.
+func cnameCertificateState(ctx context.Context, cfg config, app ketchv1.App, cname ketchv1.Cname) string {
+	if !cname.Secure {
+		return "-"
+	}
+	if len(cname.SecretName) > 0 {
+		cert, err := getSecretCertificate(ctx, cfg, app.Spec.Namespace, cname.SecretName)
+		if err != nil {
+			return fmt.Sprintf("not ready: %v", err)
+		}
+		return fmt.Sprintf("user provided, expires %s", cert.NotAfter.UTC().Format(time.RFC3339))
+	}
+	status := app.Status.CnameStatus(cname.Name)
+	switch {
+	case status == nil:
+		return "unknown"
+	case status.CertificateReady && status.CertificateExpires != nil:
+		return fmt.Sprintf("ready, expires %s", status.CertificateExpires.UTC().Format(time.RFC3339))
+	case status.CertificateReady:
+		return "ready"
+	default:
+		return fmt.Sprintf("not ready: %s", status.LastError)
+	}
+}
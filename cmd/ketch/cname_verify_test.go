@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
+	"github.com/theketchio/ketch/internal/mocks"
+)
+
+// fakeResolver resolves hosts from a static table.
+type fakeResolver map[string][]string
+
+func (r fakeResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	addrs, ok := r[host]
+	if !ok {
+		return nil, fmt.Errorf("lookup %s: no such host", host)
+	}
+	return addrs, nil
+}
+
+func TestCnameVerify(t *testing.T) {
+	expires := metav1.NewTime(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	certPEM, keyPEM := newTestCertificate(t, []string{"own.theketch.io"},
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2031, 1, 1, 0, 0, 0, 0, time.UTC))
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "own-tls", Namespace: "ketch-go-app"},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{corev1.TLSCertKey: certPEM, corev1.TLSPrivateKeyKey: keyPEM},
+	}
+	newApp := func(endpoint string) *ketchv1.App {
+		return &ketchv1.App{
+			ObjectMeta: metav1.ObjectMeta{Name: "go-app"},
+			Spec: ketchv1.AppSpec{
+				Namespace: "ketch-go-app",
+				Ingress: ketchv1.IngressSpec{
+					Cnames: ketchv1.CnameList{
+						{Name: "theketch.io"},
+						{Name: "www.theketch.io", Secure: true},
+						{Name: "api.theketch.io", Secure: true},
+						{Name: "own.theketch.io", Secure: true, SecretName: "own-tls"},
+					},
+					Controller: ketchv1.IngressControllerSpec{ServiceEndpoint: endpoint},
+				},
+			},
+			Status: ketchv1.AppStatus{
+				Cnames: []ketchv1.CnameStatus{
+					{Name: "www.theketch.io", CertificateReady: true, CertificateExpires: &expires},
+					{Name: "api.theketch.io", LastError: "Issuing certificate as Secret does not exist"},
+				},
+			},
+		}
+	}
+	resolver := fakeResolver{
+		"theketch.io":     {"10.0.0.1"},
+		"www.theketch.io": {"10.0.0.1"},
+		"api.theketch.io": {"10.0.0.2"},
+		"own.theketch.io": {"10.0.0.1"},
+		"lb.example.com":  {"10.0.0.1"},
+	}
+	tests := []struct {
+		name    string
+		app     *ketchv1.App
+		options cnameVerifyOptions
+		wantOut string
+		wantErr string
+	}{
+		{
+			name:    "single cname",
+			app:     newApp("10.0.0.1"),
+			options: cnameVerifyOptions{appName: "go-app", cname: "www.theketch.io"},
+			wantOut: `CNAME              DNS    CERTIFICATE
+www.theketch.io    ok     ready, expires 2030-01-01T00:00:00Z
+`,
+		},
+		{
+			name:    "service endpoint is a hostname",
+			app:     newApp("lb.example.com"),
+			options: cnameVerifyOptions{appName: "go-app", cname: "own.theketch.io"},
+			wantOut: `CNAME              DNS    CERTIFICATE
+own.theketch.io    ok     user provided, expires 2031-01-01T00:00:00Z
+`,
+		},
+		{
+			name:    "all cnames",
+			app:     newApp("10.0.0.1"),
+			options: cnameVerifyOptions{appName: "go-app"},
+			wantOut: `CNAME              DNS                                        CERTIFICATE
+theketch.io        ok                                         -
+www.theketch.io    ok                                         ready, expires 2030-01-01T00:00:00Z
+api.theketch.io    resolves to 10.0.0.2, expected 10.0.0.1    not ready: Issuing certificate as Secret does not exist
+own.theketch.io    ok                                         user provided, expires 2031-01-01T00:00:00Z
+`,
+			wantErr: errCnameVerificationFailed.Error(),
+		},
+		{
+			name:    "unknown cname",
+			app:     newApp("10.0.0.1"),
+			options: cnameVerifyOptions{appName: "go-app", cname: "docs.theketch.io"},
+			wantErr: "app go-app doesn't have cname docs.theketch.io",
+		},
+		{
+			name:    "no service endpoint",
+			app:     newApp(""),
+			options: cnameVerifyOptions{appName: "go-app"},
+			wantErr: "the ingress controller of app go-app doesn't have a service endpoint",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &mocks.Configuration{
+				CtrlClientObjects: []runtime.Object{tt.app, secret},
+			}
+			out := &bytes.Buffer{}
+			err := cnameVerify(context.Background(), cfg, tt.options, resolver, out)
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.Nil(t, err)
+			}
+			require.Equal(t, tt.wantOut, out.String())
+		})
+	}
+}
@@ -23,5 +23,7 @@ func newEnvCmd(cfg config, out io.Writer) *cobra.Command {
 	cmd.AddCommand(newEnvSetCmd(cfg, out))
 	cmd.AddCommand(newEnvGetCmd(cfg, out))
 	cmd.AddCommand(newEnvUnsetCmd(cfg, out))
+	cmd.AddCommand(newEnvImportCmd(cfg, out))
+	cmd.AddCommand(newEnvExportCmd(cfg, out))
 	return cmd
 }
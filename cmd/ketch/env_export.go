@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/types"
+
+	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
+	"github.com/theketchio/ketch/internal/deploy"
+	"github.com/theketchio/ketch/internal/utils"
+)
+
+const envExportHelp = `
+Export environment variables of an application in the .env format.
+
+Values containing spaces, quotes or new lines are double-quoted so the output can be imported back with "ketch env import".
+
+ketch env export -a appname [--process web] > .env
+`
+
+func newEnvExportCmd(cfg config, out io.Writer) *cobra.Command {
+	options := envExportOptions{}
+	cmd := &cobra.Command{
+		Use:   "export",
+		Args:  cobra.NoArgs,
+		Short: "Export environment variables of an application in the .env format.",
+		Long:  envExportHelp,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return envExport(cmd.Context(), cfg, options, out)
+		},
+	}
+	cmd.Flags().StringVarP(&options.appName, deploy.FlagApp, deploy.FlagAppShort, "", "The name of the app.")
+	cmd.MarkFlagRequired(deploy.FlagApp)
+	cmd.RegisterFlagCompletionFunc(deploy.FlagApp, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return autoCompleteAppNames(cfg, toComplete)
+	})
+	cmd.Flags().StringVarP(&options.processName, "process", "p", "", "Export the variables of the given process only.")
+	return cmd
+}
+
+type envExportOptions struct {
+	appName     string
+	processName string
+}
+
+func envExport(ctx context.Context, cfg config, options envExportOptions, out io.Writer) error {
+	app := ketchv1.App{}
+	if err := cfg.Client().Get(ctx, types.NamespacedName{Name: options.appName}, &app); err != nil {
+		return fmt.Errorf("failed to get the app: %w", err)
+	}
+	values := app.Envs(nil)
+	if options.processName != "" {
+		var err error
+		if values, err = app.ProcessEnvs(options.processName, nil); err != nil {
+			return fmt.Errorf("failed to get env variables for process %q: %w", options.processName, err)
+		}
+	}
+	envs := make([]ketchv1.Env, 0, len(values))
+	for name, value := range values {
+		envs = append(envs, ketchv1.Env{Name: name, Value: value})
+	}
+	sort.Slice(envs, func(i, j int) bool {
+		return envs[i].Name < envs[j].Name
+	})
+	return utils.WriteDotEnv(out, envs)
+}
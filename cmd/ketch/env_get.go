@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sort"
 
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/types"
@@ -15,7 +16,9 @@ import (
 const envGetHelp = `
 Retrieve environment variables for an application.
 
-ketch env-get [-a/--app appname] [ENVIRONMENT_VARIABLE1] [ENVIRONMENT_VARIABLE2] ...
+ketch env get [-a/--app appname] [--process web] [ENVIRONMENT_VARIABLE1] [ENVIRONMENT_VARIABLE2] ...
+
+When --process is given, app-level and process-level values are shown side by side.
 `
 
 func newEnvGetCmd(cfg config, out io.Writer) *cobra.Command {
@@ -32,12 +35,20 @@ func newEnvGetCmd(cfg config, out io.Writer) *cobra.Command {
 	}
 	cmd.Flags().StringVarP(&options.appName, "app", "a", "", "The name of the app.")
 	cmd.MarkFlagRequired("app")
+	cmd.Flags().StringVarP(&options.processName, "process", "p", "", "Show the process-level values of the given process next to the app-level ones.")
 	return cmd
 }
 
 type envGetOptions struct {
-	appName string
-	envs    []string
+	appName     string
+	processName string
+	envs        []string
+}
+
+type processEnvOutput struct {
+	Name         string `column:"NAME"`
+	AppValue     string `column:"APP"`
+	ProcessValue string `column:"PROCESS"`
 }
 
 func envGet(ctx context.Context, cfg config, options envGetOptions, out io.Writer) error {
@@ -45,5 +56,34 @@ func envGet(ctx context.Context, cfg config, options envGetOptions, out io.Write
 	if err := cfg.Client().Get(ctx, types.NamespacedName{Name: options.appName}, &app); err != nil {
 		return fmt.Errorf("failed to get the app: %w", err)
 	}
-	return output.Write(app.Envs(options.envs), out, "column")
+	if options.processName == "" {
+		return output.Write(app.Envs(options.envs), out, "column")
+	}
+	processEnvs, err := app.ProcessEnvs(options.processName, options.envs)
+	if err != nil {
+		return fmt.Errorf("failed to get env variables for process %q: %w", options.processName, err)
+	}
+	return output.Write(processEnvsToOutput(app.Envs(options.envs), processEnvs), out, "column")
+}
+
+func processEnvsToOutput(appEnvs, processEnvs map[string]string) []processEnvOutput {
+	names := make(map[string]struct{}, len(appEnvs)+len(processEnvs))
+	for name := range appEnvs {
+		names[name] = struct{}{}
+	}
+	for name := range processEnvs {
+		names[name] = struct{}{}
+	}
+	result := make([]processEnvOutput, 0, len(names))
+	for name := range names {
+		result = append(result, processEnvOutput{
+			Name:         name,
+			AppValue:     appEnvs[name],
+			ProcessValue: processEnvs[name],
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
 }
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/theketchio/ketch/internal/deploy"
+	"github.com/theketchio/ketch/internal/utils"
+)
+
+const envImportHelp = `
+Import environment variables for an application from a .env file.
+
+Each line of the file has NAME=VALUE format, blank lines and comments starting with '#' are ignored.
+Values can be quoted with single or double quotes, quoted values can span multiple lines.
+Use "-" as the file name to read variables from standard input.
+
+ketch env import -a appname [--process web] .env
+`
+
+func newEnvImportCmd(cfg config, out io.Writer) *cobra.Command {
+	options := envImportOptions{}
+	cmd := &cobra.Command{
+		Use:   "import FILE",
+		Args:  cobra.ExactArgs(1),
+		Short: "Import environment variables for an application from a .env file.",
+		Long:  envImportHelp,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.filename = args[0]
+			return envImport(cmd.Context(), cfg, options, cmd.InOrStdin(), out)
+		},
+	}
+	cmd.Flags().StringVarP(&options.appName, deploy.FlagApp, deploy.FlagAppShort, "", "The name of the app.")
+	cmd.MarkFlagRequired(deploy.FlagApp)
+	cmd.RegisterFlagCompletionFunc(deploy.FlagApp, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return autoCompleteAppNames(cfg, toComplete)
+	})
+	cmd.Flags().StringVarP(&options.processName, "process", "p", "", "Import the variables for the given process only.")
+	return cmd
+}
+
+type envImportOptions struct {
+	appName     string
+	processName string
+	filename    string
+}
+
+func envImport(ctx context.Context, cfg config, options envImportOptions, in io.Reader, out io.Writer) error {
+	if options.filename != "-" {
+		f, err := os.Open(options.filename)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", options.filename, err)
+		}
+		defer f.Close()
+		in = f
+	}
+	envs, err := utils.ParseDotEnv(in)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", options.filename, err)
+	}
+	if len(envs) == 0 {
+		return fmt.Errorf("no env variables found in %s", options.filename)
+	}
+	if err := updateAppEnvs(ctx, cfg, options.appName, options.processName, envs); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Successfully imported %d env variables\n", len(envs))
+	return nil
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"io"
-	"log"
 
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/types"
@@ -16,12 +15,17 @@ import (
 
 const envSetHelp = `
 Set environment variables for an application.
+
+Variables are set for all processes of the application unless --process is given,
+in which case they are set only for the given process.
+
+ketch env set -a appname [--process web] NAME1=VALUE1 [NAME2=VALUE2] ...
 `
 
 func newEnvSetCmd(cfg config, out io.Writer) *cobra.Command {
 	options := envSetOptions{}
 	cmd := &cobra.Command{
-		Use:   "set",
+		Use:   "set NAME1=VALUE1 NAME2=VALUE2 ...",
 		Args:  cobra.MinimumNArgs(1),
 		Short: "Set environment variables for an application.",
 		Long:  envSetHelp,
@@ -36,24 +40,38 @@ func newEnvSetCmd(cfg config, out io.Writer) *cobra.Command {
 	cmd.RegisterFlagCompletionFunc(deploy.FlagApp, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return autoCompleteAppNames(cfg, toComplete)
 	})
+	cmd.Flags().StringVarP(&options.processName, "process", "p", "", "Set the variables for the given process only.")
 	return cmd
 }
 
 type envSetOptions struct {
-	appName string
-	envs    []string
+	appName     string
+	processName string
+	envs        []string
 }
 
 func envSet(ctx context.Context, cfg config, options envSetOptions, out io.Writer) error {
 	envs, err := utils.MakeEnvironments(options.envs)
 	if err != nil {
-		return fmt.Errorf("failed to get kubernetes client: %w", err)
+		return fmt.Errorf("failed to parse env variables: %w", err)
+	}
+	return updateAppEnvs(ctx, cfg, options.appName, options.processName, envs)
+}
+
+// updateAppEnvs sets the envs either on the app level or on the process level if processName is not empty.
+func updateAppEnvs(ctx context.Context, cfg config, appName, processName string, envs []ketchv1.Env) error {
+	if err := ketchv1.ValidateEnvNames(envs); err != nil {
+		return err
 	}
 	app := ketchv1.App{}
-	if err = cfg.Client().Get(ctx, types.NamespacedName{Name: options.appName}, &app); err != nil {
-		log.Fatalf("failed to get the app: %v", err)
+	if err := cfg.Client().Get(ctx, types.NamespacedName{Name: appName}, &app); err != nil {
+		return fmt.Errorf("failed to get the app: %w", err)
+	}
+	if processName == "" {
+		app.SetEnvs(envs)
+	} else if err := app.SetProcessEnvs(processName, envs); err != nil {
+		return fmt.Errorf("failed to set env variables for process %q: %w", processName, err)
 	}
-	app.SetEnvs(envs)
 	if err := cfg.Client().Update(ctx, &app); err != nil {
 		return fmt.Errorf("failed to update the app: %w", err)
 	}
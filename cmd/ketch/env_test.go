@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
+	"github.com/theketchio/ketch/internal/mocks"
+)
+
+func newEnvTestApp() *ketchv1.App {
+	return &ketchv1.App{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "go-app",
+		},
+		Spec: ketchv1.AppSpec{
+			Env: []ketchv1.Env{
+				{Name: "PORT", Value: "8080"},
+				{Name: "GREETING", Value: "hello world"},
+			},
+			Deployments: []ketchv1.AppDeploymentSpec{
+				{
+					Version: 1,
+					Processes: []ketchv1.ProcessSpec{
+						{Name: "web", Env: []ketchv1.Env{{Name: "PORT", Value: "9090"}}},
+						{Name: "worker"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestEnvImport(t *testing.T) {
+	dir := t.TempDir()
+	dotEnv := filepath.Join(dir, ".env")
+	require.Nil(t, os.WriteFile(dotEnv, []byte("# comment\nDATABASE_URL=postgres://db?sslmode=disable\nCERT=\"a\nb\"\n"), 0644))
+	invalidDotEnv := filepath.Join(dir, "invalid.env")
+	require.Nil(t, os.WriteFile(invalidDotEnv, []byte("DATABASE_URL\n"), 0644))
+
+	tests := []struct {
+		name        string
+		options     envImportOptions
+		wantAppEnvs []ketchv1.Env
+		wantWebEnvs []ketchv1.Env
+		wantErr     string
+	}{
+		{
+			name:    "app-level",
+			options: envImportOptions{appName: "go-app", filename: dotEnv},
+			wantAppEnvs: []ketchv1.Env{
+				{Name: "PORT", Value: "8080"},
+				{Name: "GREETING", Value: "hello world"},
+				{Name: "DATABASE_URL", Value: "postgres://db?sslmode=disable"},
+				{Name: "CERT", Value: "a\nb"},
+			},
+			wantWebEnvs: []ketchv1.Env{{Name: "PORT", Value: "9090"}},
+		},
+		{
+			name:    "process-level",
+			options: envImportOptions{appName: "go-app", processName: "web", filename: dotEnv},
+			wantAppEnvs: []ketchv1.Env{
+				{Name: "PORT", Value: "8080"},
+				{Name: "GREETING", Value: "hello world"},
+			},
+			wantWebEnvs: []ketchv1.Env{
+				{Name: "PORT", Value: "9090"},
+				{Name: "DATABASE_URL", Value: "postgres://db?sslmode=disable"},
+				{Name: "CERT", Value: "a\nb"},
+			},
+		},
+		{
+			name:    "unknown process",
+			options: envImportOptions{appName: "go-app", processName: "cron", filename: dotEnv},
+			wantErr: `failed to set env variables for process "cron": process not found`,
+		},
+		{
+			name:    "invalid file",
+			options: envImportOptions{appName: "go-app", filename: invalidDotEnv},
+			wantErr: "failed to parse " + invalidDotEnv + ": line 1: env variables should have NAME=VALUE format",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &mocks.Configuration{
+				CtrlClientObjects: []runtime.Object{newEnvTestApp()},
+			}
+			out := &bytes.Buffer{}
+			err := envImport(context.Background(), cfg, tt.options, nil, out)
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, "Successfully imported 2 env variables\n", out.String())
+
+			gotApp := ketchv1.App{}
+			require.Nil(t, cfg.Client().Get(context.Background(), types.NamespacedName{Name: "go-app"}, &gotApp))
+			require.Equal(t, tt.wantAppEnvs, gotApp.Spec.Env)
+			require.Equal(t, tt.wantWebEnvs, gotApp.Spec.Deployments[0].Processes[0].Env)
+		})
+	}
+}
+
+func TestEnvExport(t *testing.T) {
+	tests := []struct {
+		name    string
+		options envExportOptions
+		want    string
+		wantErr string
+	}{
+		{
+			name:    "app-level",
+			options: envExportOptions{appName: "go-app"},
+			want:    "GREETING=\"hello world\"\nPORT=8080\n",
+		},
+		{
+			name:    "process-level",
+			options: envExportOptions{appName: "go-app", processName: "web"},
+			want:    "PORT=9090\n",
+		},
+		{
+			name:    "unknown process",
+			options: envExportOptions{appName: "go-app", processName: "cron"},
+			wantErr: `failed to get env variables for process "cron": process not found`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &mocks.Configuration{
+				CtrlClientObjects: []runtime.Object{newEnvTestApp()},
+			}
+			out := &bytes.Buffer{}
+			err := envExport(context.Background(), cfg, tt.options, out)
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func TestEnvGet(t *testing.T) {
+	tests := []struct {
+		name    string
+		options envGetOptions
+		want    string
+	}{
+		{
+			name:    "app-level",
+			options: envGetOptions{appName: "go-app"},
+			want:    "GREETING       PORT\nhello world    8080\n",
+		},
+		{
+			name:    "process-level side by side",
+			options: envGetOptions{appName: "go-app", processName: "web"},
+			want:    "NAME        APP            PROCESS\nGREETING    hello world    \nPORT        8080           9090\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &mocks.Configuration{
+				CtrlClientObjects: []runtime.Object{newEnvTestApp()},
+			}
+			out := &bytes.Buffer{}
+			require.Nil(t, envGet(context.Background(), cfg, tt.options, out))
+			require.Equal(t, tt.want, out.String())
+		})
+	}
+}
@@ -15,13 +15,16 @@ import (
 const envUnsetHelp = `
 Unset environment variables for an application.
 
-ketch env-unset <ENVIRONMENT_VARIABLE1> [ENVIRONMENT_VARIABLE2] ... [ENVIRONMENT_VARIABLEN]
+Variables are unset for all processes of the application unless --process is given,
+in which case they are unset only for the given process.
+
+ketch env unset -a appname [--process web] <ENVIRONMENT_VARIABLE1> [ENVIRONMENT_VARIABLE2] ... [ENVIRONMENT_VARIABLEN]
 `
 
 func newEnvUnsetCmd(cfg config, out io.Writer) *cobra.Command {
 	options := envUnsetOptions{}
 	cmd := &cobra.Command{
-		Use:   "unset",
+		Use:   "unset ENV_VAR1 ENV_VAR2 ...",
 		Args:  cobra.MinimumNArgs(1),
 		Short: "Unset environment variables for an application.",
 		Long:  envUnsetHelp,
@@ -36,12 +39,14 @@ func newEnvUnsetCmd(cfg config, out io.Writer) *cobra.Command {
 	cmd.RegisterFlagCompletionFunc(deploy.FlagApp, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return autoCompleteAppNames(cfg, toComplete)
 	})
+	cmd.Flags().StringVarP(&options.processName, "process", "p", "", "Unset the variables for the given process only.")
 	return cmd
 }
 
 type envUnsetOptions struct {
-	appName string
-	envs    []string
+	appName     string
+	processName string
+	envs        []string
 }
 
 func envUnset(ctx context.Context, cfg config, options envUnsetOptions, out io.Writer) error {
@@ -49,7 +54,11 @@ func envUnset(ctx context.Context, cfg config, options envUnsetOptions, out io.W
 	if err := cfg.Client().Get(ctx, types.NamespacedName{Name: options.appName}, &app); err != nil {
 		return fmt.Errorf("failed to get the app: %w", err)
 	}
-	app.UnsetEnvs(options.envs)
+	if options.processName == "" {
+		app.UnsetEnvs(options.envs)
+	} else if err := app.UnsetProcessEnvs(options.processName, options.envs); err != nil {
+		return fmt.Errorf("failed to unset env variables for process %q: %w", options.processName, err)
+	}
 	if err := cfg.Client().Update(ctx, &app); err != nil {
 		return fmt.Errorf("failed to update the app: %w", err)
 	}
@@ -36,6 +36,15 @@ type ingressSetOptions struct {
 	serviceEndpoint string
 	ingressType     string
 	clusterIssuer   string
+	traefikAPIGroup string
+	profile         string
+
+	gatewayName      string
+	gatewayNamespace string
+
+	defaultCnameTemplate   string
+	defaultCnameSecretName string
+	keepDefaultCnames      bool
 }
 
 func newIngressCmd(cfg config, out io.Writer) *cobra.Command {
@@ -49,6 +58,7 @@ func newIngressCmd(cfg config, out io.Writer) *cobra.Command {
 	}
 	cmd.AddCommand(newIngressSetCmd(cfg, out))
 	cmd.AddCommand(newIngressGetCmd(cfg, out))
+	cmd.AddCommand(newIngressDetectCmd(cfg, out))
 	return cmd
 }
 
@@ -59,6 +69,45 @@ data:
   ingressType: nginx #required
   serviceEndpoint: 127.0.0.1 #required
   clusterIssuer: letsencrypt
+  traefikAPIGroup: traefik.io
+
+"ketch ingress detect" inspects the cluster and proposes the class name, type and service endpoint.
+
+With the traefik ingress type, ketch creates IngressRoutes and Middlewares of the traefik.io API group
+if the cluster serves it and of the deprecated traefik.containo.us group otherwise.
+Use --traefik-api-group to choose the group explicitly.
+
+Several ingress controllers can serve apps of a cluster, each of them is configured by a named profile
+stored in a ketch-ingress-<profile> configmap. Apps use a profile they were deployed with by "ketch app deploy --ingress-profile":
+
+  ketch ingress set --profile internal --ingress-type nginx --ingress-class-name nginx-internal --ingress-service-endpoint 10.0.0.1
+
+Apps get a default cname <app>.<ingress-service-endpoint>.shipa.cloud unless a default cname template is set.
+The template gets App, Namespace and ServiceEndpoint fields, and default cnames can be served over https
+with a wildcard certificate stored in a Secret in each app's namespace:
+
+  ketch ingress set --default-cname-template "{{.App}}.{{.Namespace}}.apps.example.com" --default-cname-secret apps-wildcard-tls
+
+Changing the template changes default cnames of existing apps. Use --keep-default-cnames
+to add the current default cname of each app to its cnames, so the apps stay reachable at their old addresses.
+
+A ClusterIssuer obtains certificates of secure cnames, it must exist before it is set.
+Ketch can create a Let's Encrypt ClusterIssuer:
+
+  ketch cluster-issuer create letsencrypt --email admin@example.com --ingress-class nginx
+  ketch ingress set --cluster-issuer letsencrypt
+
+With the gateway-api ingress type, HTTPRoutes of apps are attached to a shared Gateway
+and the service endpoint is the address of the Gateway. className is a name of its GatewayClass:
+
+  ketch ingress set --ingress-type gateway-api --ingress-class-name envoy-gateway --ingress-service-endpoint 127.0.0.1 \
+    --gateway-name ketch --gateway-namespace gateway-system
+
+The Gateway isn't created by ketch. It must have a listener named "http" on port 80
+and a listener named "https" on port 443 terminating TLS of secure cnames,
+and both listeners must allow routes from namespaces of apps, for example with "allowedRoutes.namespaces.from: All".
+Certificate Secrets of cnames are created in namespaces of apps, so the https listener refers to them
+with ReferenceGrants unless it uses its own wildcard certificate.
 `
 
 var ingressSetValidationError = fmt.Errorf("ingress-class-name, ingress-service-endpoint, and ingress-type are required")
@@ -67,7 +116,7 @@ func newIngressSetCmd(cfg config, out io.Writer) *cobra.Command {
 	var options ingressSetOptions
 
 	cmd := &cobra.Command{
-		Use:   "set [--ingress-class-name/-c <class_name>] [--ingress-service-endpoint/-s <service_endpoint>] [--ingress-type/-t <type>] [--cluster-issuer <cluster_issuer>]",
+		Use:   "set [--ingress-class-name/-c <class_name>] [--ingress-service-endpoint/-s <service_endpoint>] [--ingress-type/-t <type>] [--cluster-issuer <cluster_issuer>] [--traefik-api-group <group>] [--profile <profile>] [--default-cname-template <template>] [--default-cname-secret <secret>] [--keep-default-cnames] [--gateway-name <name>] [--gateway-namespace <namespace>]",
 		Short: "Set ingress controller values",
 		Long:  ingressSetHelp,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -76,15 +125,25 @@ func newIngressSetCmd(cfg config, out io.Writer) *cobra.Command {
 	}
 	cmd.Flags().StringVarP(&options.className, "ingress-class-name", "c", "", "If set, it is used as kubernetes.io/ingress.class annotations")
 	cmd.Flags().StringVarP(&options.serviceEndpoint, "ingress-service-endpoint", "s", "", "An IP address or DNS name of the ingress controller's Service")
-	cmd.Flags().StringVarP(&options.ingressType, "ingress-type", "t", "", "Ingress controller type: nginx, traefik, istio")
+	cmd.Flags().StringVarP(&options.ingressType, "ingress-type", "t", "", "Ingress controller type: nginx, traefik, istio, gateway-api")
 	cmd.Flags().StringVar(&options.clusterIssuer, "cluster-issuer", "", "ClusterIssuer to obtain SSL certificates")
+	cmd.Flags().StringVar(&options.traefikAPIGroup, "traefik-api-group", "", "API group of traefik resources: traefik.io or traefik.containo.us")
+	cmd.Flags().StringVar(&options.profile, "profile", "", "Name of an ingress profile to configure, the default ingress is configured if not set")
+	cmd.Flags().StringVar(&options.defaultCnameTemplate, "default-cname-template", "", "Template of default cnames of apps, e.g. {{.App}}.{{.Namespace}}.apps.example.com")
+	cmd.Flags().StringVar(&options.defaultCnameSecretName, "default-cname-secret", "", "Name of a Secret with a wildcard certificate to serve default cnames over https")
+	cmd.Flags().BoolVar(&options.keepDefaultCnames, "keep-default-cnames", false, "Add the current default cname of each app to its cnames")
+	cmd.Flags().StringVar(&options.gatewayName, "gateway-name", "", "Name of a shared Gateway HTTPRoutes of apps are attached to, required by the gateway-api ingress type")
+	cmd.Flags().StringVar(&options.gatewayNamespace, "gateway-namespace", "", "Namespace of the shared Gateway, a namespace of each app if not set")
 
 	return cmd
 }
 
 func ingressSet(ctx context.Context, cfg config, options ingressSetOptions, out io.Writer) error {
+	if err := ketchv1.ValidateIngressProfile(options.profile); err != nil {
+		return err
+	}
 	configmap := v1.ConfigMap{}
-	err := cfg.Client().Get(ctx, types.NamespacedName{Name: ketchv1.IngressConfigmapName, Namespace: ketchv1.IngressConfigmapNamespace}, &configmap)
+	err := cfg.Client().Get(ctx, types.NamespacedName{Name: ketchv1.IngressProfileConfigmapName(options.profile), Namespace: ketchv1.IngressConfigmapNamespace}, &configmap)
 	if client.IgnoreNotFound(err) != nil {
 		return fmt.Errorf("failed to get ingress: %w", err)
 	}
@@ -101,8 +160,33 @@ func ingressSet(ctx context.Context, cfg config, options ingressSetOptions, out
 		configmap.Data["ingressType"] = options.ingressType
 	}
 	if options.clusterIssuer != "" {
+		exists, err := clusterIssuerExist(cfg.DynamicClient(), ctx, options.clusterIssuer)
+		if err != nil {
+			return fmt.Errorf("failed to get cluster issuer: %w", err)
+		}
+		if !exists {
+			return fmt.Errorf("%w: %s, create it with \"ketch cluster-issuer create\"", ErrClusterIssuerNotFound, options.clusterIssuer)
+		}
 		configmap.Data["clusterIssuer"] = options.clusterIssuer
 	}
+	if options.traefikAPIGroup != "" {
+		configmap.Data["traefikAPIGroup"] = options.traefikAPIGroup
+	}
+	if options.defaultCnameTemplate != "" {
+		if err := ketchv1.ValidateDefaultCnameTemplate(options.defaultCnameTemplate); err != nil {
+			return err
+		}
+		configmap.Data["defaultCnameTemplate"] = options.defaultCnameTemplate
+	}
+	if options.defaultCnameSecretName != "" {
+		configmap.Data["defaultCnameSecretName"] = options.defaultCnameSecretName
+	}
+	if options.gatewayName != "" {
+		configmap.Data["gatewayName"] = options.gatewayName
+	}
+	if options.gatewayNamespace != "" {
+		configmap.Data["gatewayNamespace"] = options.gatewayNamespace
+	}
 	if val, ok := configmap.Data["className"]; !ok || val == "" {
 		return ingressSetValidationError
 	}
@@ -112,9 +196,24 @@ func ingressSet(ctx context.Context, cfg config, options ingressSetOptions, out
 	if val, ok := configmap.Data["ingressType"]; !ok || val == "" {
 		return ingressSetValidationError
 	}
+	spec := ketchv1.NewIngressControllerSpec(configmap)
+	if !spec.IngressType.IsSupported() {
+		return fmt.Errorf("unsupported ingress type %q", spec.IngressType)
+	}
+	if spec.IngressType == ketchv1.GatewayAPIIngressControllerType && spec.GatewayName == "" {
+		return fmt.Errorf("gateway-name is required by the %s ingress type", ketchv1.GatewayAPIIngressControllerType)
+	}
+	if group := configmap.Data["traefikAPIGroup"]; group != "" && group != ketchv1.TraefikAPIGroup && group != ketchv1.LegacyTraefikAPIGroup {
+		return fmt.Errorf("unsupported traefik API group %q", group)
+	}
+	configmap.Name = ketchv1.IngressProfileConfigmapName(options.profile)
+	if options.keepDefaultCnames {
+		if err := keepDefaultCnames(ctx, cfg, *ketchv1.NewIngressControllerSpec(configmap), out); err != nil {
+			return err
+		}
+	}
 	if err != nil {
 		// create
-		configmap.Name = ketchv1.IngressConfigmapName
 		configmap.Namespace = ketchv1.IngressConfigmapNamespace
 		if err := cfg.Client().Create(ctx, &configmap); err != nil {
 			return fmt.Errorf("failed to create ingress: %w", err)
@@ -129,6 +228,47 @@ func ingressSet(ctx context.Context, cfg config, options ingressSetOptions, out
 	return nil
 }
 
+// keepDefaultCnames adds the current default cname of each app served by the ingress profile to the app's cnames
+// if the new ingress controller configuration gives the app another default cname.
+func keepDefaultCnames(ctx context.Context, cfg config, controller ketchv1.IngressControllerSpec, out io.Writer) error {
+	var apps ketchv1.AppList
+	if err := cfg.Client().List(ctx, &apps); err != nil {
+		return fmt.Errorf("failed to list apps: %w", err)
+	}
+	for _, app := range apps.Items {
+		if app.Spec.Ingress.Controller.Profile != controller.Profile {
+			continue
+		}
+		current := app.DefaultCname()
+		if current == nil {
+			continue
+		}
+		if next, err := controller.DefaultCname(app.Name, app.Spec.Namespace); err == nil && next == *current {
+			continue
+		}
+		kept := false
+		for _, cname := range app.Spec.Ingress.Cnames {
+			if cname.Name == *current {
+				kept = true
+			}
+		}
+		if kept {
+			continue
+		}
+		secretName := app.Spec.Ingress.Controller.DefaultCnameSecretName
+		app.Spec.Ingress.Cnames = append(app.Spec.Ingress.Cnames, ketchv1.Cname{
+			Name:       *current,
+			Secure:     len(secretName) > 0,
+			SecretName: secretName,
+		})
+		if err := cfg.Client().Update(ctx, &app); err != nil {
+			return fmt.Errorf("failed to keep default cname of app %s: %w", app.Name, err)
+		}
+		fmt.Fprintf(out, "Kept %s as a cname of app %s\n", *current, app.Name)
+	}
+	return nil
+}
+
 var (
 	ingressGetTemplate = `Class Name: {{ .className }}
 Service Endpoint: {{ .serviceEndpoint }}
@@ -136,24 +276,38 @@ Ingress Type: {{ .ingressType }}
 {{- if .clusterIssuer }}
 Cluster Issuer: {{ .clusterIssuer }}
 {{- end }}
+{{- if .traefikAPIGroup }}
+Traefik API Group: {{ .traefikAPIGroup }}
+{{- end }}
+{{- if .defaultCnameTemplate }}
+Default Cname Template: {{ .defaultCnameTemplate }}
+{{- end }}
+{{- if .defaultCnameSecretName }}
+Default Cname Secret: {{ .defaultCnameSecretName }}
+{{- end }}
+{{- if .gatewayName }}
+Gateway: {{ with .gatewayNamespace }}{{ . }}/{{ end }}{{ .gatewayName }}
+{{- end }}
 `
 )
 
 func newIngressGetCmd(cfg config, out io.Writer) *cobra.Command {
+	var profile string
 	cmd := &cobra.Command{
-		Use:   "get",
+		Use:   "get [--profile <profile>]",
 		Short: "Get ingress controller values",
 		Long:  "Get ingress controller values",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return ingressGet(cmd.Context(), cfg, out)
+			return ingressGet(cmd.Context(), cfg, profile, out)
 		},
 	}
+	cmd.Flags().StringVar(&profile, "profile", "", "Name of an ingress profile, the default ingress is shown if not set")
 	return cmd
 }
 
-func ingressGet(ctx context.Context, cfg config, out io.Writer) error {
+func ingressGet(ctx context.Context, cfg config, profile string, out io.Writer) error {
 	configmap := v1.ConfigMap{}
-	if err := cfg.Client().Get(ctx, types.NamespacedName{Name: ketchv1.IngressConfigmapName, Namespace: ketchv1.IngressConfigmapNamespace}, &configmap); err != nil {
+	if err := cfg.Client().Get(ctx, types.NamespacedName{Name: ketchv1.IngressProfileConfigmapName(profile), Namespace: ketchv1.IngressConfigmapNamespace}, &configmap); err != nil {
 		return fmt.Errorf("failed to get ingress: %w", err)
 	}
 
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/theketchio/ketch/cmd/ketch/output"
+	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
+)
+
+const ingressDetectHelp = `
+Detect ingress controllers installed in the cluster and propose the ingress configuration for apps.
+
+IngressClasses, Deployments and Services of nginx, traefik and istio ingress gateways are inspected,
+the service endpoint is taken from the LoadBalancer status of the controller's Service.
+Use --write to save the proposed configuration to the ketch-ingress configmap (or the configmap of --profile):
+
+  ketch ingress detect
+  ketch ingress detect --ingress-type traefik --write
+`
+
+// errSeveralIngressControllers is returned when a configuration can't be written because several ingress controllers were detected.
+var errSeveralIngressControllers = errors.New("several ingress controllers were detected, choose one with --ingress-type")
+
+// isDefaultIngressClassAnnotation marks the IngressClass used by Ingresses without a class.
+const isDefaultIngressClassAnnotation = "ingressclass.kubernetes.io/is-default-class"
+
+// knownIngressController describes how to find an ingress controller of a supported type in a cluster.
+type knownIngressController struct {
+	ingressType ketchv1.IngressControllerType
+	// controllerName is the spec.controller field of IngressClasses of the ingress controller.
+	controllerName string
+	// defaultClassName is used when the ingress controller doesn't have an IngressClass.
+	defaultClassName string
+	// selector matches labels of Deployments and Services of the ingress controller as installed by its helm chart.
+	selector string
+}
+
+var knownIngressControllers = []knownIngressController{
+	{
+		ingressType:      ketchv1.NginxIngressControllerType,
+		controllerName:   "k8s.io/ingress-nginx",
+		defaultClassName: "nginx",
+		selector:         "app.kubernetes.io/name=ingress-nginx",
+	},
+	{
+		ingressType:      ketchv1.TraefikIngressControllerType,
+		controllerName:   "traefik.io/ingress-controller",
+		defaultClassName: "traefik",
+		selector:         "app.kubernetes.io/name=traefik",
+	},
+	{
+		ingressType:      ketchv1.IstioIngressControllerType,
+		controllerName:   "istio.io/ingress-controller",
+		defaultClassName: "istio",
+		selector:         "istio=ingressgateway",
+	},
+}
+
+type detectedIngressController struct {
+	IngressType     string `column:"TYPE"`
+	ClassName       string `column:"CLASS NAME"`
+	ServiceEndpoint string `column:"SERVICE ENDPOINT"`
+	Service         string `column:"SERVICE"`
+	Ready           string `column:"READY"`
+}
+
+type ingressDetectOptions struct {
+	ingressType string
+	profile     string
+	write       bool
+}
+
+func newIngressDetectCmd(cfg config, out io.Writer) *cobra.Command {
+	var options ingressDetectOptions
+	cmd := &cobra.Command{
+		Use:   "detect [--ingress-type <type>] [--profile <profile>] [--write]",
+		Args:  cobra.NoArgs,
+		Short: "Detect ingress controllers of the cluster",
+		Long:  ingressDetectHelp,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return ingressDetect(cmd.Context(), cfg, options, out)
+		},
+	}
+	cmd.Flags().StringVarP(&options.ingressType, "ingress-type", "t", "", "Propose an ingress controller of the type: nginx, traefik, istio")
+	cmd.Flags().StringVar(&options.profile, "profile", "", "Name of an ingress profile to write, the default ingress is written if not set")
+	cmd.Flags().BoolVar(&options.write, "write", false, "Write the proposed configuration to the ingress configmap")
+	return cmd
+}
+
+func ingressDetect(ctx context.Context, cfg config, options ingressDetectOptions, out io.Writer) error {
+	detected, err := detectIngressControllers(ctx, cfg.KubernetesClient())
+	if err != nil {
+		return err
+	}
+	var candidates []detectedIngressController
+	for _, controller := range detected {
+		if len(options.ingressType) > 0 && controller.IngressType != options.ingressType {
+			continue
+		}
+		if controller.ServiceEndpoint == "-" {
+			continue
+		}
+		candidates = append(candidates, controller)
+	}
+	if len(detected) > 0 {
+		if err := output.Write(detected, out, "column"); err != nil {
+			return err
+		}
+		fmt.Fprintln(out)
+	}
+	switch {
+	case len(candidates) == 0:
+		return errors.New("no ingress controller with a service endpoint was detected")
+	case len(candidates) > 1 && options.write:
+		return errSeveralIngressControllers
+	case len(candidates) > 1:
+		fmt.Fprintln(out, "Several ingress controllers were detected, choose one with --ingress-type.")
+		return nil
+	}
+	proposed := candidates[0]
+	setOptions := ingressSetOptions{
+		ingressType:     proposed.IngressType,
+		className:       proposed.ClassName,
+		serviceEndpoint: proposed.ServiceEndpoint,
+		profile:         options.profile,
+	}
+	if options.write {
+		return ingressSet(ctx, cfg, setOptions, out)
+	}
+	profileFlag := ""
+	if len(options.profile) > 0 {
+		profileFlag = " --profile " + options.profile
+	}
+	fmt.Fprintf(out, "Proposed ingress configuration:\n  ketch ingress set%s --ingress-type %s --ingress-class-name %s --ingress-service-endpoint %s\n",
+		profileFlag, setOptions.ingressType, setOptions.className, setOptions.serviceEndpoint)
+	fmt.Fprintf(out, "Run the command above or add --write to save it to the %s configmap.\n", ketchv1.IngressProfileConfigmapName(options.profile))
+	return nil
+}
+
+// detectIngressControllers returns ingress controllers of known types that have an IngressClass, a Deployment or a Service in the cluster.
+func detectIngressControllers(ctx context.Context, client kubernetes.Interface) ([]detectedIngressController, error) {
+	classes, err := client.NetworkingV1().IngressClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ingress classes: %w", err)
+	}
+	var detected []detectedIngressController
+	for _, known := range knownIngressControllers {
+		listOptions := metav1.ListOptions{LabelSelector: known.selector}
+		deployments, err := client.AppsV1().Deployments(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s deployments: %w", known.ingressType, err)
+		}
+		services, err := client.CoreV1().Services(metav1.NamespaceAll).List(ctx, listOptions)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s services: %w", known.ingressType, err)
+		}
+		className, hasClass := ingressClassName(classes.Items, known.controllerName)
+		if !hasClass && len(deployments.Items) == 0 && len(services.Items) == 0 {
+			continue
+		}
+		if !hasClass {
+			className = known.defaultClassName
+		}
+		controller := detectedIngressController{
+			IngressType:     known.ingressType.String(),
+			ClassName:       className,
+			ServiceEndpoint: "-",
+			Service:         "-",
+			Ready:           deploymentsReadiness(deployments.Items),
+		}
+		if service, endpoint := ingressServiceEndpoint(services.Items); service != nil {
+			controller.Service = fmt.Sprintf("%s/%s", service.Namespace, service.Name)
+			if len(endpoint) > 0 {
+				controller.ServiceEndpoint = endpoint
+			}
+		}
+		detected = append(detected, controller)
+	}
+	return detected, nil
+}
+
+// ingressClassName returns the name of an IngressClass of the controller preferring the default class of the cluster.
+func ingressClassName(classes []networkingv1.IngressClass, controllerName string) (string, bool) {
+	var names []string
+	for _, class := range classes {
+		if class.Spec.Controller != controllerName {
+			continue
+		}
+		if class.Annotations[isDefaultIngressClassAnnotation] == "true" {
+			return class.Name, true
+		}
+		names = append(names, class.Name)
+	}
+	if len(names) == 0 {
+		return "", false
+	}
+	sort.Strings(names)
+	return names[0], true
+}
+
+// ingressServiceEndpoint returns the Service exposing the ingress controller and its external IP address or DNS name.
+// A LoadBalancer Service with an assigned address is preferred over one still waiting for it and over other Services.
+func ingressServiceEndpoint(services []v1.Service) (*v1.Service, string) {
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].Namespace+"/"+services[i].Name < services[j].Namespace+"/"+services[j].Name
+	})
+	var pending *v1.Service
+	for i, service := range services {
+		if service.Spec.Type != v1.ServiceTypeLoadBalancer {
+			continue
+		}
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			if len(ingress.IP) > 0 {
+				return &services[i], ingress.IP
+			}
+			if len(ingress.Hostname) > 0 {
+				return &services[i], ingress.Hostname
+			}
+		}
+		if pending == nil {
+			pending = &services[i]
+		}
+	}
+	if pending != nil {
+		return pending, ""
+	}
+	for i, service := range services {
+		if len(service.Spec.ExternalIPs) > 0 {
+			return &services[i], service.Spec.ExternalIPs[0]
+		}
+	}
+	return nil, ""
+}
+
+// deploymentsReadiness returns ready and desired replicas of the Deployments.
+func deploymentsReadiness(deployments []appsv1.Deployment) string {
+	if len(deployments) == 0 {
+		return "-"
+	}
+	var ready, desired int32
+	for _, deployment := range deployments {
+		ready += deployment.Status.ReadyReplicas
+		if deployment.Spec.Replicas != nil {
+			desired += *deployment.Spec.Replicas
+		}
+	}
+	return fmt.Sprintf("%d/%d", ready, desired)
+}
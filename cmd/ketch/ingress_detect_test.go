@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
+	"github.com/theketchio/ketch/internal/mocks"
+)
+
+func TestIngressDetect(t *testing.T) {
+	replicas := int32(2)
+	nginxClass := &networkingv1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx-public", Annotations: map[string]string{isDefaultIngressClassAnnotation: "true"}},
+		Spec:       networkingv1.IngressClassSpec{Controller: "k8s.io/ingress-nginx"},
+	}
+	nginxInternalClass := &networkingv1.IngressClass{
+		ObjectMeta: metav1.ObjectMeta{Name: "nginx-internal"},
+		Spec:       networkingv1.IngressClassSpec{Controller: "k8s.io/ingress-nginx"},
+	}
+	nginxLabels := map[string]string{"app.kubernetes.io/name": "ingress-nginx"}
+	nginxDeployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingress-nginx-controller", Namespace: "ingress-nginx", Labels: nginxLabels},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{ReadyReplicas: 2},
+	}
+	nginxService := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingress-nginx-controller", Namespace: "ingress-nginx", Labels: nginxLabels},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer},
+		Status: v1.ServiceStatus{LoadBalancer: v1.LoadBalancerStatus{
+			Ingress: []v1.LoadBalancerIngress{{IP: "34.1.2.3"}},
+		}},
+	}
+	nginxAdmissionService := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingress-nginx-controller-admission", Namespace: "ingress-nginx", Labels: nginxLabels},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeClusterIP},
+	}
+	istioService := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "istio-ingressgateway", Namespace: "istio-system", Labels: map[string]string{"istio": "ingressgateway"}},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer},
+		Status: v1.ServiceStatus{LoadBalancer: v1.LoadBalancerStatus{
+			Ingress: []v1.LoadBalancerIngress{{Hostname: "gateway.elb.amazonaws.com"}},
+		}},
+	}
+	traefikService := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "traefik", Namespace: "traefik", Labels: map[string]string{"app.kubernetes.io/name": "traefik"}},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer},
+	}
+	tests := []struct {
+		name          string
+		objects       []runtime.Object
+		options       ingressDetectOptions
+		want          string
+		wantConfigmap map[string]string
+		wantErr       string
+	}{
+		{
+			name:    "nginx",
+			objects: []runtime.Object{nginxInternalClass, nginxClass, nginxDeployment, nginxService, nginxAdmissionService},
+			want: `TYPE     CLASS NAME      SERVICE ENDPOINT    SERVICE                                   READY
+nginx    nginx-public    34.1.2.3            ingress-nginx/ingress-nginx-controller    2/2
+
+Proposed ingress configuration:
+  ketch ingress set --ingress-type nginx --ingress-class-name nginx-public --ingress-service-endpoint 34.1.2.3
+Run the command above or add --write to save it to the ketch-ingress configmap.
+`,
+		},
+		{
+			name:    "write nginx to a profile",
+			objects: []runtime.Object{nginxClass, nginxDeployment, nginxService},
+			options: ingressDetectOptions{profile: "public", write: true},
+			want: `TYPE     CLASS NAME      SERVICE ENDPOINT    SERVICE                                   READY
+nginx    nginx-public    34.1.2.3            ingress-nginx/ingress-nginx-controller    2/2
+
+Successfully set!
+`,
+			wantConfigmap: map[string]string{
+				"className":       "nginx-public",
+				"ingressType":     "nginx",
+				"serviceEndpoint": "34.1.2.3",
+			},
+		},
+		{
+			name:    "several controllers",
+			objects: []runtime.Object{nginxClass, nginxService, istioService, traefikService},
+			want: `TYPE       CLASS NAME      SERVICE ENDPOINT             SERVICE                                   READY
+nginx      nginx-public    34.1.2.3                     ingress-nginx/ingress-nginx-controller    -
+traefik    traefik         -                            traefik/traefik                           -
+istio      istio           gateway.elb.amazonaws.com    istio-system/istio-ingressgateway         -
+
+Several ingress controllers were detected, choose one with --ingress-type.
+`,
+		},
+		{
+			name:    "several controllers with write",
+			objects: []runtime.Object{nginxClass, nginxService, istioService},
+			options: ingressDetectOptions{write: true},
+			wantErr: errSeveralIngressControllers.Error(),
+		},
+		{
+			name:    "choose a controller by type",
+			objects: []runtime.Object{nginxClass, nginxService, istioService},
+			options: ingressDetectOptions{ingressType: "istio"},
+			want: `TYPE     CLASS NAME      SERVICE ENDPOINT             SERVICE                                   READY
+nginx    nginx-public    34.1.2.3                     ingress-nginx/ingress-nginx-controller    -
+istio    istio           gateway.elb.amazonaws.com    istio-system/istio-ingressgateway         -
+
+Proposed ingress configuration:
+  ketch ingress set --ingress-type istio --ingress-class-name istio --ingress-service-endpoint gateway.elb.amazonaws.com
+Run the command above or add --write to save it to the ketch-ingress configmap.
+`,
+		},
+		{
+			name:    "load balancer is pending",
+			objects: []runtime.Object{traefikService},
+			wantErr: "no ingress controller with a service endpoint was detected",
+		},
+		{
+			name:    "no ingress controllers",
+			wantErr: "no ingress controller with a service endpoint was detected",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &mocks.Configuration{KubeClientObjects: tt.objects}
+			out := &bytes.Buffer{}
+			err := ingressDetect(context.Background(), cfg, tt.options, out)
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.want, out.String())
+			if tt.wantConfigmap != nil {
+				configmap := v1.ConfigMap{}
+				name := types.NamespacedName{Name: ketchv1.IngressProfileConfigmapName(tt.options.profile), Namespace: ketchv1.IngressConfigmapNamespace}
+				require.Nil(t, cfg.Client().Get(context.Background(), name, &configmap))
+				require.Equal(t, tt.wantConfigmap, configmap.Data)
+			}
+		})
+	}
+}
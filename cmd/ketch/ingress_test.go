@@ -28,6 +28,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 func TestIngressSet(t *testing.T) {
@@ -40,12 +41,23 @@ func TestIngressSet(t *testing.T) {
 			"ingressType":     "nginx",
 		},
 	}
+	app := &ketchv1.App{
+		ObjectMeta: metav1.ObjectMeta{Name: "go-app"},
+		Spec: ketchv1.AppSpec{
+			Namespace: "apps",
+			Ingress: ketchv1.IngressSpec{
+				GenerateDefaultCname: true,
+				Controller:           *ketchv1.NewIngressControllerSpec(*mockConfigmap),
+			},
+		},
+	}
 	tests := []struct {
-		name    string
-		cfg     config
-		options ingressSetOptions
-		want    string
-		wantErr string
+		name          string
+		cfg           config
+		options       ingressSetOptions
+		want          string
+		wantAppCnames ketchv1.CnameList
+		wantErr       string
 	}{
 		{
 			name: "successful update",
@@ -75,6 +87,124 @@ func TestIngressSet(t *testing.T) {
 			},
 			want: "Successfully set!\n",
 		},
+		{
+			name: "successful create with gateway api",
+			cfg:  &mocks.Configuration{},
+			options: ingressSetOptions{
+				ingressType:      "gateway-api",
+				className:        "envoy-gateway",
+				serviceEndpoint:  "127.0.0.1",
+				gatewayName:      "ketch",
+				gatewayNamespace: "gateway-system",
+			},
+			want: "Successfully set!\n",
+		},
+		{
+			name: "error - gateway api without gateway name",
+			cfg:  &mocks.Configuration{},
+			options: ingressSetOptions{
+				ingressType:     "gateway-api",
+				className:       "envoy-gateway",
+				serviceEndpoint: "127.0.0.1",
+			},
+			wantErr: "gateway-name is required by the gateway-api ingress type",
+		},
+		{
+			name: "error - unsupported ingress type",
+			cfg: &mocks.Configuration{
+				CtrlClientObjects: []runtime.Object{mockConfigmap},
+			},
+			options: ingressSetOptions{
+				ingressType: "haproxy",
+			},
+			wantErr: `unsupported ingress type "haproxy"`,
+		},
+		{
+			name: "successful update with traefik api group",
+			cfg: &mocks.Configuration{
+				CtrlClientObjects: []runtime.Object{mockConfigmap},
+			},
+			options: ingressSetOptions{
+				ingressType:     "traefik",
+				traefikAPIGroup: "traefik.containo.us",
+			},
+			want: "Successfully set!\n",
+		},
+		{
+			name: "successful update with cluster issuer",
+			cfg: &mocks.Configuration{
+				CtrlClientObjects:    []runtime.Object{mockConfigmap},
+				DynamicClientObjects: []runtime.Object{newTestClusterIssuer("letsencrypt-staging", map[string]interface{}{})},
+			},
+			options: ingressSetOptions{
+				clusterIssuer: "letsencrypt-staging",
+			},
+			want: "Successfully set!\n",
+		},
+		{
+			name: "error - cluster issuer not found",
+			cfg: &mocks.Configuration{
+				CtrlClientObjects: []runtime.Object{mockConfigmap},
+			},
+			options: ingressSetOptions{
+				clusterIssuer: "letsencrypt-staging",
+			},
+			wantErr: `cluster issuer not found: letsencrypt-staging, create it with "ketch cluster-issuer create"`,
+		},
+		{
+			name: "error - unsupported traefik api group",
+			cfg: &mocks.Configuration{
+				CtrlClientObjects: []runtime.Object{mockConfigmap},
+			},
+			options: ingressSetOptions{
+				ingressType:     "traefik",
+				traefikAPIGroup: "traefik.example.com",
+			},
+			wantErr: `unsupported traefik API group "traefik.example.com"`,
+		},
+		{
+			name: "successful create of a profile",
+			cfg: &mocks.Configuration{
+				CtrlClientObjects: []runtime.Object{mockConfigmap},
+			},
+			options: ingressSetOptions{
+				ingressType:     "traefik",
+				className:       "traefik-public",
+				serviceEndpoint: "10.0.0.1",
+				profile:         "public",
+			},
+			want: "Successfully set!\n",
+		},
+		{
+			name: "error - invalid profile",
+			cfg:  &mocks.Configuration{},
+			options: ingressSetOptions{
+				profile: "Public",
+			},
+			wantErr: `invalid ingress profile "Public": a lowercase RFC 1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')`,
+		},
+		{
+			name: "successful update of default cname template keeping default cnames",
+			cfg: &mocks.Configuration{
+				CtrlClientObjects: []runtime.Object{mockConfigmap, app},
+			},
+			options: ingressSetOptions{
+				defaultCnameTemplate: "{{.App}}.{{.Namespace}}.apps.example.com",
+				keepDefaultCnames:    true,
+			},
+			want:          "Kept go-app.127.0.0.1.shipa.cloud as a cname of app go-app\nSuccessfully set!\n",
+			wantAppCnames: ketchv1.CnameList{{Name: "go-app.127.0.0.1.shipa.cloud"}},
+		},
+		{
+			name: "error - invalid default cname template",
+			cfg: &mocks.Configuration{
+				CtrlClientObjects: []runtime.Object{mockConfigmap},
+			},
+			options: ingressSetOptions{
+				defaultCnameTemplate: "{{.App}}_apps.example.com",
+			},
+			wantErr: `invalid default cname "app_apps.example.com": a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')`,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -87,6 +217,11 @@ func TestIngressSet(t *testing.T) {
 			}
 			require.Nil(t, err)
 			require.Equal(t, tt.want, out.String())
+			if tt.wantAppCnames != nil {
+				gotApp := ketchv1.App{}
+				require.Nil(t, tt.cfg.Client().Get(context.Background(), types.NamespacedName{Name: "go-app"}, &gotApp))
+				require.Equal(t, tt.wantAppCnames, gotApp.Spec.Ingress.Cnames)
+			}
 		})
 	}
 }
@@ -104,6 +239,7 @@ func TestIngressGet(t *testing.T) {
 	tests := []struct {
 		name    string
 		cfg     config
+		profile string
 		want    string
 		wantErr string
 	}{
@@ -114,6 +250,37 @@ func TestIngressGet(t *testing.T) {
 			},
 			want: "Class Name: nginx\nService Endpoint: 127.0.0.1\nIngress Type: nginx\nCluster Issuer: letsencrypt\n",
 		},
+		{
+			name: "profile",
+			cfg: &mocks.Configuration{
+				CtrlClientObjects: []runtime.Object{mockConfigmap, &v1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: "ketch-ingress-public", Namespace: ketchv1.IngressConfigmapNamespace},
+					Data: map[string]string{
+						"className":       "traefik",
+						"serviceEndpoint": "10.0.0.1",
+						"ingressType":     "traefik",
+					},
+				}},
+			},
+			profile: "public",
+			want:    "Class Name: traefik\nService Endpoint: 10.0.0.1\nIngress Type: traefik\n",
+		},
+		{
+			name: "gateway api",
+			cfg: &mocks.Configuration{
+				CtrlClientObjects: []runtime.Object{&v1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: ketchv1.IngressConfigmapName, Namespace: ketchv1.IngressConfigmapNamespace},
+					Data: map[string]string{
+						"className":        "envoy-gateway",
+						"serviceEndpoint":  "10.0.0.2",
+						"ingressType":      "gateway-api",
+						"gatewayName":      "ketch",
+						"gatewayNamespace": "gateway-system",
+					},
+				}},
+			},
+			want: "Class Name: envoy-gateway\nService Endpoint: 10.0.0.2\nIngress Type: gateway-api\nGateway: gateway-system/ketch\n",
+		},
 		{
 			name:    "error - not set",
 			cfg:     &mocks.Configuration{},
@@ -123,7 +290,7 @@ func TestIngressGet(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			out := &bytes.Buffer{}
-			err := ingressGet(context.Background(), tt.cfg, out)
+			err := ingressGet(context.Background(), tt.cfg, tt.profile, out)
 			if tt.wantErr != "" {
 				require.NotNil(t, err)
 				require.Equal(t, tt.wantErr, err.Error())
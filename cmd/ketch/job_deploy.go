@@ -20,12 +20,6 @@ const jobDeployHelp = `
 Deploy a job.
 `
 
-const (
-	defaultJobVersion       = "v1"
-	defaultJobParallelism   = 1
-	defaultJobRestartPolicy = "Never"
-)
-
 func newJobDeployCmd(cfg config, out io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "deploy [FILENAME]",
@@ -49,7 +43,9 @@ func jobDeploy(ctx context.Context, cfg config, filename string, out io.Writer)
 	if err != nil {
 		return err
 	}
-	setJobSpecDefaults(&spec)
+	// the mutating webhook applies the same defaults,
+	// setting them here lets CreateOrUpdate detect an unchanged job.
+	spec.SetDefaults()
 	if err = validateJobSpec(&spec); err != nil {
 		return err
 	}
@@ -73,23 +69,6 @@ func jobDeploy(ctx context.Context, cfg config, filename string, out io.Writer)
 	return nil
 }
 
-// setJobSpecDefaults sets defaults on job.Spec for some unset fields
-func setJobSpecDefaults(jobSpec *ketchv1.JobSpec) {
-	jobSpec.Type = "Job"
-	if jobSpec.Version == "" {
-		jobSpec.Version = defaultJobVersion
-	}
-	if jobSpec.Parallelism == 0 {
-		jobSpec.Parallelism = defaultJobParallelism
-	}
-	if jobSpec.Completions == 0 {
-		jobSpec.Completions = jobSpec.Parallelism
-	}
-	if jobSpec.Policy.RestartPolicy == "" {
-		jobSpec.Policy.RestartPolicy = defaultJobRestartPolicy
-	}
-}
-
 // validateJobSpec assures that required fields are populated. Missing fields will throw errors
 // when the custom resource is created, but this is a way to surface errors to user clearly.
 func validateJobSpec(jobSpec *ketchv1.JobSpec) error {
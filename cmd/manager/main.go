@@ -102,6 +102,10 @@ func main() {
 		setupLog.Error(err, "unable to set default templates")
 		os.Exit(1)
 	}
+	if err = storage.Update(templates.IngressConfigMapName(ketchv1.GatewayAPIIngressControllerType.String()), templates.GatewayAPIDefaultTemplates); err != nil {
+		setupLog.Error(err, "unable to set default templates")
+		os.Exit(1)
+	}
 	if err = storage.Update(templates.JobConfigMapName(), templates.JobTemplates); err != nil {
 		setupLog.Error(err, "unable to set default templates")
 		os.Exit(1)
@@ -169,6 +173,10 @@ func main() {
 			setupLog.Error(err, "unable to create webhook", "webhook", "Job")
 			os.Exit(1)
 		}
+		if err = (&ketchv1.App{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "App")
+			os.Exit(1)
+		}
 	}
 	// +kubebuilder:scaffold:builder
 
@@ -0,0 +1,45 @@
+package v1beta1
+
+import "fmt"
+
+// AppProtocol is an application protocol a process speaks on a port.
+type AppProtocol string
+
+const (
+	// HTTPAppProtocol is HTTP/1.1, it is used when a port doesn't set an application protocol.
+	HTTPAppProtocol AppProtocol = "http"
+	// HTTP2AppProtocol is HTTP/2 without TLS (h2c).
+	HTTP2AppProtocol AppProtocol = "http2"
+	// GRPCAppProtocol is gRPC without TLS.
+	GRPCAppProtocol AppProtocol = "grpc"
+	// GRPCSAppProtocol is gRPC over TLS, the certificate of the process is verified unless its port sets InsecureSkipVerify.
+	GRPCSAppProtocol AppProtocol = "grpcs"
+	// WebSocketAppProtocol is HTTP/1.1 with connections upgraded to WebSockets.
+	WebSocketAppProtocol AppProtocol = "websocket"
+)
+
+// Validate returns an error if the protocol is unknown.
+func (p AppProtocol) Validate() error {
+	switch p {
+	case "", HTTPAppProtocol, HTTP2AppProtocol, GRPCAppProtocol, GRPCSAppProtocol, WebSocketAppProtocol:
+		return nil
+	}
+	return fmt.Errorf("unknown application protocol %q", p)
+}
+
+// IsGRPC returns true for gRPC with and without TLS.
+func (p AppProtocol) IsGRPC() bool {
+	return p == GRPCAppProtocol || p == GRPCSAppProtocol
+}
+
+// ServiceAppProtocol returns the appProtocol of a Service port, it is also used as a prefix of a port name, so istio detects the protocol.
+// gRPC over TLS is declared as grpc, istio sends it HTTP/2 and originates TLS with a DestinationRule.
+func (p AppProtocol) ServiceAppProtocol() string {
+	switch p {
+	case HTTP2AppProtocol:
+		return "http2"
+	case GRPCAppProtocol, GRPCSAppProtocol:
+		return "grpc"
+	}
+	return "http"
+}
@@ -0,0 +1,36 @@
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppProtocol(t *testing.T) {
+	tests := []struct {
+		protocol           AppProtocol
+		serviceAppProtocol string
+		isGRPC             bool
+		wantErr            string
+	}{
+		{protocol: "", serviceAppProtocol: "http"},
+		{protocol: HTTPAppProtocol, serviceAppProtocol: "http"},
+		{protocol: HTTP2AppProtocol, serviceAppProtocol: "http2"},
+		{protocol: GRPCAppProtocol, serviceAppProtocol: "grpc", isGRPC: true},
+		{protocol: GRPCSAppProtocol, serviceAppProtocol: "grpc", isGRPC: true},
+		{protocol: WebSocketAppProtocol, serviceAppProtocol: "http"},
+		{protocol: "thrift", wantErr: `unknown application protocol "thrift"`},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.protocol), func(t *testing.T) {
+			err := tt.protocol.Validate()
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.serviceAppProtocol, tt.protocol.ServiceAppProtocol())
+			require.Equal(t, tt.isGRPC, tt.protocol.IsGRPC())
+		})
+	}
+}
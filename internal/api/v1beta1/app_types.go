@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"math"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -28,6 +29,8 @@ import (
 	"github.com/go-logr/logr"
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/tools/record"
 
 	v1 "k8s.io/api/core/v1"
@@ -37,6 +40,7 @@ import (
 const (
 	ShipaCloudDomain     = "shipa.cloud"
 	DefaultNumberOfUnits = 1
+	DefaultAppVersion    = "v1"
 	KetchFinalizer       = "ketch-controller"
 )
 
@@ -70,6 +74,260 @@ type Cname struct {
 	// SecretName if provided must contain an SSL certificate that will be used to serve this cname.
 	// Currently, the secret must be in the app's namespace.
 	SecretName string `json:"secretName,omitempty"`
+	// Path is a path prefix of requests to the cname handled by the application, for example "/orders".
+	// It allows several applications to share a hostname. If omitted, the application handles all requests to the cname.
+	// +optional
+	Path string `json:"path,omitempty"`
+	// StripPath removes the path prefix from requests before they are sent to the application.
+	// With nginx, it turns on the use-regex annotation, which nginx applies to every Ingress of the hostname,
+	// so paths of other applications sharing the cname are matched as case-insensitive regular expressions too.
+	// +optional
+	StripPath bool `json:"stripPath,omitempty"`
+	// Routes send requests to the cname to processes based on a path prefix.
+	// Path prefixes of routes are relative to the Path of the cname.
+	// Requests that don't match any route are sent to the routable process of the application.
+	// +optional
+	Routes []CnameRoute `json:"routes,omitempty"`
+	// Policies of requests to the cname, each policy set here replaces the policy of the app.
+	// +optional
+	Policies *IngressPolicies `json:"policies,omitempty"`
+}
+
+// CnameRoute sends requests with a path prefix to a port of a process.
+type CnameRoute struct {
+	// PathPrefix is a prefix of a request path, for example "/admin". "/" matches all requests.
+	PathPrefix string `json:"pathPrefix"`
+	// Process is a name of a process handling the requests.
+	Process string `json:"process"`
+	// Port is a service port of the process. If omitted, the first port of the process is used.
+	// +optional
+	Port int32 `json:"port,omitempty"`
+}
+
+var pathPrefixRegex = regexp.MustCompile(`^/[A-Za-z0-9._~%/-]*$`)
+
+// String returns the route in the PATH=PROCESS[:PORT] format.
+func (r CnameRoute) String() string {
+	if r.Port > 0 {
+		return fmt.Sprintf("%s=%s:%d", r.PathPrefix, r.Process, r.Port)
+	}
+	return fmt.Sprintf("%s=%s", r.PathPrefix, r.Process)
+}
+
+// Validate returns an error if the path or routes of the cname are invalid.
+func (c Cname) Validate() error {
+	if len(c.Path) > 0 && !pathPrefixRegex.MatchString(c.Path) {
+		return fmt.Errorf("cname %s: invalid path %q, %s", c.Name, c.Path, pathPrefixRequirements)
+	}
+	if c.StripPath && c.NormalizedPath() == "/" {
+		return fmt.Errorf("cname %s: stripPath requires a path", c.Name)
+	}
+	prefixes := make(map[string]struct{}, len(c.Routes))
+	for _, route := range c.Routes {
+		if !pathPrefixRegex.MatchString(route.PathPrefix) {
+			return fmt.Errorf("cname %s: invalid path prefix %q, %s", c.Name, route.PathPrefix, pathPrefixRequirements)
+		}
+		if len(route.Process) == 0 {
+			return fmt.Errorf("cname %s: route %s doesn't have a process", c.Name, route.PathPrefix)
+		}
+		if route.Port < 0 || route.Port > 65535 {
+			return fmt.Errorf("cname %s: route %s has an invalid port %d", c.Name, route.PathPrefix, route.Port)
+		}
+		prefix := normalizePath(route.PathPrefix)
+		if _, ok := prefixes[prefix]; ok {
+			return fmt.Errorf("cname %s: duplicate route %s", c.Name, route.PathPrefix)
+		}
+		prefixes[prefix] = struct{}{}
+	}
+	return nil
+}
+
+const pathPrefixRequirements = "it should start with / and contain only letters, digits and -._~%/ characters"
+
+// NormalizedPath returns the path of the cname without a trailing slash or "/" if the cname doesn't have a path.
+func (c Cname) NormalizedPath() string {
+	return normalizePath(c.Path)
+}
+
+// URL returns a url to access the application with the cname.
+func (c Cname) URL() string {
+	scheme := "http"
+	if c.Secure {
+		scheme = "https"
+	}
+	if path := c.NormalizedPath(); path != "/" {
+		return fmt.Sprintf("%s://%s%s", scheme, c.Name, path)
+	}
+	return fmt.Sprintf("%s://%s", scheme, c.Name)
+}
+
+// OrderedRoutes returns routes of the cname ordered from the longest path prefix,
+// so that a more specific route takes precedence.
+// Path prefixes of the returned routes include the path of the cname.
+// If no route matches all requests of the cname, a route to the given process is added.
+func (c Cname) OrderedRoutes(defaultProcess string) []CnameRoute {
+	path := c.NormalizedPath()
+	routes := make([]CnameRoute, 0, len(c.Routes)+1)
+	hasRootRoute := false
+	for _, route := range c.Routes {
+		prefix := normalizePath(route.PathPrefix)
+		if prefix == "/" {
+			hasRootRoute = true
+		}
+		route.PathPrefix = joinPaths(path, prefix)
+		routes = append(routes, route)
+	}
+	if !hasRootRoute {
+		routes = append(routes, CnameRoute{PathPrefix: path, Process: defaultProcess})
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return len(routes[i].PathPrefix) > len(routes[j].PathPrefix)
+	})
+	return routes
+}
+
+// normalizePath returns the path without a trailing slash, an empty path is normalized to "/".
+func normalizePath(path string) string {
+	if trimmed := strings.TrimRight(path, "/"); len(trimmed) > 0 {
+		return trimmed
+	}
+	return "/"
+}
+
+func joinPaths(base, path string) string {
+	if base == "/" {
+		return path
+	}
+	if path == "/" {
+		return base
+	}
+	return base + path
+}
+
+// CnameConflict returns an error if a cname of the app claims the same hostname and path as a cname of another app.
+func (app *App) CnameConflict(apps []App) error {
+	for _, other := range apps {
+		if other.Name == app.Name {
+			continue
+		}
+		for _, cname := range app.Spec.Ingress.Cnames {
+			for _, otherCname := range other.Spec.Ingress.Cnames {
+				if cname.Name == otherCname.Name && cname.NormalizedPath() == otherCname.NormalizedPath() {
+					return fmt.Errorf("%w: %s%s is used by app %s", ErrCnameConflict, cname.Name, strings.TrimSuffix(cname.NormalizedPath(), "/"), other.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// SetDefaults sets defaults on unset fields of the app's spec.
+// Unset fields of the ingress controller are taken from ingressController when the app's one isn't fully configured.
+func (app *App) SetDefaults(ingressController *IngressControllerSpec) {
+	if app.Spec.Version == nil {
+		version := DefaultAppVersion
+		app.Spec.Version = &version
+	}
+	for i := range app.Spec.Deployments {
+		for j := range app.Spec.Deployments[i].Processes {
+			if app.Spec.Deployments[i].Processes[j].Units == nil {
+				units := DefaultNumberOfUnits
+				app.Spec.Deployments[i].Processes[j].Units = &units
+			}
+		}
+	}
+	if ingressController != nil && !app.Spec.Ingress.Controller.IsConfigured() {
+		app.Spec.Ingress.Controller.SetDefaults(*ingressController)
+	}
+}
+
+// Validate returns an error if the app's spec is inconsistent:
+// weights of deployments don't sum to 100, an active canary has out of range steps,
+// a process, an environment variable or an ingress profile has an invalid name, a cname is invalid or listed twice,
+// or ingress policies are invalid or not supported by the ingress controller.
+func (app *App) Validate() error {
+	if len(app.Spec.Deployments) > 0 {
+		total := 0
+		for _, deployment := range app.Spec.Deployments {
+			total += int(deployment.RoutingSettings.Weight)
+		}
+		if total != 100 {
+			return fmt.Errorf("weights of deployments should sum to 100, got %d", total)
+		}
+	}
+	if err := app.Spec.Canary.Validate(); err != nil {
+		return err
+	}
+	if err := ValidateEnvNames(app.Spec.Env); err != nil {
+		return err
+	}
+	if err := ValidateIngressProfile(app.Spec.Ingress.Controller.Profile); err != nil {
+		return err
+	}
+	for _, deployment := range app.Spec.Deployments {
+		for _, process := range deployment.Processes {
+			if msgs := validation.IsDNS1123Label(process.Name); len(msgs) > 0 {
+				return fmt.Errorf("invalid process name %q: %s", process.Name, strings.Join(msgs, ", "))
+			}
+			if err := ValidateEnvNames(process.Env); err != nil {
+				return fmt.Errorf("process %q: %w", process.Name, err)
+			}
+			if err := process.DisruptionBudget.Validate(); err != nil {
+				return fmt.Errorf("process %q: %w", process.Name, err)
+			}
+			if err := process.Strategy.Validate(); err != nil {
+				return fmt.Errorf("process %q: %w", process.Name, err)
+			}
+			if err := deployment.ProcessExposure(process.Name).Validate(app.Spec.Ingress.Controller.IngressType); err != nil {
+				return fmt.Errorf("process %q: %w", process.Name, err)
+			}
+		}
+		if deployment.KetchYaml == nil || deployment.KetchYaml.Kubernetes == nil {
+			continue
+		}
+		for name, processConfig := range deployment.KetchYaml.Kubernetes.Processes {
+			for _, port := range processConfig.Ports {
+				if err := port.Validate(); err != nil {
+					return fmt.Errorf("process %q: %w", name, err)
+				}
+			}
+		}
+	}
+	ingressType := app.Spec.Ingress.Controller.IngressType
+	if err := app.Spec.Ingress.Policies.Validate(ingressType, "/"); err != nil {
+		return fmt.Errorf("ingress policies: %w", err)
+	}
+	if err := app.Spec.Ingress.ValidateUpstreamReadTimeout(); err != nil {
+		return err
+	}
+	if err := app.Spec.Ingress.MeshPolicy.Validate(ingressType); err != nil {
+		return err
+	}
+	urls := make(map[string]struct{}, len(app.Spec.Ingress.Cnames))
+	for _, cname := range app.Spec.Ingress.Cnames {
+		if err := cname.Validate(); err != nil {
+			return err
+		}
+		if err := app.Spec.Ingress.Policies.Merge(cname.Policies).Validate(ingressType, cname.NormalizedPath()); err != nil {
+			return fmt.Errorf("cname %s: %w", cname.Name, err)
+		}
+		url := cname.Name + cname.NormalizedPath()
+		if _, ok := urls[url]; ok {
+			return fmt.Errorf("cname %s is listed more than once", strings.TrimSuffix(url, "/"))
+		}
+		urls[url] = struct{}{}
+	}
+	return nil
+}
+
+// ValidateEnvNames returns an error if a name of the environment variables can't be used in a container.
+func ValidateEnvNames(envs []Env) error {
+	for _, env := range envs {
+		if msgs := validation.IsEnvVarName(env.Name); len(msgs) > 0 {
+			return fmt.Errorf("invalid environment variable name %q: %s", env.Name, strings.Join(msgs, ", "))
+		}
+	}
+	return nil
 }
 
 // RoutingSettings contains a weight of the current deployment used to route incoming traffic.
@@ -101,6 +359,214 @@ type ProcessSpec struct {
 	VolumeMounts []v1.VolumeMount `json:"volumeMounts,omitempty"`
 	// Security options the process should run with.
 	SecurityContext *v1.SecurityContext `json:"securityContext,omitempty"`
+
+	// Scheduling contains scheduling options of the process.
+	// Options that are not set here are taken from the app-level scheduling options.
+	Scheduling *SchedulingSpec `json:"scheduling,omitempty"`
+
+	// DisruptionBudget configures a PodDisruptionBudget of the process.
+	// If not set, the process gets a PodDisruptionBudget with maxUnavailable=1 when it runs more than one unit.
+	DisruptionBudget *DisruptionBudgetSpec `json:"disruptionBudget,omitempty"`
+
+	// Strategy configures how pods of the process are replaced during a rollout.
+	// If not set, the strategy from ketch.yaml is used, otherwise the kubernetes defaults apply.
+	Strategy *RolloutStrategy `json:"strategy,omitempty"`
+
+	// InitContainers is a list of containers that run to completion before the app container is started.
+	InitContainers []ContainerSpec `json:"initContainers,omitempty"`
+
+	// Sidecars is a list of containers that run alongside the app container, like log shippers or proxies.
+	Sidecars []ContainerSpec `json:"sidecars,omitempty"`
+
+	// Exposure configures how the process is made reachable.
+	// If not set, the exposure from ketch.yaml is used, otherwise the process gets a ClusterIP Service.
+	Exposure *ExposureSpec `json:"exposure,omitempty"`
+}
+
+// ContainerSpec describes an additional container of a process's pods.
+type ContainerSpec struct {
+	// +kubebuilder:validation:MinLength=1
+	// Name of the container, it must be unique within a pod.
+	Name string `json:"name"`
+
+	// +kubebuilder:validation:MinLength=1
+	// Image of the container.
+	Image string `json:"image"`
+
+	// Command is an entrypoint of the container. The image's entrypoint is used if not provided.
+	Command []string `json:"command,omitempty"`
+
+	// Args are arguments to the entrypoint.
+	Args []string `json:"args,omitempty"`
+
+	// Env is a list of environment variables to set in the container.
+	Env []Env `json:"env,omitempty"`
+
+	// VolumeMounts is a list of the process's volumes to mount into the container.
+	VolumeMounts []v1.VolumeMount `json:"volumeMounts,omitempty"`
+
+	// Resources are compute resource requests and limits of the container.
+	// They aren't inherited from the process, the container has no requests and limits if not provided.
+	Resources *v1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// RolloutStrategyType is a type of a rollout strategy.
+type RolloutStrategyType string
+
+const (
+	// RollingUpdateStrategy replaces old pods with new ones gradually.
+	RollingUpdateStrategy RolloutStrategyType = "RollingUpdate"
+	// RecreateStrategy kills all old pods before new ones are created.
+	RecreateStrategy RolloutStrategyType = "Recreate"
+)
+
+// RolloutStrategy configures how pods of a process are replaced during a rollout.
+// Type, maxSurge, maxUnavailable and progressDeadlineSeconds are only applied to Deployment apps.
+type RolloutStrategy struct {
+	// Type is either RollingUpdate or Recreate. Defaults to RollingUpdate.
+	// +kubebuilder:validation:Enum=RollingUpdate;Recreate
+	Type RolloutStrategyType `json:"type,omitempty"`
+
+	// MaxSurge is a number or a percentage of pods that can be created above the desired number of units.
+	MaxSurge *intstr.IntOrString `json:"maxSurge,omitempty"`
+
+	// MaxUnavailable is a number or a percentage of pods that can be unavailable during a rollout.
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// MinReadySeconds is a number of seconds a new pod should be ready to be considered available.
+	MinReadySeconds *int32 `json:"minReadySeconds,omitempty"`
+
+	// ProgressDeadlineSeconds is a number of seconds a rollout can take to make progress before it is considered failed.
+	// Ketch waits this long for a rollout to finish before reporting a timeout.
+	ProgressDeadlineSeconds *int32 `json:"progressDeadlineSeconds,omitempty"`
+}
+
+// Validate returns an error if the strategy can't be applied to a Deployment.
+func (s *RolloutStrategy) Validate() error {
+	if s == nil {
+		return nil
+	}
+	switch s.Type {
+	case "", RollingUpdateStrategy:
+	case RecreateStrategy:
+		if s.MaxSurge != nil || s.MaxUnavailable != nil {
+			return errors.New("maxSurge and maxUnavailable can't be used with the Recreate strategy")
+		}
+	default:
+		return fmt.Errorf("unknown strategy type %q", s.Type)
+	}
+	surge, err := scaledValue("maxSurge", s.MaxSurge)
+	if err != nil {
+		return err
+	}
+	unavailable, err := scaledValue("maxUnavailable", s.MaxUnavailable)
+	if err != nil {
+		return err
+	}
+	if s.MaxSurge != nil && s.MaxUnavailable != nil && surge == 0 && unavailable == 0 {
+		return errors.New("maxSurge and maxUnavailable can't both be 0")
+	}
+	if s.MinReadySeconds != nil && *s.MinReadySeconds < 0 {
+		return errors.New("minReadySeconds must not be negative")
+	}
+	if s.ProgressDeadlineSeconds != nil {
+		if *s.ProgressDeadlineSeconds <= 0 {
+			return errors.New("progressDeadlineSeconds must be positive")
+		}
+		if s.MinReadySeconds != nil && *s.ProgressDeadlineSeconds <= *s.MinReadySeconds {
+			return errors.New("progressDeadlineSeconds must be greater than minReadySeconds")
+		}
+	}
+	return nil
+}
+
+func scaledValue(name string, value *intstr.IntOrString) (int, error) {
+	if value == nil {
+		return 0, nil
+	}
+	scaled, err := intstr.GetScaledValueFromIntOrPercent(value, 100, true)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if scaled < 0 {
+		return 0, fmt.Errorf("%s must not be negative", name)
+	}
+	return scaled, nil
+}
+
+// DisruptionBudgetSpec configures a PodDisruptionBudget covering pods of all deployment versions of a process.
+type DisruptionBudgetSpec struct {
+	// MinAvailable is a number or a percentage of pods that must remain available during a voluntary disruption.
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// MaxUnavailable is a number or a percentage of pods that can be unavailable during a voluntary disruption.
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+
+	// Disabled turns off the PodDisruptionBudget of the process.
+	Disabled bool `json:"disabled,omitempty"`
+}
+
+// Validate returns an error if both minAvailable and maxUnavailable are set or any of them is negative.
+func (s *DisruptionBudgetSpec) Validate() error {
+	if s == nil {
+		return nil
+	}
+	if s.MinAvailable != nil && s.MaxUnavailable != nil {
+		return errors.New("only one of minAvailable and maxUnavailable can be set")
+	}
+	if _, err := scaledValue("minAvailable", s.MinAvailable); err != nil {
+		return err
+	}
+	if _, err := scaledValue("maxUnavailable", s.MaxUnavailable); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SchedulingSpec contains options that control on which nodes and with which priority pods of a process are scheduled.
+type SchedulingSpec struct {
+	// NodeSelectorTerms is a list of node selector terms, pods are scheduled on nodes matching any of them.
+	NodeSelectorTerms []v1.NodeSelectorTerm `json:"nodeSelectorTerms,omitempty"`
+
+	// Tolerations allow pods to be scheduled on nodes with matching taints.
+	Tolerations []v1.Toleration `json:"tolerations,omitempty"`
+
+	// PodAntiAffinity describes rules to keep pods away from other pods.
+	PodAntiAffinity *v1.PodAntiAffinity `json:"podAntiAffinity,omitempty"`
+
+	// TopologySpreadConstraints describes how pods are spread across topology domains like zones.
+	// If a constraint has no label selector, it selects the pods of the same process and deployment version.
+	TopologySpreadConstraints []v1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// PriorityClassName is the name of a PriorityClass of the pods.
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+}
+
+// WithDefaults returns scheduling options where the options that are not set are taken from defaults.
+func (s *SchedulingSpec) WithDefaults(defaults *SchedulingSpec) *SchedulingSpec {
+	if s == nil {
+		return defaults
+	}
+	if defaults == nil {
+		return s
+	}
+	result := *s
+	if len(result.NodeSelectorTerms) == 0 {
+		result.NodeSelectorTerms = defaults.NodeSelectorTerms
+	}
+	if len(result.Tolerations) == 0 {
+		result.Tolerations = defaults.Tolerations
+	}
+	if result.PodAntiAffinity == nil {
+		result.PodAntiAffinity = defaults.PodAntiAffinity
+	}
+	if len(result.TopologySpreadConstraints) == 0 {
+		result.TopologySpreadConstraints = defaults.TopologySpreadConstraints
+	}
+	if len(result.PriorityClassName) == 0 {
+		result.PriorityClassName = defaults.PriorityClassName
+	}
+	return &result
 }
 
 type DeploymentVersion int
@@ -125,7 +591,8 @@ type AppDeploymentSpec struct {
 // IngressSpec configures entrypoints to access an application.
 type IngressSpec struct {
 
-	// GenerateDefaultCname if set the application will have a default cname <app-name>.<ServiceEndpoint>.shipa.cloud.
+	// GenerateDefaultCname if set the application will have a default cname rendered from DefaultCnameTemplate of the ingress controller,
+	// <app-name>.<ServiceEndpoint>.shipa.cloud by default.
 	GenerateDefaultCname bool `json:"generateDefaultCname"`
 
 	// Cnames is a list of additional cnames.
@@ -133,6 +600,36 @@ type IngressSpec struct {
 
 	// Controller is the ingress controller the app is using
 	Controller IngressControllerSpec `json:"controller,omitempty"`
+
+	// Policies of requests to the application, applied to all its cnames.
+	// +optional
+	Policies *IngressPolicies `json:"policies,omitempty"`
+	// UpstreamReadTimeoutSeconds is how long the ingress controller waits for data from the application,
+	// set it to keep long-lived WebSocket connections and gRPC streams open.
+	// nginx uses it as a read timeout, istio as an idle timeout of connections to the application.
+	// traefik has no read timeout, it only limits the time to the first byte of a response,
+	// so it doesn't change how long traefik keeps streams open.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	UpstreamReadTimeoutSeconds int32 `json:"upstreamReadTimeoutSeconds,omitempty"`
+	// MeshPolicy configures retries, timeouts, circuit breaking and fault injection of requests to the application.
+	// Only the istio ingress controller supports it.
+	// +optional
+	MeshPolicy *MeshPolicy `json:"meshPolicy,omitempty"`
+}
+
+// ValidateUpstreamReadTimeout returns an error if the upstream read timeout is invalid or not supported by the ingress controller.
+func (s IngressSpec) ValidateUpstreamReadTimeout() error {
+	if s.UpstreamReadTimeoutSeconds == 0 {
+		return nil
+	}
+	if s.UpstreamReadTimeoutSeconds < 0 {
+		return fmt.Errorf("invalid upstream read timeout %d", s.UpstreamReadTimeoutSeconds)
+	}
+	if s.Controller.IngressType == GatewayAPIIngressControllerType {
+		return fmt.Errorf("upstream read timeout is not supported by the %s ingress controller", GatewayAPIIngressControllerType)
+	}
+	return nil
 }
 
 // DockerRegistrySpec contains docker registry configuration of an application.
@@ -164,8 +661,45 @@ type AppStatus struct {
 	// +optional
 	// +kubebuilder:pruning:PreserveUnknownFields
 	ExtensionsStatuses []runtime.RawExtension `json:"extensionsStatuses,omitempty"`
+	// Cnames holds the state of certificates of secure cnames issued by cert-manager.
+	// +optional
+	Cnames []CnameStatus `json:"cnames,omitempty"`
+}
+
+// CnameStatus represents the state of a cert-manager certificate of a secure cname.
+type CnameStatus struct {
+	// Name is the cname.
+	Name string `json:"name"`
+	// SecretName is the name of the secret the certificate is stored in.
+	SecretName string `json:"secretName,omitempty"`
+	// CertificateReady shows if the certificate has been issued and is up to date.
+	CertificateReady bool `json:"certificateReady"`
+	// CertificateExpires is the expiration time of the issued certificate.
+	// +optional
+	CertificateExpires *metav1.Time `json:"certificateExpires,omitempty"`
+	// LastError describes why the certificate is not ready.
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+}
+
+// CnameStatus returns the status of a cname or nil if the status is not known.
+func (s AppStatus) CnameStatus(name string) *CnameStatus {
+	for i := range s.Cnames {
+		if s.Cnames[i].Name == name {
+			return &s.Cnames[i]
+		}
+	}
+	return nil
 }
 
+// Limits of the number of steps of a canary deployment.
+const (
+	// MinCanarySteps is the minimum number of steps of a canary deployment.
+	MinCanarySteps = 2
+	// MaxCanarySteps is the maximum number of steps of a canary deployment.
+	MaxCanarySteps = 100
+)
+
 // CanarySpec represents configuration for a canary deployment.
 type CanarySpec struct {
 	// +kubebuilder:validation:Minimum=0
@@ -189,6 +723,23 @@ type CanarySpec struct {
 	Target map[string]uint16 `json:"target,omitempty"`
 }
 
+// Validate returns an error if an active canary has steps, a step weight or a current step out of range.
+func (c CanarySpec) Validate() error {
+	if !c.Active {
+		return nil
+	}
+	if c.Steps < MinCanarySteps || c.Steps > MaxCanarySteps {
+		return fmt.Errorf("canary steps must be between %d and %d, got %d", MinCanarySteps, MaxCanarySteps, c.Steps)
+	}
+	if c.StepWeight == 0 || c.StepWeight > 100 {
+		return fmt.Errorf("canary step weight must be between 1 and 100, got %d", c.StepWeight)
+	}
+	if c.CurrentStep < 1 || c.CurrentStep > c.Steps {
+		return fmt.Errorf("canary current step must be between 1 and %d, got %d", c.Steps, c.CurrentStep)
+	}
+	return nil
+}
+
 // AppSpec defines the desired state of App.
 type AppSpec struct {
 	Version *string `json:"version,omitempty"`
@@ -242,6 +793,9 @@ type AppSpec struct {
 	// SecurityContext specifies security settings for a pod/app, which get applied to all containers.
 	SecurityContext *v1.PodSecurityContext `json:"securityContext,omitempty"`
 
+	// Scheduling contains default scheduling options for all processes of the app.
+	Scheduling *SchedulingSpec `json:"scheduling,omitempty"`
+
 	// Extensions can be used by third-parties to keep additional information.
 	// +optional
 	// +kubebuilder:pruning:PreserveUnknownFields
@@ -328,6 +882,34 @@ func (s *AppDeploymentSpec) setUnitsForAllProcess(units int) {
 	}
 }
 
+// RolloutStrategy returns the rollout strategy of the process.
+// The strategy set on ProcessSpec takes precedence over the one from ketch.yaml.
+func (s AppDeploymentSpec) RolloutStrategy(process string) *RolloutStrategy {
+	for _, processSpec := range s.Processes {
+		if processSpec.Name == process && processSpec.Strategy != nil {
+			return processSpec.Strategy
+		}
+	}
+	if s.KetchYaml == nil || s.KetchYaml.Kubernetes == nil {
+		return nil
+	}
+	return s.KetchYaml.Kubernetes.Processes[process].Strategy
+}
+
+// ProcessExposure returns the exposure of the process.
+// The exposure set on ProcessSpec takes precedence over the one from ketch.yaml.
+func (s AppDeploymentSpec) ProcessExposure(process string) *ExposureSpec {
+	for _, processSpec := range s.Processes {
+		if processSpec.Name == process && processSpec.Exposure != nil {
+			return processSpec.Exposure
+		}
+	}
+	if s.KetchYaml == nil || s.KetchYaml.Kubernetes == nil {
+		return nil
+	}
+	return s.KetchYaml.Kubernetes.Processes[process].Exposure
+}
+
 // SetUnits set quantity of units of the specified processes.
 func (app *App) SetUnits(selector Selector, units int) error {
 	deploymentFound := false
@@ -353,12 +935,72 @@ func (app *App) SetUnits(selector Selector, units int) error {
 // SetEnvs extends the current list of environment variables with the provided list.
 // If the current list has an env variable from the provided list, the env variable will be updated with a new value.
 func (app *App) SetEnvs(envs []Env) {
+	app.Spec.Env = setEnvs(app.Spec.Env, envs)
+}
+
+// Envs returns values of the asked env variables.
+func (app *App) Envs(names []string) map[string]string {
+	return getEnvs(app.Spec.Env, names)
+}
+
+// UnsetEnvs unsets environment values.
+func (app *App) UnsetEnvs(envs []string) {
+	app.Spec.Env = unsetEnvs(app.Spec.Env, envs)
+}
+
+// SetProcessEnvs extends the list of environment variables of the given process in every deployment of the app.
+// It returns ErrProcessNotFound if no deployment has such a process.
+func (app *App) SetProcessEnvs(process string, envs []Env) error {
+	return app.updateProcessEnvs(process, func(current []Env) []Env {
+		return setEnvs(current, envs)
+	})
+}
+
+// UnsetProcessEnvs unsets environment values of the given process in every deployment of the app.
+// It returns ErrProcessNotFound if no deployment has such a process.
+func (app *App) UnsetProcessEnvs(process string, envs []string) error {
+	return app.updateProcessEnvs(process, func(current []Env) []Env {
+		return unsetEnvs(current, envs)
+	})
+}
+
+// ProcessEnvs returns values of the asked env variables of the given process.
+// The values are taken from the most recent deployment running the process.
+func (app *App) ProcessEnvs(process string, names []string) (map[string]string, error) {
+	for i := len(app.Spec.Deployments) - 1; i >= 0; i-- {
+		for _, processSpec := range app.Spec.Deployments[i].Processes {
+			if processSpec.Name == process {
+				return getEnvs(processSpec.Env, names), nil
+			}
+		}
+	}
+	return nil, ErrProcessNotFound
+}
+
+func (app *App) updateProcessEnvs(process string, update func(current []Env) []Env) error {
+	processFound := false
+	for _, deploymentSpec := range app.Spec.Deployments {
+		for i, processSpec := range deploymentSpec.Processes {
+			if processSpec.Name != process {
+				continue
+			}
+			deploymentSpec.Processes[i].Env = update(processSpec.Env)
+			processFound = true
+		}
+	}
+	if !processFound {
+		return ErrProcessNotFound
+	}
+	return nil
+}
+
+func setEnvs(current []Env, envs []Env) []Env {
 	names := make(map[string]Env, len(envs))
 	for _, env := range envs {
 		names[env.Name] = env
 	}
 	newEnvs := make([]Env, 0, len(envs))
-	for _, env := range app.Spec.Env {
+	for _, env := range current {
 		if newEnv, hasNewValue := names[env.Name]; hasNewValue {
 			newEnvs = append(newEnvs, newEnv)
 			delete(names, env.Name)
@@ -366,21 +1008,24 @@ func (app *App) SetEnvs(envs []Env) {
 		}
 		newEnvs = append(newEnvs, env)
 	}
-	for _, env := range names {
-		newEnvs = append(newEnvs, env)
+	// keep the order of the provided list for the new variables.
+	for _, env := range envs {
+		if newEnv, ok := names[env.Name]; ok {
+			newEnvs = append(newEnvs, newEnv)
+			delete(names, env.Name)
+		}
 	}
-	app.Spec.Env = newEnvs
+	return newEnvs
 }
 
-// Envs returns values of the asked env variables.
-func (app *App) Envs(names []string) map[string]string {
+func getEnvs(current []Env, names []string) map[string]string {
 	namesMap := make(map[string]struct{}, len(names))
 	for _, name := range names {
 		namesMap[name] = struct{}{}
 	}
 
 	envs := make(map[string]string)
-	for _, env := range app.Spec.Env {
+	for _, env := range current {
 		if len(names) == 0 {
 			envs[env.Name] = env.Value
 			continue
@@ -392,19 +1037,18 @@ func (app *App) Envs(names []string) map[string]string {
 	return envs
 }
 
-// UnsetEnvs unsets environment values.
-func (app *App) UnsetEnvs(envs []string) {
+func unsetEnvs(current []Env, envs []string) []Env {
 	names := make(map[string]struct{}, len(envs))
 	for _, name := range envs {
 		names[name] = struct{}{}
 	}
 	var newEnvs []Env
-	for _, env := range app.Spec.Env {
+	for _, env := range current {
 		if _, remove := names[env.Name]; !remove {
 			newEnvs = append(newEnvs, env)
 		}
 	}
-	app.Spec.Env = newEnvs
+	return newEnvs
 }
 
 // Stop stops processes specified by the selector.
@@ -448,33 +1092,43 @@ func (app *App) Start(selector Selector) error {
 // CNames returns all CNAMEs to access the application including a default cname.
 func (app *App) CNames() []string {
 	cnames := []string{}
-	defaultCname := app.DefaultCname()
-	if defaultCname != nil {
-		cnames = append(cnames, fmt.Sprintf("http://%s", *defaultCname))
+	if url := app.DefaultCnameURL(); len(url) > 0 {
+		cnames = append(cnames, url)
 	}
 	for _, cname := range app.Spec.Ingress.Cnames {
-		scheme := "http"
-		if cname.Secure {
-			scheme = "https"
-		}
-		cnames = append(cnames, fmt.Sprintf("%s://%s", scheme, cname.Name))
+		cnames = append(cnames, cname.URL())
 	}
 	return cnames
 }
 
 // DefaultCname returns a default cname to access the application.
-// A default cname uses the following format: <app name>.<App's Ingress ServiceEndpoint>.shipa.cloud.
+// A default cname is rendered from DefaultCnameTemplate of the app's ingress controller,
+// and uses the <app name>.<App's Ingress ServiceEndpoint>.shipa.cloud format if the template is not set.
+// It returns nil if the template doesn't render a valid cname, for example, when the ingress controller has no ServiceEndpoint yet.
 func (app *App) DefaultCname() *string {
 	if !app.Spec.Ingress.GenerateDefaultCname {
 		return nil
 	}
-	if len(app.Spec.Ingress.Controller.ServiceEndpoint) == 0 {
+	url, err := app.Spec.Ingress.Controller.DefaultCname(app.Name, app.Spec.Namespace)
+	if err != nil {
 		return nil
 	}
-	url := fmt.Sprintf("%s.%s.%s", app.Name, app.Spec.Ingress.Controller.ServiceEndpoint, ShipaCloudDomain)
 	return &url
 }
 
+// DefaultCnameURL returns a URL of the default cname or an empty string if the app has no default cname.
+// The default cname is served over https if the ingress controller has a certificate for default cnames.
+func (app *App) DefaultCnameURL() string {
+	defaultCname := app.DefaultCname()
+	if defaultCname == nil {
+		return ""
+	}
+	if len(app.Spec.Ingress.Controller.DefaultCnameSecretName) > 0 {
+		return "https://" + *defaultCname
+	}
+	return "http://" + *defaultCname
+}
+
 // Units returns a total number units.
 func (app *App) Units() int {
 	units := 0
@@ -13,6 +13,7 @@ import (
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/tools/record"
 )
 
@@ -357,6 +358,56 @@ func TestApp_UnsetEnvs(t *testing.T) {
 	}
 }
 
+func TestApp_ProcessEnvs(t *testing.T) {
+	app := &App{
+		Spec: AppSpec{
+			Env: []Env{{Name: "KETCH", Value: "true"}},
+			Deployments: []AppDeploymentSpec{
+				{
+					Version: 1,
+					Processes: []ProcessSpec{
+						{Name: "web", Env: []Env{{Name: "PORT", Value: "8080"}}},
+						{Name: "worker"},
+					},
+				},
+				{
+					Version: 2,
+					Processes: []ProcessSpec{
+						{Name: "web", Env: []Env{{Name: "PORT", Value: "8080"}}},
+					},
+				},
+			},
+		},
+	}
+
+	require.Nil(t, app.SetProcessEnvs("web", []Env{{Name: "PORT", Value: "9090"}, {Name: "DEBUG", Value: "1"}}))
+	for _, deployment := range app.Spec.Deployments {
+		require.Equal(t, []Env{{Name: "PORT", Value: "9090"}, {Name: "DEBUG", Value: "1"}}, deployment.Processes[0].Env)
+	}
+	require.Equal(t, []Env{{Name: "KETCH", Value: "true"}}, app.Spec.Env)
+
+	envs, err := app.ProcessEnvs("web", nil)
+	require.Nil(t, err)
+	require.Equal(t, map[string]string{"PORT": "9090", "DEBUG": "1"}, envs)
+
+	envs, err = app.ProcessEnvs("web", []string{"DEBUG", "KETCH"})
+	require.Nil(t, err)
+	require.Equal(t, map[string]string{"DEBUG": "1"}, envs)
+
+	require.Nil(t, app.UnsetProcessEnvs("web", []string{"PORT"}))
+	envs, err = app.ProcessEnvs("web", nil)
+	require.Nil(t, err)
+	require.Equal(t, map[string]string{"DEBUG": "1"}, envs)
+
+	require.Nil(t, app.SetProcessEnvs("worker", []Env{{Name: "QUEUE", Value: "jobs"}}))
+	require.Equal(t, []Env{{Name: "QUEUE", Value: "jobs"}}, app.Spec.Deployments[0].Processes[1].Env)
+
+	require.Equal(t, ErrProcessNotFound, app.SetProcessEnvs("cron", []Env{{Name: "QUEUE", Value: "jobs"}}))
+	require.Equal(t, ErrProcessNotFound, app.UnsetProcessEnvs("cron", []string{"QUEUE"}))
+	_, err = app.ProcessEnvs("cron", nil)
+	require.Equal(t, ErrProcessNotFound, err)
+}
+
 func TestApp_DefaultCname(t *testing.T) {
 	tests := []struct {
 		name                 string
@@ -383,6 +434,26 @@ func TestApp_DefaultCname(t *testing.T) {
 			generateDefaultCname: false,
 			ingressController:    IngressControllerSpec{ServiceEndpoint: "20.20.20.20"},
 		},
+		{
+			name:                 "cname from a template",
+			appName:              "app-3",
+			generateDefaultCname: true,
+			ingressController:    IngressControllerSpec{DefaultCnameTemplate: "{{.App}}.{{.Namespace}}.apps.example.com"},
+			want:                 stringRef("app-3.app-namespace.apps.example.com"),
+		},
+		{
+			name:                 "cname from a template with service endpoint",
+			appName:              "app-3",
+			generateDefaultCname: true,
+			ingressController:    IngressControllerSpec{ServiceEndpoint: "20.20.20.20", DefaultCnameTemplate: "{{.App}}.{{.ServiceEndpoint}}.nip.io"},
+			want:                 stringRef("app-3.20.20.20.20.nip.io"),
+		},
+		{
+			name:                 "template with unknown field - no default cname",
+			appName:              "app-3",
+			generateDefaultCname: true,
+			ingressController:    IngressControllerSpec{DefaultCnameTemplate: "{{.Name}}.apps.example.com"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -391,6 +462,7 @@ func TestApp_DefaultCname(t *testing.T) {
 					Name: tt.appName,
 				},
 				Spec: AppSpec{
+					Namespace: "app-namespace",
 					Ingress: IngressSpec{
 						GenerateDefaultCname: tt.generateDefaultCname,
 						Controller:           tt.ingressController,
@@ -446,6 +518,13 @@ func TestApp_CNames(t *testing.T) {
 			ingressController:    IngressControllerSpec{ServiceEndpoint: "10.20.30.40"},
 			want:                 []string{"http://ketch.10.20.30.40.shipa.cloud"},
 		},
+		{
+			name:                 "secure default cname",
+			generateDefaultCname: true,
+			ingressController:    IngressControllerSpec{DefaultCnameTemplate: "{{.App}}.apps.example.com", DefaultCnameSecretName: "apps-tls"},
+			cnames:               []Cname{{Name: "theketch.io"}},
+			want:                 []string{"https://ketch.apps.example.com", "http://theketch.io"},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -1626,3 +1705,585 @@ func TestAppType(t *testing.T) {
 		})
 	}
 }
+
+func TestSchedulingSpec_WithDefaults(t *testing.T) {
+	defaults := &SchedulingSpec{
+		Tolerations:       []v1.Toleration{{Key: "spot", Operator: v1.TolerationOpExists}},
+		PriorityClassName: "low",
+	}
+	tests := []struct {
+		name       string
+		scheduling *SchedulingSpec
+		defaults   *SchedulingSpec
+		want       *SchedulingSpec
+	}{
+		{
+			name:     "no process-level options",
+			defaults: defaults,
+			want:     defaults,
+		},
+		{
+			name:       "no defaults",
+			scheduling: &SchedulingSpec{PriorityClassName: "high"},
+			want:       &SchedulingSpec{PriorityClassName: "high"},
+		},
+		{
+			name:       "process-level options override defaults",
+			scheduling: &SchedulingSpec{PriorityClassName: "high"},
+			defaults:   defaults,
+			want: &SchedulingSpec{
+				Tolerations:       []v1.Toleration{{Key: "spot", Operator: v1.TolerationOpExists}},
+				PriorityClassName: "high",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.scheduling.WithDefaults(tt.defaults))
+		})
+	}
+}
+
+func TestDisruptionBudgetSpec_Validate(t *testing.T) {
+	one := intstr.FromInt(1)
+	negative := intstr.FromInt(-1)
+	half := intstr.FromString("50%")
+	invalid := intstr.FromString("half")
+	tests := []struct {
+		name    string
+		budget  *DisruptionBudgetSpec
+		wantErr string
+	}{
+		{
+			name: "nil budget",
+		},
+		{
+			name:   "maxUnavailable",
+			budget: &DisruptionBudgetSpec{MaxUnavailable: &one},
+		},
+		{
+			name:   "percentage",
+			budget: &DisruptionBudgetSpec{MinAvailable: &half},
+		},
+		{
+			name:    "both set",
+			budget:  &DisruptionBudgetSpec{MinAvailable: &half, MaxUnavailable: &one},
+			wantErr: "only one of minAvailable and maxUnavailable can be set",
+		},
+		{
+			name:    "negative",
+			budget:  &DisruptionBudgetSpec{MaxUnavailable: &negative},
+			wantErr: "maxUnavailable must not be negative",
+		},
+		{
+			name:    "invalid percentage",
+			budget:  &DisruptionBudgetSpec{MinAvailable: &invalid},
+			wantErr: `invalid minAvailable: invalid value for IntOrString: invalid type: string is not a percentage`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.budget.Validate()
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+		})
+	}
+}
+
+func TestAppDeploymentSpec_RolloutStrategy(t *testing.T) {
+	recreate := &RolloutStrategy{Type: RecreateStrategy}
+	rollingUpdate := &RolloutStrategy{Type: RollingUpdateStrategy}
+	deployment := AppDeploymentSpec{
+		Processes: []ProcessSpec{
+			{Name: "web", Strategy: rollingUpdate},
+			{Name: "worker"},
+			{Name: "cron"},
+		},
+		KetchYaml: &KetchYamlData{
+			Kubernetes: &KetchYamlKubernetesConfig{
+				Processes: map[string]KetchYamlProcessConfig{
+					"web":    {Strategy: recreate},
+					"worker": {Strategy: recreate},
+				},
+			},
+		},
+	}
+	require.Equal(t, rollingUpdate, deployment.RolloutStrategy("web"))
+	require.Equal(t, recreate, deployment.RolloutStrategy("worker"))
+	require.Nil(t, deployment.RolloutStrategy("cron"))
+	require.Nil(t, AppDeploymentSpec{}.RolloutStrategy("web"))
+}
+
+func TestCname_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cname   Cname
+		wantErr string
+	}{
+		{
+			name:  "no routes",
+			cname: Cname{Name: "theketch.io"},
+		},
+		{
+			name: "valid routes",
+			cname: Cname{Name: "theketch.io", Routes: []CnameRoute{
+				{PathPrefix: "/", Process: "web"},
+				{PathPrefix: "/admin", Process: "admin", Port: 9090},
+			}},
+		},
+		{
+			name:  "path with strip",
+			cname: Cname{Name: "theketch.io", Path: "/orders", StripPath: true},
+		},
+		{
+			name:    "invalid path",
+			cname:   Cname{Name: "theketch.io", Path: "orders"},
+			wantErr: `cname theketch.io: invalid path "orders", it should start with / and contain only letters, digits and -._~%/ characters`,
+		},
+		{
+			name:    "strip without path",
+			cname:   Cname{Name: "theketch.io", Path: "/", StripPath: true},
+			wantErr: "cname theketch.io: stripPath requires a path",
+		},
+		{
+			name:    "path prefix without a leading slash",
+			cname:   Cname{Name: "theketch.io", Routes: []CnameRoute{{PathPrefix: "admin", Process: "admin"}}},
+			wantErr: `cname theketch.io: invalid path prefix "admin", it should start with / and contain only letters, digits and -._~%/ characters`,
+		},
+		{
+			name:    "missing process",
+			cname:   Cname{Name: "theketch.io", Routes: []CnameRoute{{PathPrefix: "/admin"}}},
+			wantErr: "cname theketch.io: route /admin doesn't have a process",
+		},
+		{
+			name:    "invalid port",
+			cname:   Cname{Name: "theketch.io", Routes: []CnameRoute{{PathPrefix: "/admin", Process: "admin", Port: 70000}}},
+			wantErr: "cname theketch.io: route /admin has an invalid port 70000",
+		},
+		{
+			name: "duplicate routes",
+			cname: Cname{Name: "theketch.io", Routes: []CnameRoute{
+				{PathPrefix: "/admin/", Process: "admin"},
+				{PathPrefix: "/admin", Process: "web"},
+			}},
+			wantErr: "cname theketch.io: duplicate route /admin",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cname.Validate()
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+		})
+	}
+}
+
+func TestCname_OrderedRoutes(t *testing.T) {
+	tests := []struct {
+		name  string
+		cname Cname
+		want  []CnameRoute
+	}{
+		{
+			name:  "no routes",
+			cname: Cname{Name: "theketch.io"},
+			want:  []CnameRoute{{PathPrefix: "/", Process: "web"}},
+		},
+		{
+			name: "longest prefix goes first",
+			cname: Cname{Name: "theketch.io", Routes: []CnameRoute{
+				{PathPrefix: "/api/", Process: "api"},
+				{PathPrefix: "/api/admin", Process: "admin", Port: 9090},
+			}},
+			want: []CnameRoute{
+				{PathPrefix: "/api/admin", Process: "admin", Port: 9090},
+				{PathPrefix: "/api", Process: "api"},
+				{PathPrefix: "/", Process: "web"},
+			},
+		},
+		{
+			name: "root route",
+			cname: Cname{Name: "theketch.io", Routes: []CnameRoute{
+				{PathPrefix: "/", Process: "admin"},
+				{PathPrefix: "/api", Process: "api"},
+			}},
+			want: []CnameRoute{
+				{PathPrefix: "/api", Process: "api"},
+				{PathPrefix: "/", Process: "admin"},
+			},
+		},
+		{
+			name: "routes are relative to the path",
+			cname: Cname{Name: "theketch.io", Path: "/shop/", Routes: []CnameRoute{
+				{PathPrefix: "/admin", Process: "admin"},
+			}},
+			want: []CnameRoute{
+				{PathPrefix: "/shop/admin", Process: "admin"},
+				{PathPrefix: "/shop", Process: "web"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, tt.cname.OrderedRoutes("web"))
+		})
+	}
+}
+
+func TestApp_CnameConflict(t *testing.T) {
+	newApp := func(name string, cnames ...Cname) App {
+		return App{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec:       AppSpec{Ingress: IngressSpec{Cnames: cnames}},
+		}
+	}
+	apps := []App{
+		newApp("orders", Cname{Name: "api.theketch.io", Path: "/orders"}),
+		newApp("web", Cname{Name: "theketch.io"}),
+	}
+	tests := []struct {
+		name    string
+		app     App
+		wantErr string
+	}{
+		{
+			name: "different path",
+			app:  newApp("users", Cname{Name: "api.theketch.io", Path: "/users"}),
+		},
+		{
+			name: "same app",
+			app:  newApp("orders", Cname{Name: "api.theketch.io", Path: "/orders"}, Cname{Name: "orders.theketch.io"}),
+		},
+		{
+			name:    "same path",
+			app:     newApp("users", Cname{Name: "api.theketch.io", Path: "/orders/"}),
+			wantErr: "cname conflict: api.theketch.io/orders is used by app orders",
+		},
+		{
+			name:    "same host without a path",
+			app:     newApp("users", Cname{Name: "theketch.io", Secure: true}),
+			wantErr: "cname conflict: theketch.io is used by app web",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.app.CnameConflict(apps)
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				require.ErrorIs(t, err, ErrCnameConflict)
+				return
+			}
+			require.Nil(t, err)
+		})
+	}
+}
+
+func TestApp_Validate(t *testing.T) {
+	newApp := func(update func(app *App)) App {
+		app := App{
+			ObjectMeta: metav1.ObjectMeta{Name: "go-app"},
+			Spec: AppSpec{
+				Env: []Env{{Name: "DEBUG", Value: "true"}},
+				Deployments: []AppDeploymentSpec{
+					{
+						Version:         1,
+						RoutingSettings: RoutingSettings{Weight: 100},
+						Processes: []ProcessSpec{
+							{Name: "web", Env: []Env{{Name: "PORT", Value: "8080"}}},
+							{Name: "worker"},
+						},
+					},
+				},
+				Ingress: IngressSpec{Cnames: CnameList{{Name: "theketch.io"}}},
+			},
+		}
+		if update != nil {
+			update(&app)
+		}
+		return app
+	}
+	tests := []struct {
+		name    string
+		app     App
+		wantErr string
+	}{
+		{
+			name: "valid app",
+			app:  newApp(nil),
+		},
+		{
+			name: "no deployments",
+			app: newApp(func(app *App) {
+				app.Spec.Deployments = nil
+			}),
+		},
+		{
+			name: "canary weights",
+			app: newApp(func(app *App) {
+				app.Spec.Deployments[0].RoutingSettings.Weight = 75
+				app.Spec.Deployments = append(app.Spec.Deployments, AppDeploymentSpec{Version: 2, RoutingSettings: RoutingSettings{Weight: 25}})
+				app.Spec.Canary = CanarySpec{Active: true, Steps: 4, StepWeight: 25, CurrentStep: 2}
+			}),
+		},
+		{
+			name: "weights don't sum to 100",
+			app: newApp(func(app *App) {
+				app.Spec.Deployments = append(app.Spec.Deployments, AppDeploymentSpec{Version: 2, RoutingSettings: RoutingSettings{Weight: 10}})
+			}),
+			wantErr: "weights of deployments should sum to 100, got 110",
+		},
+		{
+			name: "canary steps out of range",
+			app: newApp(func(app *App) {
+				app.Spec.Canary = CanarySpec{Active: true, Steps: 1, StepWeight: 100, CurrentStep: 1}
+			}),
+			wantErr: "canary steps must be between 2 and 100, got 1",
+		},
+		{
+			name: "invalid process name",
+			app: newApp(func(app *App) {
+				app.Spec.Deployments[0].Processes[1].Name = "Worker_1"
+			}),
+			wantErr: `invalid process name "Worker_1": a lowercase RFC 1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')`,
+		},
+		{
+			name: "invalid env name",
+			app: newApp(func(app *App) {
+				app.Spec.Env = append(app.Spec.Env, Env{Name: "1DEBUG"})
+			}),
+			wantErr: `invalid environment variable name "1DEBUG": a valid environment variable name must consist of alphabetic characters, digits, '_', '-', or '.', and must not start with a digit (e.g. 'my.env-name',  or 'MY_ENV.NAME',  or 'MyEnvName1', regex used for validation is '[-._a-zA-Z][-._a-zA-Z0-9]*')`,
+		},
+		{
+			name: "invalid process env name",
+			app: newApp(func(app *App) {
+				app.Spec.Deployments[0].Processes[0].Env = []Env{{Name: "MY VAR"}}
+			}),
+			wantErr: `process "web": invalid environment variable name "MY VAR": a valid environment variable name must consist of alphabetic characters, digits, '_', '-', or '.', and must not start with a digit (e.g. 'my.env-name',  or 'MY_ENV.NAME',  or 'MyEnvName1', regex used for validation is '[-._a-zA-Z][-._a-zA-Z0-9]*')`,
+		},
+		{
+			name: "duplicate cname",
+			app: newApp(func(app *App) {
+				app.Spec.Ingress.Cnames = append(app.Spec.Ingress.Cnames, Cname{Name: "theketch.io", Secure: true})
+			}),
+			wantErr: "cname theketch.io is listed more than once",
+		},
+		{
+			name: "invalid app ingress policies",
+			app: newApp(func(app *App) {
+				app.Spec.Ingress.Policies = &IngressPolicies{IPAllowlist: []string{"10.0.0.0/33"}}
+			}),
+			wantErr: `ingress policies: ipAllowlist: "10.0.0.0/33" is neither an IP address nor a CIDR range`,
+		},
+		{
+			name: "cname policies not supported by the ingress controller",
+			app: newApp(func(app *App) {
+				app.Spec.Ingress.Controller.IngressType = IstioIngressControllerType
+				app.Spec.Ingress.Cnames[0].Path = "/orders"
+				app.Spec.Ingress.Cnames[0].Policies = &IngressPolicies{RateLimit: &RateLimitPolicy{RequestsPerSecond: 10}}
+			}),
+			wantErr: "cname theketch.io: rateLimit: the istio ingress controller limits requests of whole hosts, it can't be used with a cname path",
+		},
+		{
+			name: "exposure not supported by the ingress controller",
+			app: newApp(func(app *App) {
+				app.Spec.Ingress.Controller.IngressType = NginxIngressControllerType
+				app.Spec.Deployments[0].KetchYaml = &KetchYamlData{Kubernetes: &KetchYamlKubernetesConfig{
+					Processes: map[string]KetchYamlProcessConfig{"worker": {Exposure: &ExposureSpec{Mode: ExposureUDPRoute, ListenPort: 514}}},
+				}}
+			}),
+			wantErr: `process "worker": exposure: the nginx ingress controller doesn't support the UDPRoute mode`,
+		},
+		{
+			name: "unknown app protocol",
+			app: newApp(func(app *App) {
+				app.Spec.Deployments[0].KetchYaml = &KetchYamlData{Kubernetes: &KetchYamlKubernetesConfig{
+					Processes: map[string]KetchYamlProcessConfig{"web": {Ports: []KetchYamlProcessPortConfig{{Port: 8080, AppProtocol: "thrift"}}}},
+				}}
+			}),
+			wantErr: `process "web": unknown application protocol "thrift"`,
+		},
+		{
+			name: "insecure skip verify without tls",
+			app: newApp(func(app *App) {
+				app.Spec.Deployments[0].KetchYaml = &KetchYamlData{Kubernetes: &KetchYamlKubernetesConfig{
+					Processes: map[string]KetchYamlProcessConfig{"web": {Ports: []KetchYamlProcessPortConfig{{Port: 8080, AppProtocol: GRPCAppProtocol, InsecureSkipVerify: true}}}},
+				}}
+			}),
+			wantErr: `process "web": insecure_skip_verify requires the grpcs application protocol`,
+		},
+		{
+			name: "upstream read timeout not supported by the ingress controller",
+			app: newApp(func(app *App) {
+				app.Spec.Ingress.Controller.IngressType = GatewayAPIIngressControllerType
+				app.Spec.Ingress.UpstreamReadTimeoutSeconds = 3600
+			}),
+			wantErr: "upstream read timeout is not supported by the gateway-api ingress controller",
+		},
+		{
+			name: "mesh policy with istio",
+			app: newApp(func(app *App) {
+				app.Spec.Ingress.Controller.IngressType = IstioIngressControllerType
+				app.Spec.Ingress.MeshPolicy = &MeshPolicy{RequestTimeoutSeconds: 15}
+			}),
+		},
+		{
+			name: "mesh policy not supported by the ingress controller",
+			app: newApp(func(app *App) {
+				app.Spec.Ingress.Controller.IngressType = TraefikIngressControllerType
+				app.Spec.Ingress.MeshPolicy = &MeshPolicy{RequestTimeoutSeconds: 15}
+			}),
+			wantErr: "mesh policy: it is supported only by the istio ingress controller, the app uses traefik",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.app.Validate()
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+		})
+	}
+}
+
+func TestCanarySpec_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		canary  CanarySpec
+		wantErr string
+	}{
+		{
+			name:   "inactive canary",
+			canary: CanarySpec{CurrentStep: 5, Steps: 4},
+		},
+		{
+			name:   "active canary",
+			canary: CanarySpec{Active: true, Steps: 3, StepWeight: 33, CurrentStep: 3},
+		},
+		{
+			name:    "too many steps",
+			canary:  CanarySpec{Active: true, Steps: 101, StepWeight: 1, CurrentStep: 1},
+			wantErr: "canary steps must be between 2 and 100, got 101",
+		},
+		{
+			name:    "zero step weight",
+			canary:  CanarySpec{Active: true, Steps: 4, CurrentStep: 1},
+			wantErr: "canary step weight must be between 1 and 100, got 0",
+		},
+		{
+			name:    "current step out of range",
+			canary:  CanarySpec{Active: true, Steps: 4, StepWeight: 25, CurrentStep: 5},
+			wantErr: "canary current step must be between 1 and 4, got 5",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.canary.Validate()
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+		})
+	}
+}
+
+func TestApp_SetDefaults(t *testing.T) {
+	ingressController := &IngressControllerSpec{
+		ClassName:       "nginx",
+		ServiceEndpoint: "10.10.10.10",
+		IngressType:     NginxIngressControllerType,
+	}
+	tests := []struct {
+		name              string
+		app               App
+		ingressController *IngressControllerSpec
+		want              AppSpec
+	}{
+		{
+			name: "empty app",
+			app: App{
+				Spec: AppSpec{
+					Deployments: []AppDeploymentSpec{
+						{Processes: []ProcessSpec{{Name: "web"}, {Name: "worker", Units: intRef(0)}}},
+					},
+				},
+			},
+			ingressController: ingressController,
+			want: AppSpec{
+				Version: stringRef("v1"),
+				Deployments: []AppDeploymentSpec{
+					{Processes: []ProcessSpec{{Name: "web", Units: intRef(1)}, {Name: "worker", Units: intRef(0)}}},
+				},
+				Ingress: IngressSpec{Controller: *ingressController},
+			},
+		},
+		{
+			name: "configured ingress controller",
+			app: App{
+				Spec: AppSpec{
+					Version: stringRef("v2"),
+					Ingress: IngressSpec{Controller: IngressControllerSpec{
+						ClassName:       "traefik",
+						ServiceEndpoint: "20.20.20.20",
+						IngressType:     TraefikIngressControllerType,
+					}},
+				},
+			},
+			ingressController: ingressController,
+			want: AppSpec{
+				Version: stringRef("v2"),
+				Ingress: IngressSpec{Controller: IngressControllerSpec{
+					ClassName:       "traefik",
+					ServiceEndpoint: "20.20.20.20",
+					IngressType:     TraefikIngressControllerType,
+				}},
+			},
+		},
+		{
+			name: "partially configured ingress controller",
+			app: App{
+				Spec: AppSpec{
+					Ingress: IngressSpec{Controller: IngressControllerSpec{
+						ClassName: "nginx-internal",
+						Profile:   "internal",
+					}},
+				},
+			},
+			ingressController: &IngressControllerSpec{
+				ClassName:       "nginx",
+				ServiceEndpoint: "10.10.10.10",
+				IngressType:     NginxIngressControllerType,
+				ClusterIssuer:   "letsencrypt",
+			},
+			want: AppSpec{
+				Version: stringRef("v1"),
+				Ingress: IngressSpec{Controller: IngressControllerSpec{
+					ClassName:       "nginx-internal",
+					ServiceEndpoint: "10.10.10.10",
+					IngressType:     NginxIngressControllerType,
+					ClusterIssuer:   "letsencrypt",
+					Profile:         "internal",
+				}},
+			},
+		},
+		{
+			name: "no ingress controller",
+			app:  App{},
+			want: AppSpec{Version: stringRef("v1")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.app.SetDefaults(tt.ingressController)
+			require.Equal(t, tt.want, tt.app.Spec)
+		})
+	}
+}
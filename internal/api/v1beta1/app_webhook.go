@@ -0,0 +1,129 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// applog is for logging in this package.
+var applog = logf.Log.WithName("app-resource")
+
+var appmgr manager = nil
+
+func (app *App) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	appmgr = mgr
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(app).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-theketch-io-v1beta1-app,mutating=true,failurePolicy=fail,groups=theketch.io,resources=apps,versions=v1beta1,name=mapp.kb.io,sideEffects=none,admissionReviewVersions=v1beta1
+
+var _ webhook.Defaulter = &App{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (app *App) Default() {
+	applog.Info("default", "name", app.Name)
+	ingressController, err := GetIngressControllerSpec(context.Background(), appmgr.GetClient(), app.Spec.Ingress.Controller.Profile)
+	if client.IgnoreNotFound(err) != nil {
+		// the ingress watcher sets the ingress controller of apps once the configmap of their profile is readable.
+		applog.Error(err, "failed to get ingress controller spec", "name", app.Name)
+	}
+	app.SetDefaults(ingressController)
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-theketch-io-v1beta1-app,mutating=false,failurePolicy=fail,groups=theketch.io,resources=apps,versions=v1beta1,name=vapp.kb.io,sideEffects=none,admissionReviewVersions=v1beta1
+
+var _ webhook.Validator = &App{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (app *App) ValidateCreate() (admission.Warnings, error) {
+	applog.Info("validate create", "name", app.Name)
+	return nil, app.validateWithOtherApps()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+// Only violations and cname conflicts introduced by the update are rejected,
+// so an app that already breaks a rule can still be updated, for example by the controller.
+func (app *App) ValidateUpdate(old runtime.Object) (admission.Warnings, error) {
+	applog.Info("validate update", "name", app.Name)
+	if app.DeletionTimestamp != nil {
+		// an app being deleted must be able to drop its finalizer whatever its spec is.
+		return nil, nil
+	}
+	oldApp, ok := old.(*App)
+	if !ok {
+		return nil, app.validateWithOtherApps()
+	}
+	if err := app.Validate(); err != nil {
+		if oldErr := oldApp.Validate(); oldErr == nil || oldErr.Error() != err.Error() {
+			return nil, err
+		}
+	}
+	return nil, app.validateCnames(app.addedCnames(oldApp))
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (app *App) ValidateDelete() (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (app *App) validateWithOtherApps() error {
+	if err := app.Validate(); err != nil {
+		return err
+	}
+	return app.validateCnames(app.Spec.Ingress.Cnames)
+}
+
+// validateCnames returns an error if any of the cnames is used by another app.
+func (app *App) validateCnames(cnames CnameList) error {
+	if len(cnames) == 0 {
+		return nil
+	}
+	apps := AppList{}
+	if err := appmgr.GetClient().List(context.Background(), &apps); err != nil {
+		return err
+	}
+	claimed := App{ObjectMeta: app.ObjectMeta, Spec: AppSpec{Ingress: IngressSpec{Cnames: cnames}}}
+	return claimed.CnameConflict(apps.Items)
+}
+
+// addedCnames returns cnames of the app that the old version of the app doesn't have.
+func (app *App) addedCnames(old *App) CnameList {
+	var added CnameList
+	for _, cname := range app.Spec.Ingress.Cnames {
+		found := false
+		for _, oldCname := range old.Spec.Ingress.Cnames {
+			if cname.Name == oldCname.Name && cname.NormalizedPath() == oldCname.NormalizedPath() {
+				found = true
+				break
+			}
+		}
+		if !found {
+			added = append(added, cname)
+		}
+	}
+	return added
+}
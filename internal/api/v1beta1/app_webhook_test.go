@@ -0,0 +1,253 @@
+package v1beta1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/theketchio/ketch/internal/api/v1beta1/mocks"
+)
+
+func TestApp_ValidateCreate(t *testing.T) {
+
+	const listError Error = "error"
+
+	otherApps := func(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+		apps := list.(*AppList)
+		apps.Items = []App{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "orders"},
+				Spec:       AppSpec{Ingress: IngressSpec{Cnames: CnameList{{Name: "theketch.io"}}}},
+			},
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name    string
+		app     App
+		client  *mocks.MockClient
+		wantErr string
+	}{
+		{
+			name: "error getting a list of apps",
+			app: App{
+				ObjectMeta: metav1.ObjectMeta{Name: "users"},
+				Spec:       AppSpec{Ingress: IngressSpec{Cnames: CnameList{{Name: "users.theketch.io"}}}},
+			},
+			client: &mocks.MockClient{
+				OnList: func(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+					return listError
+				},
+			},
+			wantErr: "error",
+		},
+		{
+			name: "cname used by another app",
+			app: App{
+				ObjectMeta: metav1.ObjectMeta{Name: "users"},
+				Spec:       AppSpec{Ingress: IngressSpec{Cnames: CnameList{{Name: "theketch.io"}}}},
+			},
+			client:  &mocks.MockClient{OnList: otherApps},
+			wantErr: "cname conflict: theketch.io is used by app orders",
+		},
+		{
+			name: "invalid spec",
+			app: App{
+				ObjectMeta: metav1.ObjectMeta{Name: "users"},
+				Spec: AppSpec{
+					Deployments: []AppDeploymentSpec{{Version: 1, RoutingSettings: RoutingSettings{Weight: 90}}},
+				},
+			},
+			client:  &mocks.MockClient{OnList: otherApps},
+			wantErr: "weights of deployments should sum to 100, got 90",
+		},
+		{
+			name: "success",
+			app: App{
+				ObjectMeta: metav1.ObjectMeta{Name: "users"},
+				Spec:       AppSpec{Ingress: IngressSpec{Cnames: CnameList{{Name: "theketch.io", Path: "/users"}}}},
+			},
+			client: &mocks.MockClient{OnList: otherApps},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appmgr = &mockManager{client: tt.client}
+			_, err := tt.app.ValidateCreate()
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+		})
+	}
+}
+
+func TestApp_ValidateUpdate(t *testing.T) {
+	client := &mocks.MockClient{
+		OnList: func(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+			apps := list.(*AppList)
+			apps.Items = []App{
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "orders"},
+					Spec:       AppSpec{Ingress: IngressSpec{Cnames: CnameList{{Name: "theketch.io"}}}},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{Name: "users"},
+					Spec:       AppSpec{Ingress: IngressSpec{Cnames: CnameList{{Name: "users.theketch.io"}}}},
+				},
+			}
+			return nil
+		},
+	}
+	now := metav1.NewTime(time.Now())
+
+	invalid := AppSpec{
+		Deployments: []AppDeploymentSpec{{Version: 1, RoutingSettings: RoutingSettings{Weight: 90}}},
+		Ingress:     IngressSpec{Cnames: CnameList{{Name: "theketch.io"}}},
+	}
+
+	tests := []struct {
+		name    string
+		app     App
+		old     App
+		wantErr string
+	}{
+		{
+			name: "own cname",
+			app: App{
+				ObjectMeta: metav1.ObjectMeta{Name: "users"},
+				Spec:       AppSpec{Ingress: IngressSpec{Cnames: CnameList{{Name: "users.theketch.io"}}}},
+			},
+		},
+		{
+			name: "cname used by another app",
+			app: App{
+				ObjectMeta: metav1.ObjectMeta{Name: "users"},
+				Spec:       AppSpec{Ingress: IngressSpec{Cnames: CnameList{{Name: "users.theketch.io"}, {Name: "theketch.io"}}}},
+			},
+			wantErr: "cname conflict: theketch.io is used by app orders",
+		},
+		{
+			name: "app being deleted",
+			app: App{
+				ObjectMeta: metav1.ObjectMeta{Name: "users", DeletionTimestamp: &now},
+				Spec:       AppSpec{Ingress: IngressSpec{Cnames: CnameList{{Name: "theketch.io"}}}},
+			},
+		},
+		{
+			name: "cname conflict the app already had",
+			app: App{
+				ObjectMeta: metav1.ObjectMeta{Name: "users", Finalizers: []string{"theketch.io/app"}},
+				Spec:       AppSpec{Ingress: IngressSpec{Cnames: CnameList{{Name: "theketch.io"}}}},
+			},
+			old: App{
+				ObjectMeta: metav1.ObjectMeta{Name: "users"},
+				Spec:       AppSpec{Ingress: IngressSpec{Cnames: CnameList{{Name: "theketch.io"}}}},
+			},
+		},
+		{
+			name: "violation the app already had",
+			app: App{
+				ObjectMeta: metav1.ObjectMeta{Name: "users", Finalizers: []string{"theketch.io/app"}},
+				Spec:       invalid,
+			},
+			old: App{
+				ObjectMeta: metav1.ObjectMeta{Name: "users"},
+				Spec:       invalid,
+			},
+		},
+		{
+			name: "violation introduced by the update",
+			app: App{
+				ObjectMeta: metav1.ObjectMeta{Name: "users"},
+				Spec:       invalid,
+			},
+			old: App{
+				ObjectMeta: metav1.ObjectMeta{Name: "users"},
+				Spec:       AppSpec{Ingress: IngressSpec{Cnames: CnameList{{Name: "theketch.io"}}}},
+			},
+			wantErr: "weights of deployments should sum to 100, got 90",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appmgr = &mockManager{client: client}
+			_, err := tt.app.ValidateUpdate(&tt.old)
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+		})
+	}
+}
+
+func TestApp_Default(t *testing.T) {
+	const getError Error = "error"
+
+	tests := []struct {
+		name    string
+		profile string
+		client  *mocks.MockClient
+		want    IngressControllerSpec
+	}{
+		{
+			name: "ingress controller from the configmap",
+			client: &mocks.MockClient{
+				OnGet: func(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+					require.Equal(t, client.ObjectKey{Name: IngressConfigmapName, Namespace: IngressConfigmapNamespace}, key)
+					configmap := obj.(*v1.ConfigMap)
+					configmap.Data = map[string]string{"className": "nginx", "serviceEndpoint": "10.10.10.10", "type": "nginx"}
+					return nil
+				},
+			},
+			want: IngressControllerSpec{ClassName: "nginx", ServiceEndpoint: "10.10.10.10", IngressType: NginxIngressControllerType},
+		},
+		{
+			name:    "ingress controller from the configmap of the app's profile",
+			profile: "internal",
+			client: &mocks.MockClient{
+				OnGet: func(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+					require.Equal(t, client.ObjectKey{Name: "ketch-ingress-internal", Namespace: IngressConfigmapNamespace}, key)
+					configmap := obj.(*v1.ConfigMap)
+					configmap.Name = key.Name
+					configmap.Data = map[string]string{"className": "nginx-internal", "serviceEndpoint": "10.0.0.1", "type": "nginx"}
+					return nil
+				},
+			},
+			want: IngressControllerSpec{ClassName: "nginx-internal", ServiceEndpoint: "10.0.0.1", IngressType: NginxIngressControllerType, Profile: "internal"},
+		},
+		{
+			name: "error getting the configmap",
+			client: &mocks.MockClient{
+				OnGet: func(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+					return getError
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appmgr = &mockManager{client: tt.client}
+			app := App{
+				ObjectMeta: metav1.ObjectMeta{Name: "users"},
+				Spec: AppSpec{
+					Deployments: []AppDeploymentSpec{{Processes: []ProcessSpec{{Name: "web"}}}},
+					Ingress:     IngressSpec{Controller: IngressControllerSpec{Profile: tt.profile}},
+				},
+			}
+			app.Default()
+			require.Equal(t, tt.want, app.Spec.Ingress.Controller)
+			require.Equal(t, "v1", *app.Spec.Version)
+			require.Equal(t, 1, *app.Spec.Deployments[0].Processes[0].Units)
+		})
+	}
+}
@@ -14,4 +14,7 @@ const (
 
 	// ErrJobExists
 	ErrJobExists Error = "failed to create job because the job already exists"
+
+	// ErrCnameConflict is returned when a cname claims the same hostname and path as a cname of another app.
+	ErrCnameConflict Error = "cname conflict"
 )
@@ -0,0 +1,125 @@
+package v1beta1
+
+import (
+	"fmt"
+	"net"
+)
+
+// ExposureMode is a way a process is made reachable.
+type ExposureMode string
+
+const (
+	// ExposureNone doesn't create any Service for a process, so it isn't reachable at all.
+	ExposureNone ExposureMode = "None"
+	// ExposureClusterInternal makes a process reachable only from inside the cluster with a ClusterIP Service.
+	ExposureClusterInternal ExposureMode = "ClusterInternal"
+	// ExposureLoadBalancer makes a process reachable with a LoadBalancer Service.
+	ExposureLoadBalancer ExposureMode = "LoadBalancer"
+	// ExposureNodePort makes a process reachable on a port of every node with a NodePort Service.
+	ExposureNodePort ExposureMode = "NodePort"
+	// ExposureTCPRoute sends TCP connections accepted by the ingress controller to a process.
+	ExposureTCPRoute ExposureMode = "TCPRoute"
+	// ExposureUDPRoute sends UDP datagrams accepted by the ingress controller to a process.
+	ExposureUDPRoute ExposureMode = "UDPRoute"
+)
+
+// ExposureSpec configures how a process is made reachable.
+// A process without exposure gets a ClusterIP Service and, if it is the routable process, HTTP ingress.
+// Every mode except None creates a Service named "<app>-<process>-exposed" which stays the same across deployments.
+type ExposureSpec struct {
+	// Mode is one of None, ClusterInternal, LoadBalancer, NodePort, TCPRoute and UDPRoute.
+	// A process exposed with None, ClusterInternal, TCPRoute or UDPRoute doesn't receive HTTP requests from the ingress controller.
+	// +kubebuilder:validation:Enum=None;ClusterInternal;LoadBalancer;NodePort;TCPRoute;UDPRoute
+	Mode ExposureMode `json:"mode"`
+
+	// NodePort is a node port of the first service port of the process, kubernetes allocates one if not set.
+	// It can be set only in the NodePort mode.
+	// +optional
+	NodePort int32 `json:"nodePort,omitempty"`
+
+	// LoadBalancerSourceRanges is a list of CIDR ranges of clients allowed to connect to a LoadBalancer.
+	// It can be set only in the LoadBalancer mode.
+	// +optional
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+
+	// EntryPoint is a name of a traefik entry point accepting connections of a TCP or UDP route.
+	// The entry point must be configured in traefik's static configuration.
+	// +optional
+	EntryPoint string `json:"entryPoint,omitempty"`
+
+	// ListenPort is a port of the istio ingress gateway accepting connections of a TCP route.
+	// The port must be exposed by the Service of the ingress gateway.
+	// +optional
+	ListenPort int32 `json:"listenPort,omitempty"`
+}
+
+// IsHTTPRoutable returns true if the ingress controller can send HTTP requests to the process.
+func (e *ExposureSpec) IsHTTPRoutable() bool {
+	if e == nil {
+		return true
+	}
+	return e.Mode == ExposureLoadBalancer || e.Mode == ExposureNodePort
+}
+
+// IsRoute returns true if the ingress controller sends TCP or UDP traffic to the process.
+func (e *ExposureSpec) IsRoute() bool {
+	return e != nil && (e.Mode == ExposureTCPRoute || e.Mode == ExposureUDPRoute)
+}
+
+// Validate returns an error if the exposure is invalid or not supported by the ingress controller.
+// An empty ingressType skips checks that depend on the ingress controller.
+func (e *ExposureSpec) Validate(ingressType IngressControllerType) error {
+	if e == nil {
+		return nil
+	}
+	switch e.Mode {
+	case ExposureNone, ExposureClusterInternal, ExposureLoadBalancer, ExposureNodePort, ExposureTCPRoute, ExposureUDPRoute:
+	default:
+		return fmt.Errorf("exposure: unknown mode %q", e.Mode)
+	}
+	if e.NodePort != 0 {
+		if e.Mode != ExposureNodePort {
+			return fmt.Errorf("exposure: nodePort can be set only in the %s mode", ExposureNodePort)
+		}
+		if e.NodePort < 1 || e.NodePort > 65535 {
+			return fmt.Errorf("exposure: invalid nodePort %d", e.NodePort)
+		}
+	}
+	if len(e.LoadBalancerSourceRanges) > 0 && e.Mode != ExposureLoadBalancer {
+		return fmt.Errorf("exposure: loadBalancerSourceRanges can be set only in the %s mode", ExposureLoadBalancer)
+	}
+	for _, cidr := range e.LoadBalancerSourceRanges {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("exposure: invalid load balancer source range %q", cidr)
+		}
+	}
+	if !e.IsRoute() {
+		if len(e.EntryPoint) > 0 || e.ListenPort != 0 {
+			return fmt.Errorf("exposure: entryPoint and listenPort can be set only in the %s and %s modes", ExposureTCPRoute, ExposureUDPRoute)
+		}
+		return nil
+	}
+	if e.ListenPort < 0 || e.ListenPort > 65535 {
+		return fmt.Errorf("exposure: invalid listenPort %d", e.ListenPort)
+	}
+	switch ingressType {
+	case TraefikIngressControllerType:
+		if len(e.EntryPoint) == 0 {
+			return fmt.Errorf("exposure: traefik requires an entryPoint of the %s", e.Mode)
+		}
+	case IstioIngressControllerType:
+		if e.Mode == ExposureUDPRoute {
+			return fmt.Errorf("exposure: istio doesn't support the %s mode", ExposureUDPRoute)
+		}
+		if e.ListenPort == 0 {
+			return fmt.Errorf("exposure: istio requires a listenPort of the %s", e.Mode)
+		}
+	case "":
+		if len(e.EntryPoint) == 0 && e.ListenPort == 0 {
+			return fmt.Errorf("exposure: %s requires an entryPoint for traefik or a listenPort for istio", e.Mode)
+		}
+	default:
+		return fmt.Errorf("exposure: the %s ingress controller doesn't support the %s mode", ingressType, e.Mode)
+	}
+	return nil
+}
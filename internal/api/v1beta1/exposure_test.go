@@ -0,0 +1,124 @@
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExposureSpec_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		exposure    *ExposureSpec
+		ingressType IngressControllerType
+		wantErr     string
+	}{
+		{
+			name: "no exposure",
+		},
+		{
+			name:     "cluster internal",
+			exposure: &ExposureSpec{Mode: ExposureClusterInternal},
+		},
+		{
+			name:     "load balancer",
+			exposure: &ExposureSpec{Mode: ExposureLoadBalancer, LoadBalancerSourceRanges: []string{"10.0.0.0/8"}},
+		},
+		{
+			name:     "node port",
+			exposure: &ExposureSpec{Mode: ExposureNodePort, NodePort: 30080},
+		},
+		{
+			name:        "traefik udp route",
+			exposure:    &ExposureSpec{Mode: ExposureUDPRoute, EntryPoint: "syslog"},
+			ingressType: TraefikIngressControllerType,
+		},
+		{
+			name:        "istio tcp route",
+			exposure:    &ExposureSpec{Mode: ExposureTCPRoute, ListenPort: 5432},
+			ingressType: IstioIngressControllerType,
+		},
+		{
+			name:     "unknown mode",
+			exposure: &ExposureSpec{Mode: "ExternalName"},
+			wantErr:  `exposure: unknown mode "ExternalName"`,
+		},
+		{
+			name:     "node port in load balancer mode",
+			exposure: &ExposureSpec{Mode: ExposureLoadBalancer, NodePort: 30080},
+			wantErr:  "exposure: nodePort can be set only in the NodePort mode",
+		},
+		{
+			name:     "invalid source range",
+			exposure: &ExposureSpec{Mode: ExposureLoadBalancer, LoadBalancerSourceRanges: []string{"10.0.0.1"}},
+			wantErr:  `exposure: invalid load balancer source range "10.0.0.1"`,
+		},
+		{
+			name:     "source ranges in node port mode",
+			exposure: &ExposureSpec{Mode: ExposureNodePort, LoadBalancerSourceRanges: []string{"10.0.0.0/8"}},
+			wantErr:  "exposure: loadBalancerSourceRanges can be set only in the LoadBalancer mode",
+		},
+		{
+			name:     "entry point in cluster internal mode",
+			exposure: &ExposureSpec{Mode: ExposureClusterInternal, EntryPoint: "postgres"},
+			wantErr:  "exposure: entryPoint and listenPort can be set only in the TCPRoute and UDPRoute modes",
+		},
+		{
+			name:     "route without entry point and listen port",
+			exposure: &ExposureSpec{Mode: ExposureTCPRoute},
+			wantErr:  "exposure: TCPRoute requires an entryPoint for traefik or a listenPort for istio",
+		},
+		{
+			name:        "traefik route without entry point",
+			exposure:    &ExposureSpec{Mode: ExposureTCPRoute, ListenPort: 5432},
+			ingressType: TraefikIngressControllerType,
+			wantErr:     "exposure: traefik requires an entryPoint of the TCPRoute",
+		},
+		{
+			name:        "istio udp route",
+			exposure:    &ExposureSpec{Mode: ExposureUDPRoute, ListenPort: 514},
+			ingressType: IstioIngressControllerType,
+			wantErr:     "exposure: istio doesn't support the UDPRoute mode",
+		},
+		{
+			name:        "nginx tcp route",
+			exposure:    &ExposureSpec{Mode: ExposureTCPRoute, ListenPort: 5432},
+			ingressType: NginxIngressControllerType,
+			wantErr:     "exposure: the nginx ingress controller doesn't support the TCPRoute mode",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.exposure.Validate(tt.ingressType)
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+		})
+	}
+}
+
+func TestAppDeploymentSpec_ProcessExposure(t *testing.T) {
+	internal := &ExposureSpec{Mode: ExposureClusterInternal}
+	loadBalancer := &ExposureSpec{Mode: ExposureLoadBalancer}
+	deployment := AppDeploymentSpec{
+		Processes: []ProcessSpec{
+			{Name: "web", Exposure: loadBalancer},
+			{Name: "worker"},
+			{Name: "cron"},
+		},
+		KetchYaml: &KetchYamlData{
+			Kubernetes: &KetchYamlKubernetesConfig{
+				Processes: map[string]KetchYamlProcessConfig{
+					"web":    {Exposure: internal},
+					"worker": {Exposure: internal},
+				},
+			},
+		},
+	}
+	require.Equal(t, loadBalancer, deployment.ProcessExposure("web"))
+	require.Equal(t, internal, deployment.ProcessExposure("worker"))
+	require.Nil(t, deployment.ProcessExposure("cron"))
+	require.Nil(t, AppDeploymentSpec{}.ProcessExposure("web"))
+}
@@ -19,8 +19,11 @@ package v1beta1
 import (
 	"context"
 	"fmt"
+	"strings"
+	"text/template"
 
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	v1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -35,23 +38,172 @@ const (
 	TraefikIngressControllerType IngressControllerType = "traefik"
 	IstioIngressControllerType   IngressControllerType = "istio"
 	NginxIngressControllerType   IngressControllerType = "nginx"
+	// GatewayAPIIngressControllerType routes requests with HTTPRoutes attached to a shared Gateway,
+	// see GatewayName of IngressControllerSpec. ClassName of such a controller is a name of the Gateway's GatewayClass.
+	GatewayAPIIngressControllerType IngressControllerType = "gateway-api"
+
+	// TraefikAPIGroup is the API group of Traefik CRDs since Traefik v2.10, the only group served by Traefik v3.
+	TraefikAPIGroup = "traefik.io"
+	// LegacyTraefikAPIGroup is the deprecated API group of Traefik CRDs, it is removed in Traefik v3.
+	LegacyTraefikAPIGroup = "traefik.containo.us"
+
+	// LegacyDefaultCnameTemplate is used to render default cnames of apps if the ingress controller doesn't configure a template.
+	LegacyDefaultCnameTemplate = "{{.App}}.{{.ServiceEndpoint}}." + ShipaCloudDomain
 
 	IngressConfigmapNamespace = "default"
 	IngressConfigmapName      = "ketch-ingress"
 )
 
+// SupportedIngressControllerTypes is a list of ingress controller types ketch has templates for.
+var SupportedIngressControllerTypes = []IngressControllerType{
+	TraefikIngressControllerType,
+	IstioIngressControllerType,
+	NginxIngressControllerType,
+	GatewayAPIIngressControllerType,
+}
+
+// IsSupported returns true if ketch has templates for the ingress controller type.
+func (t IngressControllerType) IsSupported() bool {
+	for _, supported := range SupportedIngressControllerTypes {
+		if t == supported {
+			return true
+		}
+	}
+	return false
+}
+
 // IngressControllerSpec contains configuration for an ingress controller.
 type IngressControllerSpec struct {
 	ClassName       string                `json:"className,omitempty"`
 	ServiceEndpoint string                `json:"serviceEndpoint,omitempty"`
 	IngressType     IngressControllerType `json:"type,omitempty"`
 	ClusterIssuer   string                `json:"clusterIssuer,omitempty"`
+	// TraefikAPIGroup is an API group of IngressRoutes and Middlewares created for apps
+	// when the ingress controller is traefik, either traefik.io or traefik.containo.us.
+	// If empty, ketch uses traefik.io if the cluster serves it and traefik.containo.us otherwise.
+	TraefikAPIGroup string `json:"traefikAPIGroup,omitempty"`
+	// Profile is a name of an ingress profile this configuration comes from.
+	// Several ingress controllers can serve apps of a cluster, each of them is configured by its own profile.
+	// Empty Profile stands for the default profile stored in the ketch-ingress configmap.
+	Profile string `json:"profile,omitempty"`
+	// DefaultCnameTemplate is a text/template used to render default cnames of apps,
+	// for example "{{.App}}.{{.Namespace}}.apps.example.com" or "{{.App}}.{{.ServiceEndpoint}}.nip.io".
+	// The template gets App, Namespace and ServiceEndpoint fields. LegacyDefaultCnameTemplate is used if it is empty.
+	DefaultCnameTemplate string `json:"defaultCnameTemplate,omitempty"`
+	// DefaultCnameSecretName is a name of a Secret with a wildcard certificate for default cnames.
+	// If set, default cnames are served over https with the certificate,
+	// the Secret must exist in the namespace of each app.
+	DefaultCnameSecretName string `json:"defaultCnameSecretName,omitempty"`
+	// GatewayName is a name of a shared Gateway HTTPRoutes of apps are attached to
+	// when the ingress controller is gateway-api, ServiceEndpoint is the address of the Gateway.
+	// The Gateway must have a listener named "http" on port 80 and a listener named "https" on port 443
+	// terminating TLS of secure cnames, and both listeners must allow routes from namespaces of apps.
+	// Certificate Secrets of cnames are created in namespaces of apps, so the Gateway refers to them with ReferenceGrants
+	// unless it uses its own wildcard certificate.
+	GatewayName string `json:"gatewayName,omitempty"`
+	// GatewayNamespace is a namespace of the shared Gateway, a namespace of an app if empty.
+	GatewayNamespace string `json:"gatewayNamespace,omitempty"`
+}
+
+// IsConfigured returns true if the ingress controller has a type, a service endpoint and a class name.
+func (s IngressControllerSpec) IsConfigured() bool {
+	return len(s.IngressType) > 0 && len(s.ServiceEndpoint) > 0 && len(s.ClassName) > 0
 }
 
-// GetIngressControllerSpec gets the ketch-ingress configmap and returns an IngressControllerSpec from the configmap's data
-func GetIngressControllerSpec(ctx context.Context, client client.Client) (*IngressControllerSpec, error) {
+// SetDefaults sets each unset field of the ingress controller to the value of defaults.
+func (s *IngressControllerSpec) SetDefaults(defaults IngressControllerSpec) {
+	setDefault := func(value *string, defaultValue string) {
+		if len(*value) == 0 {
+			*value = defaultValue
+		}
+	}
+	setDefault(&s.ClassName, defaults.ClassName)
+	setDefault(&s.ServiceEndpoint, defaults.ServiceEndpoint)
+	setDefault(&s.ClusterIssuer, defaults.ClusterIssuer)
+	setDefault(&s.TraefikAPIGroup, defaults.TraefikAPIGroup)
+	setDefault(&s.Profile, defaults.Profile)
+	setDefault(&s.DefaultCnameTemplate, defaults.DefaultCnameTemplate)
+	setDefault(&s.DefaultCnameSecretName, defaults.DefaultCnameSecretName)
+	setDefault(&s.GatewayName, defaults.GatewayName)
+	setDefault(&s.GatewayNamespace, defaults.GatewayNamespace)
+	if len(s.IngressType) == 0 {
+		s.IngressType = defaults.IngressType
+	}
+}
+
+// defaultCnameData is passed to DefaultCnameTemplate to render a default cname of an app.
+type defaultCnameData struct {
+	App             string
+	Namespace       string
+	ServiceEndpoint string
+}
+
+// DefaultCname renders a default cname of the app with the given name deployed to the given namespace.
+func (s IngressControllerSpec) DefaultCname(appName, namespace string) (string, error) {
+	text := s.DefaultCnameTemplate
+	if len(text) == 0 {
+		text = LegacyDefaultCnameTemplate
+	}
+	tpl, err := template.New("default-cname").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("invalid default cname template: %w", err)
+	}
+	var buf strings.Builder
+	data := defaultCnameData{App: appName, Namespace: namespace, ServiceEndpoint: s.ServiceEndpoint}
+	if err := tpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("invalid default cname template: %w", err)
+	}
+	cname := buf.String()
+	if msgs := validation.IsDNS1123Subdomain(cname); len(msgs) > 0 {
+		return "", fmt.Errorf("invalid default cname %q: %s", cname, strings.Join(msgs, ", "))
+	}
+	return cname, nil
+}
+
+// ValidateDefaultCnameTemplate returns an error if the template can't render a valid cname.
+func ValidateDefaultCnameTemplate(text string) error {
+	spec := IngressControllerSpec{DefaultCnameTemplate: text, ServiceEndpoint: "10.0.0.1"}
+	_, err := spec.DefaultCname("app", "namespace")
+	return err
+}
+
+// IngressProfileConfigmapName returns a name of a configmap that contains configuration of the given ingress profile.
+func IngressProfileConfigmapName(profile string) string {
+	if len(profile) == 0 {
+		return IngressConfigmapName
+	}
+	return IngressConfigmapName + "-" + profile
+}
+
+// IngressProfileFromConfigmapName returns a name of an ingress profile stored in the configmap with the given name
+// and false if the configmap isn't an ingress profile configmap.
+func IngressProfileFromConfigmapName(name string) (string, bool) {
+	if name == IngressConfigmapName {
+		return "", true
+	}
+	profile := strings.TrimPrefix(name, IngressConfigmapName+"-")
+	if profile == name || len(profile) == 0 {
+		return "", false
+	}
+	return profile, true
+}
+
+// ValidateIngressProfile returns an error if the name can't be used as a name of an ingress profile.
+func ValidateIngressProfile(profile string) error {
+	if len(profile) == 0 {
+		return nil
+	}
+	if msgs := validation.IsDNS1123Label(profile); len(msgs) > 0 {
+		return fmt.Errorf("invalid ingress profile %q: %s", profile, strings.Join(msgs, ", "))
+	}
+	return nil
+}
+
+// GetIngressControllerSpec gets the configmap of the given ingress profile and returns an IngressControllerSpec from the configmap's data.
+// Empty profile stands for the default ketch-ingress configmap.
+func GetIngressControllerSpec(ctx context.Context, client client.Reader, profile string) (*IngressControllerSpec, error) {
 	var configmap v1.ConfigMap
-	if err := client.Get(ctx, types.NamespacedName{Name: IngressConfigmapName, Namespace: IngressConfigmapNamespace}, &configmap); err != nil {
+	if err := client.Get(ctx, types.NamespacedName{Name: IngressProfileConfigmapName(profile), Namespace: IngressConfigmapNamespace}, &configmap); err != nil {
 		return nil, err
 	}
 	if configmap.Data == nil {
@@ -61,6 +213,7 @@ func GetIngressControllerSpec(ctx context.Context, client client.Client) (*Ingre
 }
 
 func NewIngressControllerSpec(configmap v1.ConfigMap) *IngressControllerSpec {
+	profile, _ := IngressProfileFromConfigmapName(configmap.Name)
 	controllerType := IngressControllerType(configmap.Data["type"])
 	if len(controllerType) == 0 {
 		controllerType = IngressControllerType(configmap.Data["ingressType"])
@@ -70,5 +223,12 @@ func NewIngressControllerSpec(configmap v1.ConfigMap) *IngressControllerSpec {
 		ServiceEndpoint: configmap.Data["serviceEndpoint"],
 		IngressType:     controllerType,
 		ClusterIssuer:   configmap.Data["clusterIssuer"],
+		TraefikAPIGroup: configmap.Data["traefikAPIGroup"],
+		Profile:         profile,
+
+		DefaultCnameTemplate:   configmap.Data["defaultCnameTemplate"],
+		DefaultCnameSecretName: configmap.Data["defaultCnameSecretName"],
+		GatewayName:            configmap.Data["gatewayName"],
+		GatewayNamespace:       configmap.Data["gatewayNamespace"],
 	}
 }
@@ -0,0 +1,348 @@
+package v1beta1
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// IngressPolicies configure how an ingress controller handles requests to an application.
+// They are rendered as nginx annotations, traefik Middlewares and istio VirtualService fields,
+// AuthorizationPolicies and EnvoyFilters.
+type IngressPolicies struct {
+	// RateLimit limits the rate of requests.
+	// nginx and traefik limit requests of each client IP address, istio limits requests handled by each ingress gateway pod.
+	// +optional
+	RateLimit *RateLimitPolicy `json:"rateLimit,omitempty"`
+	// CORS answers preflight requests and adds CORS headers to responses.
+	// +optional
+	CORS *CORSPolicy `json:"cors,omitempty"`
+	// IPAllowlist is a list of IP addresses and CIDR ranges of clients allowed to send requests.
+	// Requests from other clients are rejected.
+	// +optional
+	IPAllowlist []string `json:"ipAllowlist,omitempty"`
+	// RequestHeaders are set on requests before they are sent to the application.
+	// nginx sets them with a configuration snippet which requires "allow-snippet-annotations" to be enabled,
+	// it is disabled by default since ingress-nginx 1.9. Values can't contain "$" as nginx would expand it as a variable.
+	// +optional
+	RequestHeaders map[string]string `json:"requestHeaders,omitempty"`
+	// ResponseHeaders are set on responses of the application.
+	// nginx sets them with a configuration snippet which requires "allow-snippet-annotations" to be enabled,
+	// it is disabled by default since ingress-nginx 1.9. Values can't contain "$" as nginx would expand it as a variable.
+	// +optional
+	ResponseHeaders map[string]string `json:"responseHeaders,omitempty"`
+	// Auth requires requests to be authenticated before they are sent to the application.
+	// +optional
+	Auth *IngressAuth `json:"auth,omitempty"`
+}
+
+// IngressAuth configures authentication of requests, exactly one method must be set.
+// nginx and traefik support basic auth and forward auth, istio supports JWT.
+type IngressAuth struct {
+	// BasicAuthSecret is a name of a Secret in the app's namespace with htpasswd users,
+	// nginx reads them from the "auth" key and traefik from the "users" key.
+	// +optional
+	BasicAuthSecret string `json:"basicAuthSecret,omitempty"`
+	// ForwardAuth sends each request to an authentication service, like oauth2-proxy, before it is sent to the application.
+	// +optional
+	ForwardAuth *ForwardAuth `json:"forwardAuth,omitempty"`
+	// JWT requires requests to carry a valid JSON Web Token of the issuer.
+	// +optional
+	JWT *JWTAuth `json:"jwt,omitempty"`
+}
+
+// ForwardAuth configures an external authentication service.
+// A request is sent to the application if the service answers it with a 2xx status code.
+type ForwardAuth struct {
+	// URL of the authentication service, for example "https://oauth2-proxy.example.com/oauth2/auth".
+	URL string `json:"url"`
+	// SignInURL is where nginx redirects unauthenticated users, for example "https://oauth2-proxy.example.com/oauth2/start".
+	// Only nginx supports it.
+	// +optional
+	SignInURL string `json:"signInURL,omitempty"`
+	// ResponseHeaders are copied from the response of the authentication service to the request sent to the application.
+	// +optional
+	ResponseHeaders []string `json:"responseHeaders,omitempty"`
+}
+
+// JWTAuth configures validation of JSON Web Tokens sent in the Authorization header.
+// istio validates tokens at the ingress gateway shared by all apps,
+// so a request carrying an invalid token of the issuer is rejected for every host of the gateway,
+// and apps using the same issuer must use the same JWKSURI.
+type JWTAuth struct {
+	// Issuer of the tokens, for example "https://accounts.google.com".
+	Issuer string `json:"issuer"`
+	// JWKSURI is a URL of the public keys of the issuer.
+	JWKSURI string `json:"jwksURI"`
+	// Audiences is a list of audiences a token must be issued for, any audience is accepted if empty.
+	// +optional
+	Audiences []string `json:"audiences,omitempty"`
+}
+
+// RateLimitPolicy limits the rate of requests.
+type RateLimitPolicy struct {
+	// RequestsPerSecond is the average number of requests per second.
+	// +kubebuilder:validation:Minimum=1
+	RequestsPerSecond int32 `json:"requestsPerSecond"`
+	// Burst is the maximum number of requests allowed to exceed the rate at once, RequestsPerSecond by default.
+	// nginx requires it to be a multiple of RequestsPerSecond.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Burst int32 `json:"burst,omitempty"`
+}
+
+// EffectiveBurst returns Burst or RequestsPerSecond if Burst is not set.
+func (p RateLimitPolicy) EffectiveBurst() int32 {
+	if p.Burst > 0 {
+		return p.Burst
+	}
+	return p.RequestsPerSecond
+}
+
+// CORSPolicy configures Cross-Origin Resource Sharing.
+type CORSPolicy struct {
+	// AllowOrigins is a list of origins allowed to send cross-origin requests, "*" allows any origin.
+	// Only nginx supports wildcard subdomains like "https://*.example.com".
+	AllowOrigins []string `json:"allowOrigins"`
+	// AllowMethods is a list of methods allowed in cross-origin requests.
+	// +optional
+	AllowMethods []string `json:"allowMethods,omitempty"`
+	// AllowHeaders is a list of headers allowed in cross-origin requests.
+	// +optional
+	AllowHeaders []string `json:"allowHeaders,omitempty"`
+	// AllowCredentials allows cross-origin requests with credentials.
+	// +optional
+	AllowCredentials bool `json:"allowCredentials,omitempty"`
+	// MaxAge is the number of seconds results of a preflight request can be cached.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxAge int32 `json:"maxAge,omitempty"`
+}
+
+var headerNameRegex = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+.^_`|~-]+$")
+
+// IsEmpty returns true if no policy is set.
+func (p *IngressPolicies) IsEmpty() bool {
+	return p == nil || (p.RateLimit == nil && p.CORS == nil && len(p.IPAllowlist) == 0 && len(p.RequestHeaders) == 0 && len(p.ResponseHeaders) == 0 && p.Auth == nil)
+}
+
+// Merge returns policies of a cname: each policy set by the cname replaces the policy of the app.
+// It returns nil if neither the app nor the cname sets a policy.
+func (p *IngressPolicies) Merge(cname *IngressPolicies) *IngressPolicies {
+	if p.IsEmpty() && cname.IsEmpty() {
+		return nil
+	}
+	merged := IngressPolicies{}
+	if p != nil {
+		merged = *p.DeepCopy()
+	}
+	if cname == nil {
+		return &merged
+	}
+	if cname.RateLimit != nil {
+		merged.RateLimit = cname.RateLimit.DeepCopy()
+	}
+	if cname.CORS != nil {
+		merged.CORS = cname.CORS.DeepCopy()
+	}
+	if len(cname.IPAllowlist) > 0 {
+		merged.IPAllowlist = append([]string{}, cname.IPAllowlist...)
+	}
+	if len(cname.RequestHeaders) > 0 {
+		merged.RequestHeaders = copyHeaders(cname.RequestHeaders)
+	}
+	if len(cname.ResponseHeaders) > 0 {
+		merged.ResponseHeaders = copyHeaders(cname.ResponseHeaders)
+	}
+	if cname.Auth != nil {
+		merged.Auth = cname.Auth.DeepCopy()
+	}
+	return &merged
+}
+
+func copyHeaders(headers map[string]string) map[string]string {
+	result := make(map[string]string, len(headers))
+	for name, value := range headers {
+		result[name] = value
+	}
+	return result
+}
+
+// Validate returns an error if the policies are invalid or can't be rendered for the ingress controller type.
+// Path is a path prefix of the cname the policies are applied to, "/" if the cname doesn't have a path.
+func (p *IngressPolicies) Validate(ingressType IngressControllerType, path string) error {
+	if p.IsEmpty() {
+		return nil
+	}
+	if ingressType == GatewayAPIIngressControllerType {
+		return fmt.Errorf("ingress policies are not supported by the %s ingress controller", ingressType)
+	}
+	if p.RateLimit != nil {
+		if err := p.RateLimit.validate(ingressType, path); err != nil {
+			return err
+		}
+	}
+	if p.CORS != nil {
+		if err := p.CORS.validate(ingressType); err != nil {
+			return err
+		}
+	}
+	for _, source := range p.IPAllowlist {
+		if _, _, err := net.ParseCIDR(source); err != nil && net.ParseIP(source) == nil {
+			return fmt.Errorf("ipAllowlist: %q is neither an IP address nor a CIDR range", source)
+		}
+	}
+	if err := validateHeaders("requestHeaders", p.RequestHeaders, ingressType); err != nil {
+		return err
+	}
+	if err := validateHeaders("responseHeaders", p.ResponseHeaders, ingressType); err != nil {
+		return err
+	}
+	if p.Auth != nil {
+		return p.Auth.Validate(ingressType)
+	}
+	return nil
+}
+
+func (p RateLimitPolicy) validate(ingressType IngressControllerType, path string) error {
+	if p.RequestsPerSecond < 1 {
+		return fmt.Errorf("rateLimit: requestsPerSecond must be positive")
+	}
+	if p.Burst < 0 {
+		return fmt.Errorf("rateLimit: burst must not be negative")
+	}
+	switch ingressType {
+	case NginxIngressControllerType:
+		if p.EffectiveBurst()%p.RequestsPerSecond != 0 {
+			return fmt.Errorf("rateLimit: the %s ingress controller requires burst to be a multiple of requestsPerSecond", ingressType)
+		}
+	case IstioIngressControllerType:
+		if path != "/" {
+			return fmt.Errorf("rateLimit: the %s ingress controller limits requests of whole hosts, it can't be used with a cname path", ingressType)
+		}
+	}
+	return nil
+}
+
+func (p CORSPolicy) validate(ingressType IngressControllerType) error {
+	if len(p.AllowOrigins) == 0 {
+		return fmt.Errorf("cors: allowOrigins must not be empty")
+	}
+	for _, origin := range p.AllowOrigins {
+		if origin == "*" {
+			if p.AllowCredentials {
+				return fmt.Errorf("cors: allowCredentials can't be used with the \"*\" origin")
+			}
+			continue
+		}
+		if strings.Contains(origin, "*") && ingressType != NginxIngressControllerType {
+			return fmt.Errorf("cors: wildcard origin %q is not supported by the %s ingress controller", origin, ingressType)
+		}
+	}
+	if p.MaxAge < 0 {
+		return fmt.Errorf("cors: maxAge must not be negative")
+	}
+	return nil
+}
+
+func validateHeaders(field string, headers map[string]string, ingressType IngressControllerType) error {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !headerNameRegex.MatchString(name) {
+			return fmt.Errorf("%s: invalid header name %q", field, name)
+		}
+		value := headers[name]
+		if strings.ContainsAny(value, "\r\n\x00") {
+			return fmt.Errorf("%s: header %s has an invalid value", field, name)
+		}
+		// nginx expands variables in values of a configuration snippet, so "$" would leak request or server data.
+		if ingressType == NginxIngressControllerType && strings.ContainsAny(value, `"\$`) {
+			return fmt.Errorf("%s: the %s ingress controller doesn't support quotes, backslashes and dollar signs in the value of header %s", field, ingressType, name)
+		}
+	}
+	return nil
+}
+
+// Validate returns an error if not exactly one authentication method is set,
+// the method is invalid or it isn't supported by the ingress controller type.
+func (a IngressAuth) Validate(ingressType IngressControllerType) error {
+	methods := 0
+	if len(a.BasicAuthSecret) > 0 {
+		methods++
+		if errs := validation.IsDNS1123Subdomain(a.BasicAuthSecret); len(errs) > 0 {
+			return fmt.Errorf("auth: invalid basic auth secret name %q: %s", a.BasicAuthSecret, errs[0])
+		}
+		if ingressType == IstioIngressControllerType {
+			return fmt.Errorf("auth: basic auth is not supported by the %s ingress controller", ingressType)
+		}
+	}
+	if a.ForwardAuth != nil {
+		methods++
+		if err := a.ForwardAuth.validate(ingressType); err != nil {
+			return err
+		}
+	}
+	if a.JWT != nil {
+		methods++
+		if err := a.JWT.validate(ingressType); err != nil {
+			return err
+		}
+	}
+	if methods != 1 {
+		return fmt.Errorf("auth: exactly one of basicAuthSecret, forwardAuth and jwt must be set")
+	}
+	return nil
+}
+
+func (f ForwardAuth) validate(ingressType IngressControllerType) error {
+	if ingressType == IstioIngressControllerType {
+		return fmt.Errorf("auth: forward auth is not supported by the %s ingress controller, use jwt", ingressType)
+	}
+	if err := validateAuthURL("forwardAuth.url", f.URL); err != nil {
+		return err
+	}
+	if len(f.SignInURL) > 0 {
+		if ingressType != "" && ingressType != NginxIngressControllerType {
+			return fmt.Errorf("auth: forwardAuth.signInURL is not supported by the %s ingress controller", ingressType)
+		}
+		if err := validateAuthURL("forwardAuth.signInURL", f.SignInURL); err != nil {
+			return err
+		}
+	}
+	for _, name := range f.ResponseHeaders {
+		if !headerNameRegex.MatchString(name) {
+			return fmt.Errorf("auth: forwardAuth.responseHeaders: invalid header name %q", name)
+		}
+	}
+	return nil
+}
+
+func (j JWTAuth) validate(ingressType IngressControllerType) error {
+	if ingressType != "" && ingressType != IstioIngressControllerType {
+		return fmt.Errorf("auth: jwt is not supported by the %s ingress controller, use forwardAuth", ingressType)
+	}
+	if len(j.Issuer) == 0 {
+		return fmt.Errorf("auth: jwt.issuer must not be empty")
+	}
+	return validateAuthURL("jwt.jwksURI", j.JWKSURI)
+}
+
+func validateAuthURL(field, value string) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || len(u.Host) == 0 {
+		return fmt.Errorf("auth: %s must be an absolute http or https URL, got %q", field, value)
+	}
+	if strings.ContainsAny(value, `"\ `) {
+		return fmt.Errorf("auth: %s must not contain quotes, backslashes or spaces", field)
+	}
+	return nil
+}
@@ -0,0 +1,295 @@
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngressPolicies_Merge(t *testing.T) {
+	appPolicies := &IngressPolicies{
+		RateLimit:       &RateLimitPolicy{RequestsPerSecond: 10},
+		IPAllowlist:     []string{"10.0.0.0/8"},
+		ResponseHeaders: map[string]string{"X-Frame-Options": "DENY"},
+	}
+	tests := []struct {
+		name  string
+		app   *IngressPolicies
+		cname *IngressPolicies
+		want  *IngressPolicies
+	}{
+		{
+			name: "no policies",
+		},
+		{
+			name: "empty policies",
+			app:  &IngressPolicies{},
+		},
+		{
+			name: "app policies",
+			app:  appPolicies,
+			want: appPolicies,
+		},
+		{
+			name: "cname policies",
+			cname: &IngressPolicies{
+				CORS: &CORSPolicy{AllowOrigins: []string{"*"}},
+			},
+			want: &IngressPolicies{
+				CORS: &CORSPolicy{AllowOrigins: []string{"*"}},
+			},
+		},
+		{
+			name: "cname policies replace app policies",
+			app:  appPolicies,
+			cname: &IngressPolicies{
+				RateLimit:      &RateLimitPolicy{RequestsPerSecond: 5, Burst: 10},
+				RequestHeaders: map[string]string{"X-Environment": "production"},
+				Auth:           &IngressAuth{BasicAuthSecret: "tools-users"},
+			},
+			want: &IngressPolicies{
+				RateLimit:       &RateLimitPolicy{RequestsPerSecond: 5, Burst: 10},
+				IPAllowlist:     []string{"10.0.0.0/8"},
+				RequestHeaders:  map[string]string{"X-Environment": "production"},
+				ResponseHeaders: map[string]string{"X-Frame-Options": "DENY"},
+				Auth:            &IngressAuth{BasicAuthSecret: "tools-users"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.app.Merge(tt.cname)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestIngressPolicies_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		policies    *IngressPolicies
+		ingressType IngressControllerType
+		path        string
+		wantErr     string
+	}{
+		{
+			name:        "no policies",
+			ingressType: GatewayAPIIngressControllerType,
+			path:        "/",
+		},
+		{
+			name: "valid policies",
+			policies: &IngressPolicies{
+				RateLimit:       &RateLimitPolicy{RequestsPerSecond: 10, Burst: 30},
+				CORS:            &CORSPolicy{AllowOrigins: []string{"https://theketch.io"}, AllowCredentials: true, MaxAge: 600},
+				IPAllowlist:     []string{"10.0.0.0/8", "192.168.1.10", "2001:db8::/32"},
+				RequestHeaders:  map[string]string{"X-Environment": "production"},
+				ResponseHeaders: map[string]string{"X-Frame-Options": "DENY"},
+			},
+			ingressType: NginxIngressControllerType,
+			path:        "/",
+		},
+		{
+			name:        "gateway api",
+			policies:    &IngressPolicies{IPAllowlist: []string{"10.0.0.0/8"}},
+			ingressType: GatewayAPIIngressControllerType,
+			path:        "/",
+			wantErr:     "ingress policies are not supported by the gateway-api ingress controller",
+		},
+		{
+			name:        "invalid rate",
+			policies:    &IngressPolicies{RateLimit: &RateLimitPolicy{}},
+			ingressType: TraefikIngressControllerType,
+			path:        "/",
+			wantErr:     "rateLimit: requestsPerSecond must be positive",
+		},
+		{
+			name:        "nginx burst",
+			policies:    &IngressPolicies{RateLimit: &RateLimitPolicy{RequestsPerSecond: 10, Burst: 15}},
+			ingressType: NginxIngressControllerType,
+			path:        "/",
+			wantErr:     "rateLimit: the nginx ingress controller requires burst to be a multiple of requestsPerSecond",
+		},
+		{
+			name:        "traefik burst",
+			policies:    &IngressPolicies{RateLimit: &RateLimitPolicy{RequestsPerSecond: 10, Burst: 15}},
+			ingressType: TraefikIngressControllerType,
+			path:        "/orders",
+		},
+		{
+			name:        "istio rate limit of a cname path",
+			policies:    &IngressPolicies{RateLimit: &RateLimitPolicy{RequestsPerSecond: 10}},
+			ingressType: IstioIngressControllerType,
+			path:        "/orders",
+			wantErr:     "rateLimit: the istio ingress controller limits requests of whole hosts, it can't be used with a cname path",
+		},
+		{
+			name:        "cors without origins",
+			policies:    &IngressPolicies{CORS: &CORSPolicy{AllowMethods: []string{"GET"}}},
+			ingressType: NginxIngressControllerType,
+			path:        "/",
+			wantErr:     "cors: allowOrigins must not be empty",
+		},
+		{
+			name:        "cors credentials with any origin",
+			policies:    &IngressPolicies{CORS: &CORSPolicy{AllowOrigins: []string{"*"}, AllowCredentials: true}},
+			ingressType: NginxIngressControllerType,
+			path:        "/",
+			wantErr:     `cors: allowCredentials can't be used with the "*" origin`,
+		},
+		{
+			name:        "nginx wildcard origin",
+			policies:    &IngressPolicies{CORS: &CORSPolicy{AllowOrigins: []string{"https://*.theketch.io"}}},
+			ingressType: NginxIngressControllerType,
+			path:        "/",
+		},
+		{
+			name:        "traefik wildcard origin",
+			policies:    &IngressPolicies{CORS: &CORSPolicy{AllowOrigins: []string{"https://*.theketch.io"}}},
+			ingressType: TraefikIngressControllerType,
+			path:        "/",
+			wantErr:     `cors: wildcard origin "https://*.theketch.io" is not supported by the traefik ingress controller`,
+		},
+		{
+			name:        "invalid ip",
+			policies:    &IngressPolicies{IPAllowlist: []string{"10.0.0.256"}},
+			ingressType: IstioIngressControllerType,
+			path:        "/",
+			wantErr:     `ipAllowlist: "10.0.0.256" is neither an IP address nor a CIDR range`,
+		},
+		{
+			name:        "invalid header name",
+			policies:    &IngressPolicies{RequestHeaders: map[string]string{"X Environment": "production"}},
+			ingressType: IstioIngressControllerType,
+			path:        "/",
+			wantErr:     `requestHeaders: invalid header name "X Environment"`,
+		},
+		{
+			name:        "invalid header value",
+			policies:    &IngressPolicies{ResponseHeaders: map[string]string{"X-Environment": "production\r\nX-Injected: true"}},
+			ingressType: TraefikIngressControllerType,
+			path:        "/",
+			wantErr:     "responseHeaders: header X-Environment has an invalid value",
+		},
+		{
+			name:        "nginx header value with quotes",
+			policies:    &IngressPolicies{ResponseHeaders: map[string]string{"Content-Security-Policy": `default-src "self"`}},
+			ingressType: NginxIngressControllerType,
+			path:        "/",
+			wantErr:     "responseHeaders: the nginx ingress controller doesn't support quotes, backslashes and dollar signs in the value of header Content-Security-Policy",
+		},
+		{
+			name:        "nginx header value with a variable",
+			policies:    &IngressPolicies{RequestHeaders: map[string]string{"X-Forwarded-Secret": "$ssl_client_cert"}},
+			ingressType: NginxIngressControllerType,
+			path:        "/",
+			wantErr:     "requestHeaders: the nginx ingress controller doesn't support quotes, backslashes and dollar signs in the value of header X-Forwarded-Secret",
+		},
+		{
+			name:        "traefik header value with a dollar sign",
+			policies:    &IngressPolicies{ResponseHeaders: map[string]string{"X-Price": "$10"}},
+			ingressType: TraefikIngressControllerType,
+			path:        "/",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policies.Validate(tt.ingressType, tt.path)
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+		})
+	}
+}
+
+func TestIngressAuth_Validate(t *testing.T) {
+	forwardAuth := &ForwardAuth{URL: "https://oauth2.theketch.io/oauth2/auth", SignInURL: "https://oauth2.theketch.io/oauth2/start"}
+	jwt := &JWTAuth{Issuer: "https://accounts.theketch.io", JWKSURI: "https://accounts.theketch.io/.well-known/jwks.json"}
+	tests := []struct {
+		name        string
+		auth        IngressAuth
+		ingressType IngressControllerType
+		wantErr     string
+	}{
+		{
+			name:        "basic auth",
+			auth:        IngressAuth{BasicAuthSecret: "tools-users"},
+			ingressType: TraefikIngressControllerType,
+		},
+		{
+			name:        "forward auth",
+			auth:        IngressAuth{ForwardAuth: forwardAuth},
+			ingressType: NginxIngressControllerType,
+		},
+		{
+			name:        "jwt",
+			auth:        IngressAuth{JWT: jwt},
+			ingressType: IstioIngressControllerType,
+		},
+		{
+			name:        "no method",
+			ingressType: NginxIngressControllerType,
+			wantErr:     "auth: exactly one of basicAuthSecret, forwardAuth and jwt must be set",
+		},
+		{
+			name:        "two methods",
+			auth:        IngressAuth{BasicAuthSecret: "tools-users", ForwardAuth: forwardAuth},
+			ingressType: NginxIngressControllerType,
+			wantErr:     "auth: exactly one of basicAuthSecret, forwardAuth and jwt must be set",
+		},
+		{
+			name:        "invalid secret name",
+			auth:        IngressAuth{BasicAuthSecret: "Tools_Users"},
+			ingressType: NginxIngressControllerType,
+			wantErr:     `auth: invalid basic auth secret name "Tools_Users": a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')`,
+		},
+		{
+			name:        "basic auth with istio",
+			auth:        IngressAuth{BasicAuthSecret: "tools-users"},
+			ingressType: IstioIngressControllerType,
+			wantErr:     "auth: basic auth is not supported by the istio ingress controller",
+		},
+		{
+			name:        "relative forward auth url",
+			auth:        IngressAuth{ForwardAuth: &ForwardAuth{URL: "/oauth2/auth"}},
+			ingressType: NginxIngressControllerType,
+			wantErr:     `auth: forwardAuth.url must be an absolute http or https URL, got "/oauth2/auth"`,
+		},
+		{
+			name:        "sign in url with traefik",
+			auth:        IngressAuth{ForwardAuth: forwardAuth},
+			ingressType: TraefikIngressControllerType,
+			wantErr:     "auth: forwardAuth.signInURL is not supported by the traefik ingress controller",
+		},
+		{
+			name:        "forward auth with istio",
+			auth:        IngressAuth{ForwardAuth: forwardAuth},
+			ingressType: IstioIngressControllerType,
+			wantErr:     "auth: forward auth is not supported by the istio ingress controller, use jwt",
+		},
+		{
+			name:        "jwt with traefik",
+			auth:        IngressAuth{JWT: jwt},
+			ingressType: TraefikIngressControllerType,
+			wantErr:     "auth: jwt is not supported by the traefik ingress controller, use forwardAuth",
+		},
+		{
+			name:        "jwt without issuer",
+			auth:        IngressAuth{JWT: &JWTAuth{JWKSURI: jwt.JWKSURI}},
+			ingressType: IstioIngressControllerType,
+			wantErr:     "auth: jwt.issuer must not be empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.auth.Validate(tt.ingressType)
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestIngressProfileFromConfigmapName(t *testing.T) {
+	tests := []struct {
+		name        string
+		configmap   string
+		wantProfile string
+		wantOk      bool
+	}{
+		{
+			name:      "default profile",
+			configmap: "ketch-ingress",
+			wantOk:    true,
+		},
+		{
+			name:        "named profile",
+			configmap:   "ketch-ingress-internal",
+			wantProfile: "internal",
+			wantOk:      true,
+		},
+		{
+			name:      "empty profile name",
+			configmap: "ketch-ingress-",
+		},
+		{
+			name:      "not an ingress configmap",
+			configmap: "ingress-nginx-templates",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, ok := IngressProfileFromConfigmapName(tt.configmap)
+			require.Equal(t, tt.wantOk, ok)
+			require.Equal(t, tt.wantProfile, profile)
+			if ok {
+				require.Equal(t, tt.configmap, IngressProfileConfigmapName(profile))
+			}
+		})
+	}
+}
+
+func TestNewIngressControllerSpec(t *testing.T) {
+	configmap := v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "ketch-ingress-internal", Namespace: IngressConfigmapNamespace},
+		Data: map[string]string{
+			"className":       "nginx-internal",
+			"serviceEndpoint": "10.0.0.1",
+			"ingressType":     "nginx",
+		},
+	}
+	want := &IngressControllerSpec{
+		ClassName:       "nginx-internal",
+		ServiceEndpoint: "10.0.0.1",
+		IngressType:     NginxIngressControllerType,
+		Profile:         "internal",
+	}
+	require.Equal(t, want, NewIngressControllerSpec(configmap))
+
+	configmap = v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: IngressConfigmapName, Namespace: IngressConfigmapNamespace},
+		Data: map[string]string{
+			"className":        "envoy-gateway",
+			"serviceEndpoint":  "10.0.0.2",
+			"ingressType":      "gateway-api",
+			"gatewayName":      "ketch",
+			"gatewayNamespace": "gateway-system",
+		},
+	}
+	want = &IngressControllerSpec{
+		ClassName:        "envoy-gateway",
+		ServiceEndpoint:  "10.0.0.2",
+		IngressType:      GatewayAPIIngressControllerType,
+		GatewayName:      "ketch",
+		GatewayNamespace: "gateway-system",
+	}
+	require.Equal(t, want, NewIngressControllerSpec(configmap))
+}
+
+func TestValidateIngressProfile(t *testing.T) {
+	require.Nil(t, ValidateIngressProfile(""))
+	require.Nil(t, ValidateIngressProfile("internal"))
+	require.EqualError(t, ValidateIngressProfile("Internal"), `invalid ingress profile "Internal": a lowercase RFC 1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name',  or '123-abc', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?')`)
+}
+
+func TestValidateDefaultCnameTemplate(t *testing.T) {
+	require.Nil(t, ValidateDefaultCnameTemplate("{{.App}}.{{.Namespace}}.apps.example.com"))
+	require.Nil(t, ValidateDefaultCnameTemplate("{{.App}}.{{.ServiceEndpoint}}.sslip.io"))
+	require.EqualError(t, ValidateDefaultCnameTemplate("{{.App}.apps.example.com"), `invalid default cname template: template: default-cname:1: bad character U+007D '}'`)
+	require.EqualError(t, ValidateDefaultCnameTemplate("{{.Name}}.apps.example.com"), `invalid default cname template: template: default-cname:1:2: executing "default-cname" at <.Name>: can't evaluate field Name in type v1beta1.defaultCnameData`)
+	require.EqualError(t, ValidateDefaultCnameTemplate("{{.App}}_apps.example.com"), `invalid default cname "app_apps.example.com": a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')`)
+}
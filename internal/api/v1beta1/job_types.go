@@ -43,6 +43,29 @@ type JobSpec struct {
 	FailedJobsHistoryLimit     *int   `json:"failedJobsHistoryLimit,omitempty"`
 }
 
+const (
+	defaultJobVersion       = "v1"
+	defaultJobParallelism   = 1
+	defaultJobRestartPolicy = "Never"
+)
+
+// SetDefaults sets defaults on unset fields of the job's spec.
+func (s *JobSpec) SetDefaults() {
+	s.Type = "Job"
+	if s.Version == "" {
+		s.Version = defaultJobVersion
+	}
+	if s.Parallelism == 0 {
+		s.Parallelism = defaultJobParallelism
+	}
+	if s.Completions == 0 {
+		s.Completions = s.Parallelism
+	}
+	if s.Policy.RestartPolicy == "" {
+		s.Policy.RestartPolicy = defaultJobRestartPolicy
+	}
+}
+
 // JobStatus defines the observed state of Job
 type JobStatus struct {
 	Conditions []Condition `json:"conditions,omitempty"`
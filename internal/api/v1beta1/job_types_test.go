@@ -70,3 +70,47 @@ func TestSetCondition(t *testing.T) {
 	j.SetCondition("type2", v1.ConditionStatus("failed"), "message-2", now)
 	require.Equal(t, expected, j)
 }
+
+func TestJobSpec_SetDefaults(t *testing.T) {
+	tests := []struct {
+		description string
+		spec        JobSpec
+		expected    JobSpec
+	}{
+		{
+			description: "empty spec",
+			spec:        JobSpec{Name: "hello"},
+			expected: JobSpec{
+				Name:        "hello",
+				Type:        "Job",
+				Version:     "v1",
+				Parallelism: 1,
+				Completions: 1,
+				Policy:      Policy{RestartPolicy: "Never"},
+			},
+		},
+		{
+			description: "completions default to parallelism",
+			spec: JobSpec{
+				Name:        "hello",
+				Version:     "v2",
+				Parallelism: 3,
+				Policy:      Policy{RestartPolicy: "OnFailure"},
+			},
+			expected: JobSpec{
+				Name:        "hello",
+				Type:        "Job",
+				Version:     "v2",
+				Parallelism: 3,
+				Completions: 3,
+				Policy:      Policy{RestartPolicy: "OnFailure"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			tt.spec.SetDefaults()
+			require.Equal(t, tt.expected, tt.spec)
+		})
+	}
+}
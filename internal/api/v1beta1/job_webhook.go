@@ -44,6 +44,16 @@ func (r *Job) SetupWebhookWithManager(mgr ctrl.Manager) error {
 		Complete()
 }
 
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-theketch-io-v1beta1-job,mutating=true,failurePolicy=fail,groups=theketch.io,resources=jobs,versions=v1beta1,name=mjob.kb.io,sideEffects=none,admissionReviewVersions=v1beta1
+
+var _ webhook.Defaulter = &Job{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *Job) Default() {
+	joblog.Info("default", "name", r.Name)
+	r.Spec.SetDefaults()
+}
+
 // +kubebuilder:webhook:verbs=create;update;delete,path=/validate-theketch-io-v1beta1-job,mutating=false,failurePolicy=fail,groups=theketch.io,resources=jobs,versions=v1beta1,name=vjob.kb.io,sideEffects=none,admissionReviewVersions=v1beta1
 
 var _ webhook.Validator = &Job{}
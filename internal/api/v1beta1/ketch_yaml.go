@@ -1,6 +1,10 @@
 package v1beta1
 
-import v1 "k8s.io/api/core/v1"
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+)
 
 // KetchYamlData describes certain aspects of the application deployment being deployed.
 type KetchYamlData struct {
@@ -9,6 +13,7 @@ type KetchYamlData struct {
 	Hooks *KetchYamlHooks `json:"hooks,omitempty"`
 
 	// Healthcheck describes readiness and liveness probes of the application deployment.
+	// It is used for every process that doesn't have its own healthcheck in kubernetes.processes.
 	Healthcheck *KetchYamlHealthcheck `json:"healthcheck,omitempty"`
 
 	// Kubernetes contains specific configurations for Kubernetes.
@@ -67,6 +72,16 @@ type KetchYamlKubernetesConfig struct {
 // KetchYamlKubernetesConfig contains specific configurations of a process.
 type KetchYamlProcessConfig struct {
 	Ports []KetchYamlProcessPortConfig `json:"ports,omitempty"`
+
+	// Strategy configures how pods of the process are replaced during a rollout.
+	Strategy *RolloutStrategy `json:"strategy,omitempty"`
+
+	// Healthcheck describes probes of the process.
+	// When set, it replaces the app-wide healthcheck, an empty healthcheck disables probes of the process.
+	Healthcheck *KetchYamlHealthcheck `json:"healthcheck,omitempty"`
+
+	// Exposure configures how the process is made reachable.
+	Exposure *ExposureSpec `json:"exposure,omitempty"`
 }
 
 // KetchYamlKubernetesConfig contains configuration of an exposed port.
@@ -82,4 +97,24 @@ type KetchYamlProcessPortConfig struct {
 
 	// TargetPort is the port that the process is listening on. If omitted, the port value is used.
 	TargetPort int `json:"target_port,omitempty"`
+
+	// AppProtocol is an application protocol the process speaks on the port, http if omitted.
+	// The ingress controller uses it to proxy HTTP/2, gRPC and WebSocket requests.
+	// +kubebuilder:validation:Enum=http;http2;grpc;grpcs;websocket
+	AppProtocol AppProtocol `json:"app_protocol,omitempty"`
+
+	// InsecureSkipVerify turns off verification of the certificate of a grpcs port by traefik and istio,
+	// set it if the process uses a self-signed certificate. nginx doesn't verify certificates of applications.
+	InsecureSkipVerify bool `json:"insecure_skip_verify,omitempty"`
+}
+
+// Validate returns an error if the application protocol is unknown or InsecureSkipVerify is set on a port without TLS.
+func (c KetchYamlProcessPortConfig) Validate() error {
+	if err := c.AppProtocol.Validate(); err != nil {
+		return err
+	}
+	if c.InsecureSkipVerify && c.AppProtocol != GRPCSAppProtocol {
+		return fmt.Errorf("insecure_skip_verify requires the %s application protocol", GRPCSAppProtocol)
+	}
+	return nil
 }
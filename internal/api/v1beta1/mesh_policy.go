@@ -0,0 +1,190 @@
+package v1beta1
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// MeshPolicy configures how the istio service mesh sends requests to an application.
+// It is rendered into the DestinationRules and VirtualServices of the application,
+// so it can be used only with the istio ingress controller.
+type MeshPolicy struct {
+	// Retries configures retries of failed requests.
+	// +optional
+	Retries *RetryPolicy `json:"retries,omitempty"`
+	// RequestTimeoutSeconds is how long istio waits for a response of the application, including retries.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	RequestTimeoutSeconds int32 `json:"requestTimeoutSeconds,omitempty"`
+	// OutlierDetection ejects pods returning errors from the load balancing pool.
+	// +optional
+	OutlierDetection *OutlierDetectionPolicy `json:"outlierDetection,omitempty"`
+	// ConnectionPool limits connections and requests to the application, requests over the limits fail right away.
+	// +optional
+	ConnectionPool *ConnectionPoolPolicy `json:"connectionPool,omitempty"`
+	// FaultInjection delays or aborts a percentage of requests to test how clients handle failures.
+	// +optional
+	FaultInjection *FaultInjectionPolicy `json:"faultInjection,omitempty"`
+}
+
+// RetryPolicy configures retries of failed requests.
+type RetryPolicy struct {
+	// Attempts is the maximum number of retries of a request.
+	// +kubebuilder:validation:Minimum=0
+	Attempts int32 `json:"attempts"`
+	// PerTryTimeoutSeconds is a timeout of each attempt, the request timeout by default.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	PerTryTimeoutSeconds int32 `json:"perTryTimeoutSeconds,omitempty"`
+	// RetryOn is a list of conditions a request is retried on, for example "5xx", "gateway-error" or "connect-failure".
+	// Istio's defaults are used if empty.
+	// +optional
+	RetryOn []string `json:"retryOn,omitempty"`
+}
+
+// OutlierDetectionPolicy configures circuit breaking of pods returning errors.
+type OutlierDetectionPolicy struct {
+	// Consecutive5xxErrors is the number of 5xx responses in a row after which a pod is ejected.
+	// +kubebuilder:validation:Minimum=1
+	Consecutive5xxErrors int32 `json:"consecutive5xxErrors"`
+	// IntervalSeconds is how often pods are checked, 10 seconds by default.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	IntervalSeconds int32 `json:"intervalSeconds,omitempty"`
+	// BaseEjectionTimeSeconds is the minimum time a pod stays ejected, 30 seconds by default.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	BaseEjectionTimeSeconds int32 `json:"baseEjectionTimeSeconds,omitempty"`
+	// MaxEjectionPercent is the maximum percentage of pods that can be ejected at once, 10 by default.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	MaxEjectionPercent int32 `json:"maxEjectionPercent,omitempty"`
+}
+
+// ConnectionPoolPolicy limits connections and requests to each deployment of the application.
+type ConnectionPoolPolicy struct {
+	// MaxConnections is the maximum number of TCP connections.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxConnections int32 `json:"maxConnections,omitempty"`
+	// MaxPendingRequests is the maximum number of requests waiting for a connection.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxPendingRequests int32 `json:"maxPendingRequests,omitempty"`
+	// MaxRequestsPerConnection is the maximum number of requests sent over a connection before it is closed.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxRequestsPerConnection int32 `json:"maxRequestsPerConnection,omitempty"`
+}
+
+// FaultInjectionPolicy configures delays and aborts of requests.
+// It is meant for chaos testing, so nothing is injected unless Enabled is set.
+// Istio ignores timeouts and retries of a route with a fault,
+// so an enabled fault injection can't be combined with Retries and RequestTimeoutSeconds.
+type FaultInjectionPolicy struct {
+	// Enabled turns the fault injection on.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+	// Delay delays a percentage of requests.
+	// +optional
+	Delay *FaultDelay `json:"delay,omitempty"`
+	// Abort responds to a percentage of requests with an error without sending them to the application.
+	// +optional
+	Abort *FaultAbort `json:"abort,omitempty"`
+}
+
+// FaultDelay delays a percentage of requests.
+type FaultDelay struct {
+	// Percentage of requests to delay.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Percentage int32 `json:"percentage"`
+	// FixedDelaySeconds is how long a request is delayed.
+	// +kubebuilder:validation:Minimum=1
+	FixedDelaySeconds int32 `json:"fixedDelaySeconds"`
+}
+
+// FaultAbort responds to a percentage of requests with an HTTP status code.
+type FaultAbort struct {
+	// Percentage of requests to abort.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Percentage int32 `json:"percentage"`
+	// HTTPStatus is a 4xx or 5xx status code of responses to aborted requests.
+	// +kubebuilder:validation:Minimum=400
+	// +kubebuilder:validation:Maximum=599
+	HTTPStatus int32 `json:"httpStatus"`
+}
+
+// Validate returns an error if the policy is invalid or the ingress controller is not istio.
+// An empty ingressType skips the check of the ingress controller.
+func (p *MeshPolicy) Validate(ingressType IngressControllerType) error {
+	if p == nil {
+		return nil
+	}
+	if len(ingressType) > 0 && ingressType != IstioIngressControllerType {
+		return fmt.Errorf("mesh policy: it is supported only by the %s ingress controller, the app uses %s", IstioIngressControllerType, ingressType)
+	}
+	if p.RequestTimeoutSeconds < 0 {
+		return fmt.Errorf("mesh policy: invalid request timeout %d", p.RequestTimeoutSeconds)
+	}
+	if p.Retries != nil {
+		if p.Retries.Attempts < 0 {
+			return fmt.Errorf("mesh policy: retries: invalid number of attempts %d", p.Retries.Attempts)
+		}
+		if p.Retries.PerTryTimeoutSeconds < 0 {
+			return fmt.Errorf("mesh policy: retries: invalid per try timeout %d", p.Retries.PerTryTimeoutSeconds)
+		}
+		if p.RequestTimeoutSeconds > 0 && p.Retries.PerTryTimeoutSeconds > p.RequestTimeoutSeconds {
+			return fmt.Errorf("mesh policy: retries: per try timeout %ds exceeds the request timeout %ds", p.Retries.PerTryTimeoutSeconds, p.RequestTimeoutSeconds)
+		}
+		for _, condition := range p.Retries.RetryOn {
+			if len(condition) == 0 || strings.ContainsAny(condition, ", ") {
+				return fmt.Errorf("mesh policy: retries: invalid retry condition %q", condition)
+			}
+		}
+	}
+	if o := p.OutlierDetection; o != nil {
+		if o.Consecutive5xxErrors < 1 {
+			return fmt.Errorf("mesh policy: outlierDetection: consecutive5xxErrors must be positive")
+		}
+		if o.IntervalSeconds < 0 || o.BaseEjectionTimeSeconds < 0 {
+			return fmt.Errorf("mesh policy: outlierDetection: intervals can't be negative")
+		}
+		if o.MaxEjectionPercent < 0 || o.MaxEjectionPercent > 100 {
+			return fmt.Errorf("mesh policy: outlierDetection: invalid maxEjectionPercent %d", o.MaxEjectionPercent)
+		}
+	}
+	if c := p.ConnectionPool; c != nil {
+		if c.MaxConnections < 0 || c.MaxPendingRequests < 0 || c.MaxRequestsPerConnection < 0 {
+			return fmt.Errorf("mesh policy: connectionPool: limits can't be negative")
+		}
+	}
+	if f := p.FaultInjection; f != nil {
+		if f.Enabled && (p.Retries != nil || p.RequestTimeoutSeconds > 0) {
+			return fmt.Errorf("mesh policy: faultInjection: it can't be enabled together with retries or requestTimeoutSeconds, istio ignores them on routes with a fault")
+		}
+		if f.Delay == nil && f.Abort == nil {
+			return fmt.Errorf("mesh policy: faultInjection: either delay or abort is required")
+		}
+		if f.Delay != nil {
+			if f.Delay.Percentage < 1 || f.Delay.Percentage > 100 {
+				return fmt.Errorf("mesh policy: faultInjection: invalid delay percentage %d", f.Delay.Percentage)
+			}
+			if f.Delay.FixedDelaySeconds < 1 {
+				return fmt.Errorf("mesh policy: faultInjection: fixedDelaySeconds must be positive")
+			}
+		}
+		if f.Abort != nil {
+			if f.Abort.Percentage < 1 || f.Abort.Percentage > 100 {
+				return fmt.Errorf("mesh policy: faultInjection: invalid abort percentage %d", f.Abort.Percentage)
+			}
+			if len(http.StatusText(int(f.Abort.HTTPStatus))) == 0 || f.Abort.HTTPStatus < 400 {
+				return fmt.Errorf("mesh policy: faultInjection: invalid abort status %d", f.Abort.HTTPStatus)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeshPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      *MeshPolicy
+		ingressType IngressControllerType
+		wantErr     string
+	}{
+		{
+			name:        "no policy",
+			ingressType: NginxIngressControllerType,
+		},
+		{
+			name: "all policies",
+			policy: &MeshPolicy{
+				Retries:               &RetryPolicy{Attempts: 3, PerTryTimeoutSeconds: 2, RetryOn: []string{"5xx", "connect-failure"}},
+				RequestTimeoutSeconds: 10,
+				OutlierDetection:      &OutlierDetectionPolicy{Consecutive5xxErrors: 5, IntervalSeconds: 10, BaseEjectionTimeSeconds: 30, MaxEjectionPercent: 50},
+				ConnectionPool:        &ConnectionPoolPolicy{MaxConnections: 100, MaxPendingRequests: 10, MaxRequestsPerConnection: 1},
+				FaultInjection: &FaultInjectionPolicy{
+					Delay: &FaultDelay{Percentage: 10, FixedDelaySeconds: 5},
+					Abort: &FaultAbort{Percentage: 5, HTTPStatus: 503},
+				},
+			},
+			ingressType: IstioIngressControllerType,
+		},
+		{
+			name:   "unknown ingress controller",
+			policy: &MeshPolicy{RequestTimeoutSeconds: 10},
+		},
+		{
+			name:        "nginx",
+			policy:      &MeshPolicy{RequestTimeoutSeconds: 10},
+			ingressType: NginxIngressControllerType,
+			wantErr:     "mesh policy: it is supported only by the istio ingress controller, the app uses nginx",
+		},
+		{
+			name: "per try timeout exceeds the request timeout",
+			policy: &MeshPolicy{
+				Retries:               &RetryPolicy{Attempts: 3, PerTryTimeoutSeconds: 20},
+				RequestTimeoutSeconds: 10,
+			},
+			ingressType: IstioIngressControllerType,
+			wantErr:     "mesh policy: retries: per try timeout 20s exceeds the request timeout 10s",
+		},
+		{
+			name:        "invalid retry condition",
+			policy:      &MeshPolicy{Retries: &RetryPolicy{Attempts: 3, RetryOn: []string{"5xx,reset"}}},
+			ingressType: IstioIngressControllerType,
+			wantErr:     `mesh policy: retries: invalid retry condition "5xx,reset"`,
+		},
+		{
+			name:        "outlier detection without errors",
+			policy:      &MeshPolicy{OutlierDetection: &OutlierDetectionPolicy{IntervalSeconds: 10}},
+			ingressType: IstioIngressControllerType,
+			wantErr:     "mesh policy: outlierDetection: consecutive5xxErrors must be positive",
+		},
+		{
+			name:        "empty fault injection",
+			policy:      &MeshPolicy{FaultInjection: &FaultInjectionPolicy{Enabled: true}},
+			ingressType: IstioIngressControllerType,
+			wantErr:     "mesh policy: faultInjection: either delay or abort is required",
+		},
+		{
+			name:        "invalid abort status",
+			policy:      &MeshPolicy{FaultInjection: &FaultInjectionPolicy{Abort: &FaultAbort{Percentage: 5, HTTPStatus: 777}}},
+			ingressType: IstioIngressControllerType,
+			wantErr:     "mesh policy: faultInjection: invalid abort status 777",
+		},
+		{
+			name:        "successful abort status",
+			policy:      &MeshPolicy{FaultInjection: &FaultInjectionPolicy{Abort: &FaultAbort{Percentage: 5, HTTPStatus: 204}}},
+			ingressType: IstioIngressControllerType,
+			wantErr:     "mesh policy: faultInjection: invalid abort status 204",
+		},
+		{
+			name: "enabled fault injection with retries",
+			policy: &MeshPolicy{
+				Retries:        &RetryPolicy{Attempts: 3},
+				FaultInjection: &FaultInjectionPolicy{Enabled: true, Abort: &FaultAbort{Percentage: 5, HTTPStatus: 503}},
+			},
+			ingressType: IstioIngressControllerType,
+			wantErr:     "mesh policy: faultInjection: it can't be enabled together with retries or requestTimeoutSeconds, istio ignores them on routes with a fault",
+		},
+		{
+			name: "enabled fault injection with a request timeout",
+			policy: &MeshPolicy{
+				RequestTimeoutSeconds: 10,
+				FaultInjection:        &FaultInjectionPolicy{Enabled: true, Delay: &FaultDelay{Percentage: 10, FixedDelaySeconds: 5}},
+			},
+			ingressType: IstioIngressControllerType,
+			wantErr:     "mesh policy: faultInjection: it can't be enabled together with retries or requestTimeoutSeconds, istio ignores them on routes with a fault",
+		},
+		{
+			name: "enabled fault injection",
+			policy: &MeshPolicy{
+				OutlierDetection: &OutlierDetectionPolicy{Consecutive5xxErrors: 5},
+				FaultInjection:   &FaultInjectionPolicy{Enabled: true, Abort: &FaultAbort{Percentage: 5, HTTPStatus: 503}},
+			},
+			ingressType: IstioIngressControllerType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate(tt.ingressType)
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+		})
+	}
+}
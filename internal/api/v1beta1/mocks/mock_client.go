@@ -11,6 +11,7 @@ import (
 
 type MockClient struct {
 	OnList func(ctx context.Context, list runtime.Object, opts ...client.ListOption) error
+	OnGet  func(ctx context.Context, key client.ObjectKey, obj client.Object) error
 }
 
 func (m MockClient) SubResource(subResource string) client.SubResourceClient {
@@ -29,6 +30,9 @@ func (m MockClient) IsObjectNamespaced(obj runtime.Object) (bool, error) {
 }
 
 func (m MockClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if m.OnGet != nil {
+		return m.OnGet(ctx, key, obj)
+	}
 	panic("implement me")
 }
 
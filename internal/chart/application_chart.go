@@ -15,6 +15,7 @@ import (
 	"helm.sh/helm/v3/pkg/chartutil"
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/yaml"
 
 	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
@@ -65,6 +66,17 @@ type app struct {
 	VolumeClaimTemplates []ketchv1.PersistentVolumeClaim `json:"volumeClaimTemplates,omitempty"`
 	// Type specifies whether the app should be a deployment or a statefulset
 	Type ketchv1.AppType `json:"type"`
+	// PodDisruptionBudgets is a list of PodDisruptionBudgets, one per process, covering all deployment versions.
+	PodDisruptionBudgets []podDisruptionBudget `json:"podDisruptionBudgets,omitempty"`
+	// ExposedServices is a list of Services of processes exposed with an exposure mode.
+	ExposedServices []exposedService `json:"exposedServices,omitempty"`
+}
+
+// podDisruptionBudget contains values for populating the pod_disruption_budget.yaml.
+type podDisruptionBudget struct {
+	Process        string              `json:"process"`
+	MinAvailable   *intstr.IntOrString `json:"minAvailable,omitempty"`
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
 }
 
 type deployment struct {
@@ -83,6 +95,8 @@ type Options struct {
 	ExposedPorts map[ketchv1.DeploymentVersion][]ketchv1.ExposedPort
 	Templates    templates.Templates
 	HPAMap       map[string]autoscalingv2.HorizontalPodAutoscaler
+	// TraefikAPIGroup overrides an API group of traefik resources, it is used when an app's ingress controller doesn't set it.
+	TraefikAPIGroup string
 }
 
 func WithExposedPorts(ports map[ketchv1.DeploymentVersion][]ketchv1.ExposedPort) Option {
@@ -110,6 +124,23 @@ func WithHPAMap(hpaMap map[string]autoscalingv2.HorizontalPodAutoscaler) Option
 	}
 }
 
+// WithTraefikAPIGroup sets an API group of traefik resources
+// used when the ingress controller spec of the app doesn't configure it explicitly.
+func WithTraefikAPIGroup(group string) Option {
+	return func(opts *Options) {
+		opts.TraefikAPIGroup = group
+	}
+}
+
+// podSelectorLabels returns labels selecting pods of the given process and deployment version.
+func podSelectorLabels(appName, processName string, version ketchv1.DeploymentVersion) map[string]string {
+	return map[string]string{
+		fmt.Sprintf("%s/app-name", ketchv1.Group):               appName,
+		fmt.Sprintf("%s/app-process", ketchv1.Group):            processName,
+		fmt.Sprintf("%s/app-deployment-version", ketchv1.Group): version.String(),
+	}
+}
+
 func imagePullSecrets(deploymentImagePullSecrets []v1.LocalObjectReference, spec ketchv1.DockerRegistrySpec) []v1.LocalObjectReference {
 	if len(deploymentImagePullSecrets) > 0 {
 		// imagePullSecrets defined for this particular deployment is higher priority.
@@ -135,6 +166,12 @@ func New(application *ketchv1.App, opts ...Option) (*ApplicationChart, error) {
 	if err != nil {
 		return nil, err
 	}
+	if ingressController.IngressType == ketchv1.GatewayAPIIngressControllerType && len(ingressController.GatewayName) == 0 {
+		return nil, fmt.Errorf("the %s ingress controller requires a gateway name, set it with \"ketch ingress set --gateway-name\"", ketchv1.GatewayAPIIngressControllerType)
+	}
+	if len(ingressController.TraefikAPIGroup) == 0 {
+		ingressController.TraefikAPIGroup = options.TraefikAPIGroup
+	}
 
 	values := &values{
 		App: &app{
@@ -178,15 +215,20 @@ func New(application *ketchv1.App, opts ...Option) (*ApplicationChart, error) {
 		c := NewConfigurator(deploymentSpec.KetchYaml, *procfile, exposedPorts, DefaultApplicationPort)
 		for _, processSpec := range deploymentSpec.Processes {
 			name := processSpec.Name
-			isRoutable := procfile.IsRoutable(name)
+			exposure := deploymentSpec.ProcessExposure(name)
+			isRoutable := procfile.IsRoutable(name) && exposure.IsHTTPRoutable()
 			processOptions := []processOption{
 				withCmd(c.procfile.Processes[name]),
 				withUnits(processSpec.Units),
 				withEnvs(processSpec.Env),
 				withPortsAndProbes(c),
+				withExposure(exposure),
 				withLifecycle(c.Lifecycle()),
 				withSecurityContext(processSpec.SecurityContext),
 				withResourceRequirements(processSpec.Resources),
+				withScheduling(processSpec.Scheduling.WithDefaults(application.Spec.Scheduling), podSelectorLabels(application.Name, name, deployment.Version)),
+				withRolloutStrategy(deploymentSpec.RolloutStrategy(name)),
+				withContainers(processSpec.InitContainers, processSpec.Sidecars, ketchv1.MakeDeploymentName(application.Name, name, deployment.Version)),
 				withVolumes(processSpec.Volumes),
 				withVolumeMounts(processSpec.VolumeMounts),
 				withLabels(application.Spec.Labels, deployment.Version),
@@ -219,7 +261,23 @@ func New(application *ketchv1.App, opts ...Option) (*ApplicationChart, error) {
 		}
 		values.App.Deployments = append(values.App.Deployments, deployment)
 	}
+	if err := values.App.Ingress.validateRoutes(values.App.Deployments); err != nil {
+		return nil, err
+	}
+	if err := values.App.Ingress.setAppProtocols(application.Name, values.App.Deployments, ingressController.IngressType); err != nil {
+		return nil, err
+	}
 	values.App.IsAccessible = isAppAccessible(values.App)
+	exposedServices, err := newExposedServices(application.Name, values.App.Deployments, ingressController.IngressType)
+	if err != nil {
+		return nil, err
+	}
+	values.App.ExposedServices = exposedServices
+	pdbs, err := podDisruptionBudgets(application.Spec.Deployments, values.App.Deployments)
+	if err != nil {
+		return nil, err
+	}
+	values.App.PodDisruptionBudgets = pdbs
 
 	return &ApplicationChart{
 		values:    *values,
@@ -227,6 +285,49 @@ func New(application *ketchv1.App, opts ...Option) (*ApplicationChart, error) {
 	}, nil
 }
 
+// podDisruptionBudgets returns a PodDisruptionBudget for each process that has more than one unit
+// across all deployment versions, or has a disruption budget configured.
+// During a canary deployment, a budget covers pods of both versions and the most recent deployment's settings are used.
+func podDisruptionBudgets(specs []ketchv1.AppDeploymentSpec, deployments []deployment) ([]podDisruptionBudget, error) {
+	var names []string
+	units := map[string]int{}
+	budgets := map[string]*ketchv1.DisruptionBudgetSpec{}
+	for i, d := range deployments {
+		for j, p := range d.Processes {
+			if _, ok := units[p.Name]; !ok {
+				names = append(names, p.Name)
+			}
+			if p.HPACurrentReplicas > 0 {
+				units[p.Name] += p.HPACurrentReplicas
+			} else {
+				units[p.Name] += p.Units
+			}
+			budgets[p.Name] = specs[i].Processes[j].DisruptionBudget
+		}
+	}
+	var result []podDisruptionBudget
+	for _, name := range names {
+		budget := budgets[name]
+		if err := budget.Validate(); err != nil {
+			return nil, fmt.Errorf("process %q: %w", name, err)
+		}
+		switch {
+		case budget != nil && budget.Disabled:
+			continue
+		case budget != nil && (budget.MinAvailable != nil || budget.MaxUnavailable != nil):
+			result = append(result, podDisruptionBudget{
+				Process:        name,
+				MinAvailable:   budget.MinAvailable,
+				MaxUnavailable: budget.MaxUnavailable,
+			})
+		case units[name] > 1:
+			maxUnavailable := intstr.FromInt(1)
+			result = append(result, podDisruptionBudget{Process: name, MaxUnavailable: &maxUnavailable})
+		}
+	}
+	return result, nil
+}
+
 func (chrt ApplicationChart) getValuesMap() (map[string]interface{}, error) {
 	bs, err := yaml.Marshal(chrt.values)
 	if err != nil {
@@ -16,6 +16,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	clientfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
@@ -39,6 +40,12 @@ func TestNewApplicationChart(t *testing.T) {
 		ClusterIssuer:   "letsencrypt-production"}
 	ingressControllerWithoutClusterIssuer := ketchv1.IngressControllerSpec{ClassName: "gke",
 		ServiceEndpoint: "20.20.20.20"}
+	withGateway := func(spec ketchv1.IngressControllerSpec) ketchv1.IngressControllerSpec {
+		spec.IngressType = ketchv1.GatewayAPIIngressControllerType
+		spec.GatewayName = "ketch"
+		spec.GatewayNamespace = "gateway-system"
+		return spec
+	}
 
 	hpaMap := map[string]autoscalingv2.HorizontalPodAutoscaler{
 		"dashboard-web-3": {
@@ -241,6 +248,200 @@ func TestNewApplicationChart(t *testing.T) {
 		}
 		return &out
 	}
+	setRolloutStrategy := func(app *ketchv1.App) *ketchv1.App {
+		out := app.DeepCopy()
+		maxSurge := intstr.FromInt(1)
+		maxUnavailable := intstr.FromInt(0)
+		out.Spec.Deployments[1].Processes[0].Strategy = &ketchv1.RolloutStrategy{
+			MaxSurge:                &maxSurge,
+			MaxUnavailable:          &maxUnavailable,
+			MinReadySeconds:         conversions.Int32Ptr(10),
+			ProgressDeadlineSeconds: conversions.Int32Ptr(300),
+		}
+		out.Spec.Deployments[1].Processes[1].Strategy = &ketchv1.RolloutStrategy{Type: ketchv1.RecreateStrategy}
+		return out
+	}
+	setContainers := func(app *ketchv1.App) *ketchv1.App {
+		out := app.DeepCopy()
+		out.Spec.Deployments[0].Processes[0].InitContainers = []ketchv1.ContainerSpec{
+			{Name: "wait-for-db", Image: "busybox:1.36", Command: []string{"sh", "-c", "until nc -z db 5432; do sleep 1; done"}},
+		}
+		out.Spec.Deployments[0].Processes[0].Sidecars = []ketchv1.ContainerSpec{
+			{
+				Name:         "log-shipper",
+				Image:        "fluent/fluent-bit:2.1",
+				Args:         []string{"-c", "/fluent-bit/etc/fluent-bit.conf"},
+				Env:          []ketchv1.Env{{Name: "LOG_LEVEL", Value: "info"}},
+				VolumeMounts: []v1.VolumeMount{{Name: "test-volume", MountPath: "/logs"}},
+				Resources: &v1.ResourceRequirements{
+					Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("64Mi")},
+				},
+			},
+		}
+		return out
+	}
+	setRoutes := func(app *ketchv1.App, routes ...ketchv1.CnameRoute) *ketchv1.App {
+		out := app.DeepCopy()
+		out.Spec.Ingress.Cnames[0].Routes = routes
+		out.Spec.Ingress.Cnames = append(out.Spec.Ingress.Cnames, ketchv1.Cname{
+			Name:   "worker.theketch.io",
+			Routes: []ketchv1.CnameRoute{{PathPrefix: "/", Process: "worker"}},
+		})
+		return out
+	}
+	setPaths := func(app *ketchv1.App) *ketchv1.App {
+		out := app.DeepCopy()
+		out.Spec.Ingress.Cnames = append(out.Spec.Ingress.Cnames,
+			ketchv1.Cname{
+				Name:      "api.theketch.io",
+				Secure:    true,
+				Path:      "/orders",
+				StripPath: true,
+				Routes:    []ketchv1.CnameRoute{{PathPrefix: "/jobs", Process: "worker"}},
+			},
+			ketchv1.Cname{Name: "shop.theketch.io", Path: "/shop/"},
+		)
+		return out
+	}
+	setPolicies := func(app *ketchv1.App) *ketchv1.App {
+		out := app.DeepCopy()
+		out.Spec.Ingress.Policies = &ketchv1.IngressPolicies{
+			ResponseHeaders: map[string]string{"X-Frame-Options": "DENY"},
+		}
+		out.Spec.Ingress.Cnames[0].Policies = &ketchv1.IngressPolicies{
+			RateLimit: &ketchv1.RateLimitPolicy{RequestsPerSecond: 10, Burst: 20},
+			CORS: &ketchv1.CORSPolicy{
+				AllowOrigins:     []string{"https://app.theketch.io"},
+				AllowMethods:     []string{"GET", "POST"},
+				AllowHeaders:     []string{"Authorization"},
+				AllowCredentials: true,
+				MaxAge:           600,
+			},
+			IPAllowlist:     []string{"10.0.0.0/8", "192.168.1.10"},
+			RequestHeaders:  map[string]string{"X-Environment": "production"},
+			ResponseHeaders: map[string]string{"X-Frame-Options": "SAMEORIGIN"},
+		}
+		return out
+	}
+	setAuth := func(app *ketchv1.App, auth ketchv1.IngressAuth) *ketchv1.App {
+		out := app.DeepCopy()
+		out.Spec.Ingress.Cnames[1].Policies = &ketchv1.IngressPolicies{Auth: &auth}
+		return out
+	}
+	setExposure := func(app *ketchv1.App, web, worker *ketchv1.ExposureSpec) *ketchv1.App {
+		out := app.DeepCopy()
+		for i := range out.Spec.Deployments {
+			out.Spec.Deployments[i].Processes[0].Exposure = web
+			out.Spec.Deployments[i].Processes[1].Exposure = worker
+		}
+		return out
+	}
+	setProtocols := func(app *ketchv1.App) *ketchv1.App {
+		out := setRoutes(app, ketchv1.CnameRoute{PathPrefix: "/admin", Process: "worker", Port: 9092})
+		out.Spec.Ingress.UpstreamReadTimeoutSeconds = 3600
+		out.Spec.Deployments[1].KetchYaml = &ketchv1.KetchYamlData{
+			Kubernetes: &ketchv1.KetchYamlKubernetesConfig{
+				Processes: map[string]ketchv1.KetchYamlProcessConfig{
+					"web": {Ports: []ketchv1.KetchYamlProcessPortConfig{{Port: 9091, AppProtocol: ketchv1.GRPCAppProtocol}}},
+					"worker": {Ports: []ketchv1.KetchYamlProcessPortConfig{
+						{Port: 9091, AppProtocol: ketchv1.WebSocketAppProtocol},
+						{Port: 9092, AppProtocol: ketchv1.GRPCSAppProtocol, InsecureSkipVerify: true},
+					}},
+				},
+			},
+		}
+		return out
+	}
+	setWorkerProbes := func(app *ketchv1.App) *ketchv1.App {
+		out := app.DeepCopy()
+		out.Spec.Deployments[1].KetchYaml = &ketchv1.KetchYamlData{
+			Kubernetes: &ketchv1.KetchYamlKubernetesConfig{
+				Processes: map[string]ketchv1.KetchYamlProcessConfig{
+					"web": {Ports: []ketchv1.KetchYamlProcessPortConfig{{Port: 9091}}},
+					"worker": {Healthcheck: &ketchv1.KetchYamlHealthcheck{
+						LivenessProbe: &v1.Probe{
+							ProbeHandler: v1.ProbeHandler{
+								Exec: &v1.ExecAction{Command: []string{"cat", "/tmp/healthy"}},
+							},
+						},
+					}},
+				},
+			},
+		}
+		return out
+	}
+	setAppWideProbes := func(app *ketchv1.App) *ketchv1.App {
+		out := app.DeepCopy()
+		out.Spec.Deployments[1].KetchYaml = &ketchv1.KetchYamlData{
+			Healthcheck: &ketchv1.KetchYamlHealthcheck{
+				LivenessProbe: &v1.Probe{
+					ProbeHandler: v1.ProbeHandler{
+						HTTPGet: &v1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt(9091)},
+					},
+				},
+			},
+			Kubernetes: &ketchv1.KetchYamlKubernetesConfig{
+				Processes: map[string]ketchv1.KetchYamlProcessConfig{
+					"web":    {Ports: []ketchv1.KetchYamlProcessPortConfig{{Port: 9091}}},
+					"worker": {},
+				},
+			},
+		}
+		return out
+	}
+	setMeshPolicy := func(app *ketchv1.App) *ketchv1.App {
+		out := setRoutes(app, ketchv1.CnameRoute{PathPrefix: "/admin", Process: "worker"})
+		out.Spec.Ingress.MeshPolicy = &ketchv1.MeshPolicy{
+			Retries:               &ketchv1.RetryPolicy{Attempts: 3, PerTryTimeoutSeconds: 2, RetryOn: []string{"5xx", "connect-failure"}},
+			RequestTimeoutSeconds: 10,
+			OutlierDetection:      &ketchv1.OutlierDetectionPolicy{Consecutive5xxErrors: 5, IntervalSeconds: 10, BaseEjectionTimeSeconds: 30, MaxEjectionPercent: 50},
+			ConnectionPool:        &ketchv1.ConnectionPoolPolicy{MaxConnections: 100, MaxPendingRequests: 10},
+		}
+		return out
+	}
+	setFaultInjection := func(app *ketchv1.App) *ketchv1.App {
+		out := setRoutes(app, ketchv1.CnameRoute{PathPrefix: "/admin", Process: "worker"})
+		out.Spec.Ingress.MeshPolicy = &ketchv1.MeshPolicy{
+			FaultInjection: &ketchv1.FaultInjectionPolicy{
+				Enabled: true,
+				Delay:   &ketchv1.FaultDelay{Percentage: 10, FixedDelaySeconds: 5},
+				Abort:   &ketchv1.FaultAbort{Percentage: 5, HTTPStatus: 503},
+			},
+		}
+		return out
+	}
+	setScheduling := func(app *ketchv1.App) *ketchv1.App {
+		out := app.DeepCopy()
+		out.Spec.Scheduling = &ketchv1.SchedulingSpec{
+			Tolerations: []v1.Toleration{
+				{Key: "spot", Operator: v1.TolerationOpEqual, Value: "true", Effect: v1.TaintEffectNoSchedule},
+			},
+			TopologySpreadConstraints: []v1.TopologySpreadConstraint{
+				{MaxSkew: 1, TopologyKey: "topology.kubernetes.io/zone", WhenUnsatisfiable: v1.ScheduleAnyway},
+			},
+			PriorityClassName: "low-priority",
+		}
+		out.Spec.Deployments[0].Processes[0].Scheduling = &ketchv1.SchedulingSpec{
+			NodeSelectorTerms: []v1.NodeSelectorTerm{
+				{MatchExpressions: []v1.NodeSelectorRequirement{
+					{Key: "node.kubernetes.io/instance-type", Operator: v1.NodeSelectorOpIn, Values: []string{"m5.large"}},
+				}},
+			},
+			PodAntiAffinity: &v1.PodAntiAffinity{
+				PreferredDuringSchedulingIgnoredDuringExecution: []v1.WeightedPodAffinityTerm{
+					{
+						Weight: 100,
+						PodAffinityTerm: v1.PodAffinityTerm{
+							LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"theketch.io/app-name": "dashboard"}},
+							TopologyKey:   "kubernetes.io/hostname",
+						},
+					},
+				},
+			},
+			PriorityClassName: "high-priority",
+		}
+		return out
+	}
 	setStatefulSet := func(app *ketchv1.App) *ketchv1.App {
 		out := *app
 		appType := ketchv1.StatefulSetAppType
@@ -278,6 +479,156 @@ func TestNewApplicationChart(t *testing.T) {
 			ingressController: ingressControllerWithoutClusterIssuer,
 			wantYamlsFilename: "dashboard-nginx",
 		},
+		{
+			name: "nginx templates with scheduling options",
+			opts: []Option{
+				WithTemplates(templates.NginxDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       setScheduling(convertSecureEndpoints(dashboard)),
+			ingressController: ingressControllerWithoutClusterIssuer,
+			wantYamlsFilename: "dashboard-nginx-scheduling",
+		},
+		{
+			name: "nginx templates with probes of a worker without ports",
+			opts: []Option{
+				WithTemplates(templates.NginxDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       setWorkerProbes(convertSecureEndpoints(dashboard)),
+			ingressController: ingressControllerWithoutClusterIssuer,
+			wantYamlsFilename: "dashboard-nginx-worker-probes",
+		},
+		{
+			name: "nginx templates with app-wide probes and a worker without ports",
+			opts: []Option{
+				WithTemplates(templates.NginxDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       setAppWideProbes(convertSecureEndpoints(dashboard)),
+			ingressController: ingressControllerWithoutClusterIssuer,
+			wantYamlsFilename: "dashboard-nginx-app-wide-probes",
+		},
+		{
+			name: "nginx templates with rollout strategy",
+			opts: []Option{
+				WithTemplates(templates.NginxDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       setRolloutStrategy(convertSecureEndpoints(dashboard)),
+			ingressController: ingressControllerWithoutClusterIssuer,
+			wantYamlsFilename: "dashboard-nginx-rollout-strategy",
+		},
+		{
+			name: "nginx templates with init containers and sidecars",
+			opts: []Option{
+				WithTemplates(templates.NginxDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       setContainers(convertSecureEndpoints(dashboard)),
+			ingressController: ingressControllerWithoutClusterIssuer,
+			wantYamlsFilename: "dashboard-nginx-containers",
+		},
+		{
+			name: "nginx templates with routes",
+			opts: []Option{
+				WithTemplates(templates.NginxDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       setRoutes(dashboard, ketchv1.CnameRoute{PathPrefix: "/worker", Process: "worker"}),
+			ingressController: ingressController,
+			wantYamlsFilename: "dashboard-nginx-routes",
+		},
+		{
+			name: "nginx templates with cname paths",
+			opts: []Option{
+				WithTemplates(templates.NginxDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       setPaths(dashboard),
+			ingressController: ingressController,
+			wantYamlsFilename: "dashboard-nginx-paths",
+		},
+		{
+			name: "nginx templates with ingress policies",
+			opts: []Option{
+				WithTemplates(templates.NginxDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       setPolicies(dashboard),
+			ingressController: ingressController,
+			wantYamlsFilename: "dashboard-nginx-policies",
+		},
+		{
+			name: "nginx templates with forward auth",
+			opts: []Option{
+				WithTemplates(templates.NginxDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application: setAuth(dashboard, ketchv1.IngressAuth{ForwardAuth: &ketchv1.ForwardAuth{
+				URL:             "https://oauth2-proxy.theketch.io/oauth2/auth",
+				SignInURL:       "https://oauth2-proxy.theketch.io/oauth2/start",
+				ResponseHeaders: []string{"X-Auth-Request-User", "X-Auth-Request-Email"},
+			}}),
+			ingressController: ingressController,
+			wantYamlsFilename: "dashboard-nginx-auth",
+		},
+		{
+			name: "nginx templates with load balancer and node port exposure",
+			opts: []Option{
+				WithTemplates(templates.NginxDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application: setExposure(dashboard,
+				&ketchv1.ExposureSpec{Mode: ketchv1.ExposureLoadBalancer, LoadBalancerSourceRanges: []string{"10.0.0.0/8"}},
+				&ketchv1.ExposureSpec{Mode: ketchv1.ExposureNodePort, NodePort: 30091}),
+			ingressController: ingressController,
+			wantYamlsFilename: "dashboard-nginx-exposure",
+		},
+		{
+			name: "nginx templates with app protocols",
+			opts: []Option{
+				WithTemplates(templates.NginxDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       setProtocols(dashboard),
+			ingressController: ingressController,
+			wantYamlsFilename: "dashboard-nginx-protocols",
+		},
+		{
+			name: "nginx templates with grpc and http routes of a cname",
+			opts: []Option{
+				WithTemplates(templates.NginxDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application: setProtocols(dashboard),
+			ingressController: ketchv1.IngressControllerSpec{
+				IngressType:     ketchv1.NginxIngressControllerType,
+				ServiceEndpoint: "10.10.10.10",
+				ClusterIssuer:   "letsencrypt-production",
+			},
+			wantErr: true,
+		},
+		{
+			name: "nginx templates with a route to a cluster internal process",
+			opts: []Option{
+				WithTemplates(templates.NginxDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       setExposure(setRoutes(dashboard, ketchv1.CnameRoute{PathPrefix: "/", Process: "web"}), nil, &ketchv1.ExposureSpec{Mode: ketchv1.ExposureClusterInternal}),
+			ingressController: ingressController,
+			wantErr:           true,
+		},
+		{
+			name: "nginx templates with a route to an unknown process",
+			opts: []Option{
+				WithTemplates(templates.NginxDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       setRoutes(dashboard, ketchv1.CnameRoute{PathPrefix: "/admin", Process: "admin"}),
+			ingressController: ingressController,
+			wantErr:           true,
+		},
 		{
 			name: "nginx templates with HPA",
 			opts: []Option{
@@ -329,6 +680,118 @@ func TestNewApplicationChart(t *testing.T) {
 			ingressController: ingressControllerWithoutClusterIssuer,
 			wantYamlsFilename: "dashboard-istio",
 		},
+		{
+			name: "istio templates with routes",
+			opts: []Option{
+				WithTemplates(templates.IstioDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       setRoutes(dashboard, ketchv1.CnameRoute{PathPrefix: "/worker", Process: "worker"}),
+			ingressController: ingressController,
+			wantYamlsFilename: "dashboard-istio-routes",
+		},
+		{
+			name: "istio templates with cname paths",
+			opts: []Option{
+				WithTemplates(templates.IstioDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       setPaths(dashboard),
+			ingressController: ingressController,
+			wantYamlsFilename: "dashboard-istio-paths",
+		},
+		{
+			name: "istio templates with ingress policies",
+			opts: []Option{
+				WithTemplates(templates.IstioDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       setPolicies(dashboard),
+			ingressController: ingressController,
+			wantYamlsFilename: "dashboard-istio-policies",
+		},
+		{
+			name: "istio templates with jwt auth",
+			opts: []Option{
+				WithTemplates(templates.IstioDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application: setAuth(dashboard, ketchv1.IngressAuth{JWT: &ketchv1.JWTAuth{
+				Issuer:    "https://accounts.theketch.io",
+				JWKSURI:   "https://accounts.theketch.io/.well-known/jwks.json",
+				Audiences: []string{"dashboard"},
+			}}),
+			ingressController: ingressController,
+			wantYamlsFilename: "dashboard-istio-auth",
+		},
+		{
+			name: "istio templates with tcp route exposure",
+			opts: []Option{
+				WithTemplates(templates.IstioDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       setExposure(dashboard, nil, &ketchv1.ExposureSpec{Mode: ketchv1.ExposureTCPRoute, ListenPort: 9000}),
+			ingressController: ingressController,
+			wantYamlsFilename: "dashboard-istio-exposure",
+		},
+		{
+			name: "istio templates with app protocols",
+			opts: []Option{
+				WithTemplates(templates.IstioDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       setProtocols(dashboard),
+			ingressController: ingressController,
+			wantYamlsFilename: "dashboard-istio-protocols",
+		},
+		{
+			name: "istio templates with mesh policy",
+			opts: []Option{
+				WithTemplates(templates.IstioDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       setMeshPolicy(dashboard),
+			ingressController: ingressController,
+			wantYamlsFilename: "dashboard-istio-mesh-policy",
+		},
+		{
+			name: "istio templates with fault injection",
+			opts: []Option{
+				WithTemplates(templates.IstioDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       setFaultInjection(dashboard),
+			ingressController: ingressController,
+			wantYamlsFilename: "dashboard-istio-fault-injection",
+		},
+		{
+			name: "nginx templates with mesh policy",
+			opts: []Option{
+				WithTemplates(templates.NginxDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application: setMeshPolicy(dashboard),
+			ingressController: ketchv1.IngressControllerSpec{
+				IngressType:     ketchv1.NginxIngressControllerType,
+				ServiceEndpoint: "10.10.10.10",
+				ClusterIssuer:   "letsencrypt-production",
+			},
+			wantErr: true,
+		},
+		{
+			name: "istio templates with udp route exposure",
+			opts: []Option{
+				WithTemplates(templates.IstioDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application: setExposure(dashboard, nil, &ketchv1.ExposureSpec{Mode: ketchv1.ExposureUDPRoute, ListenPort: 9000}),
+			ingressController: ketchv1.IngressControllerSpec{
+				IngressType:     ketchv1.IstioIngressControllerType,
+				ServiceEndpoint: "10.10.10.10",
+				ClusterIssuer:   "letsencrypt-production",
+			},
+			wantErr: true,
+		},
 		{
 			name: "traefik templates with cluster issuer",
 			opts: []Option{
@@ -370,6 +833,161 @@ func TestNewApplicationChart(t *testing.T) {
 			group:             "shipa.io",
 			wantYamlsFilename: "dashboard-traefik-cluster-issuer-shipa",
 		},
+		{
+			name: "traefik templates with routes",
+			opts: []Option{
+				WithTemplates(templates.TraefikDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       setRoutes(dashboard, ketchv1.CnameRoute{PathPrefix: "/worker", Process: "worker"}),
+			ingressController: ingressController,
+			wantYamlsFilename: "dashboard-traefik-routes",
+		},
+		{
+			name: "traefik templates with cname paths",
+			opts: []Option{
+				WithTemplates(templates.TraefikDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       setPaths(dashboard),
+			ingressController: ingressController,
+			wantYamlsFilename: "dashboard-traefik-paths",
+		},
+		{
+			name: "traefik templates with ingress policies",
+			opts: []Option{
+				WithTemplates(templates.TraefikDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       setPolicies(dashboard),
+			ingressController: ingressController,
+			wantYamlsFilename: "dashboard-traefik-policies",
+		},
+		{
+			name: "traefik templates with basic auth",
+			opts: []Option{
+				WithTemplates(templates.TraefikDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       setAuth(dashboard, ketchv1.IngressAuth{BasicAuthSecret: "dashboard-basic-auth"}),
+			ingressController: ingressController,
+			wantYamlsFilename: "dashboard-traefik-auth",
+		},
+		{
+			name: "traefik templates with cluster internal and tcp route exposure",
+			opts: []Option{
+				WithTemplates(templates.TraefikDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application: setExposure(dashboard,
+				&ketchv1.ExposureSpec{Mode: ketchv1.ExposureClusterInternal},
+				&ketchv1.ExposureSpec{Mode: ketchv1.ExposureTCPRoute, EntryPoint: "worker-tcp"}),
+			ingressController: ingressController,
+			wantYamlsFilename: "dashboard-traefik-exposure",
+		},
+		{
+			name: "traefik templates with app protocols",
+			opts: []Option{
+				WithTemplates(templates.TraefikDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       setProtocols(dashboard),
+			ingressController: ingressController,
+			wantYamlsFilename: "dashboard-traefik-protocols",
+		},
+		{
+			name: "traefik templates with udp route to a tcp port",
+			opts: []Option{
+				WithTemplates(templates.TraefikDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       setExposure(dashboard, nil, &ketchv1.ExposureSpec{Mode: ketchv1.ExposureUDPRoute, EntryPoint: "worker-udp"}),
+			ingressController: ingressController,
+			wantErr:           true,
+		},
+		{
+			name: "traefik templates with legacy api group",
+			opts: []Option{
+				WithTemplates(templates.TraefikDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+				WithTraefikAPIGroup(ketchv1.LegacyTraefikAPIGroup),
+			},
+			application:       setPaths(dashboard),
+			ingressController: ingressController,
+			wantYamlsFilename: "dashboard-traefik-legacy-api-group",
+		},
+		{
+			name: "gateway api templates with cluster issuer",
+			opts: []Option{
+				WithTemplates(templates.GatewayAPIDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       dashboard,
+			ingressController: withGateway(ingressController),
+			wantYamlsFilename: "dashboard-gateway-api-cluster-issuer",
+		},
+		{
+			name: "gateway api templates without cluster issuer",
+			opts: []Option{
+				WithTemplates(templates.GatewayAPIDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       convertSecureEndpoints(dashboard),
+			ingressController: withGateway(ingressControllerWithoutClusterIssuer),
+			wantYamlsFilename: "dashboard-gateway-api",
+		},
+		{
+			name: "gateway api templates without a gateway",
+			opts: []Option{
+				WithTemplates(templates.GatewayAPIDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application: dashboard,
+			ingressController: ketchv1.IngressControllerSpec{
+				IngressType:     ketchv1.GatewayAPIIngressControllerType,
+				ServiceEndpoint: "10.10.10.10",
+				ClusterIssuer:   "letsencrypt-production",
+			},
+			wantErr: true,
+		},
+		{
+			name: "gateway api templates with cname paths",
+			opts: []Option{
+				WithTemplates(templates.GatewayAPIDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application:       setPaths(dashboard),
+			ingressController: withGateway(ingressController),
+			wantYamlsFilename: "dashboard-gateway-api-paths",
+		},
+		{
+			name: "gateway api templates with ingress policies",
+			opts: []Option{
+				WithTemplates(templates.GatewayAPIDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application: setPolicies(dashboard),
+			ingressController: ketchv1.IngressControllerSpec{
+				IngressType:     ketchv1.GatewayAPIIngressControllerType,
+				ServiceEndpoint: "10.10.10.10",
+				ClusterIssuer:   "letsencrypt-production",
+			},
+			wantErr: true,
+		},
+		{
+			name: "gateway api templates with upstream read timeout",
+			opts: []Option{
+				WithTemplates(templates.GatewayAPIDefaultTemplates),
+				WithExposedPorts(exportedPorts),
+			},
+			application: setProtocols(dashboard),
+			ingressController: ketchv1.IngressControllerSpec{
+				IngressType:     ketchv1.GatewayAPIIngressControllerType,
+				ServiceEndpoint: "10.10.10.10",
+				ClusterIssuer:   "letsencrypt-production",
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -413,3 +1031,95 @@ func TestNewApplicationChart(t *testing.T) {
 		})
 	}
 }
+
+func TestPodDisruptionBudgets(t *testing.T) {
+	one := intstr.FromInt(1)
+	half := intstr.FromString("50%")
+	tests := []struct {
+		name        string
+		specs       []ketchv1.AppDeploymentSpec
+		deployments []deployment
+		want        []podDisruptionBudget
+		wantErr     string
+	}{
+		{
+			name: "single unit gets no budget",
+			specs: []ketchv1.AppDeploymentSpec{
+				{Processes: []ketchv1.ProcessSpec{{Name: "web"}}},
+			},
+			deployments: []deployment{
+				{Processes: []process{{Name: "web", Units: 1}}},
+			},
+		},
+		{
+			name: "units of all deployment versions are counted",
+			specs: []ketchv1.AppDeploymentSpec{
+				{Processes: []ketchv1.ProcessSpec{{Name: "web"}, {Name: "worker"}}},
+				{Processes: []ketchv1.ProcessSpec{{Name: "web"}}},
+			},
+			deployments: []deployment{
+				{Processes: []process{{Name: "web", Units: 1}, {Name: "worker", Units: 1}}},
+				{Processes: []process{{Name: "web", Units: 1}}},
+			},
+			want: []podDisruptionBudget{
+				{Process: "web", MaxUnavailable: &one},
+			},
+		},
+		{
+			name: "hpa replicas are used",
+			specs: []ketchv1.AppDeploymentSpec{
+				{Processes: []ketchv1.ProcessSpec{{Name: "web"}}},
+			},
+			deployments: []deployment{
+				{Processes: []process{{Name: "web", Units: 1, HPACurrentReplicas: 3}}},
+			},
+			want: []podDisruptionBudget{
+				{Process: "web", MaxUnavailable: &one},
+			},
+		},
+		{
+			name: "the most recent deployment's budget is used",
+			specs: []ketchv1.AppDeploymentSpec{
+				{Processes: []ketchv1.ProcessSpec{{Name: "web", DisruptionBudget: &ketchv1.DisruptionBudgetSpec{Disabled: true}}}},
+				{Processes: []ketchv1.ProcessSpec{{Name: "web", DisruptionBudget: &ketchv1.DisruptionBudgetSpec{MinAvailable: &half}}}},
+			},
+			deployments: []deployment{
+				{Processes: []process{{Name: "web", Units: 1}}},
+				{Processes: []process{{Name: "web", Units: 1}}},
+			},
+			want: []podDisruptionBudget{
+				{Process: "web", MinAvailable: &half},
+			},
+		},
+		{
+			name: "disabled budget",
+			specs: []ketchv1.AppDeploymentSpec{
+				{Processes: []ketchv1.ProcessSpec{{Name: "web", DisruptionBudget: &ketchv1.DisruptionBudgetSpec{Disabled: true}}}},
+			},
+			deployments: []deployment{
+				{Processes: []process{{Name: "web", Units: 3}}},
+			},
+		},
+		{
+			name: "invalid budget",
+			specs: []ketchv1.AppDeploymentSpec{
+				{Processes: []ketchv1.ProcessSpec{{Name: "web", DisruptionBudget: &ketchv1.DisruptionBudgetSpec{MinAvailable: &half, MaxUnavailable: &one}}}},
+			},
+			deployments: []deployment{
+				{Processes: []process{{Name: "web", Units: 3}}},
+			},
+			wantErr: `process "web": only one of minAvailable and maxUnavailable can be set`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := podDisruptionBudgets(tt.specs, tt.deployments)
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
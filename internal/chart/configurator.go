@@ -55,11 +55,22 @@ type Probes struct {
 	// More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes
 	// +optional
 	StartupProbe *apiv1.Probe
+	// ProcessSpecific is true if the probes come from a healthcheck of the process in kubernetes.processes
+	// rather than from the app-wide one.
+	ProcessSpecific bool
 }
 
-func (c Configurator) Probes() (Probes, error) {
+// Probes returns probes of the given process.
+// A healthcheck defined for the process in kubernetes.processes takes precedence over the app-wide one.
+func (c Configurator) Probes(process string) (Probes, error) {
 	var result Probes
 	hc := c.data.Healthcheck
+	if c.data.Kubernetes != nil {
+		if processConfig, ok := c.data.Kubernetes.Processes[process]; ok && processConfig.Healthcheck != nil {
+			hc = processConfig.Healthcheck
+			result.ProcessSpecific = true
+		}
+	}
 	if hc == nil {
 		return result, nil
 	}
@@ -165,6 +176,8 @@ func (c Configurator) ServicePortsForProcess(process string) []apiv1.ServicePort
 		var name string
 		if len(portConfig.Name) > 0 {
 			name = portConfig.Name
+		} else if len(portConfig.AppProtocol) > 0 {
+			name = fmt.Sprintf("%s-default-%d", portConfig.AppProtocol.ServiceAppProtocol(), i+1)
 		} else {
 			name = fmt.Sprintf("%s-%d", defaultHttpPortName, i+1)
 		}
@@ -175,7 +188,41 @@ func (c Configurator) ServicePortsForProcess(process string) []apiv1.ServicePort
 			Protocol:   apiv1.Protocol(portConfig.Protocol),
 			TargetPort: targetPort,
 		}
+		if len(portConfig.AppProtocol) > 0 {
+			appProtocol := portConfig.AppProtocol.ServiceAppProtocol()
+			sp.AppProtocol = &appProtocol
+		}
 		servicePorts = append(servicePorts, sp)
 	}
 	return servicePorts
 }
+
+// AppProtocolsForProcess returns application protocols of service ports of the process which set them in ketch.yaml.
+func (c Configurator) AppProtocolsForProcess(process string) map[int32]ketchv1.AppProtocol {
+	protocols := map[int32]ketchv1.AppProtocol{}
+	servicePorts := c.ServicePortsForProcess(process)
+	for i, portConfig := range c.ProcessPortConfigs(process) {
+		if len(portConfig.AppProtocol) > 0 {
+			protocols[servicePorts[i].Port] = portConfig.AppProtocol
+		}
+	}
+	if len(protocols) == 0 {
+		return nil
+	}
+	return protocols
+}
+
+// InsecureSkipVerifyPortsForProcess returns service ports of the process whose certificates aren't verified.
+func (c Configurator) InsecureSkipVerifyPortsForProcess(process string) map[int32]bool {
+	ports := map[int32]bool{}
+	servicePorts := c.ServicePortsForProcess(process)
+	for i, portConfig := range c.ProcessPortConfigs(process) {
+		if portConfig.InsecureSkipVerify {
+			ports[servicePorts[i].Port] = true
+		}
+	}
+	if len(ports) == 0 {
+		return nil
+	}
+	return ports
+}
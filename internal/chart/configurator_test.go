@@ -0,0 +1,100 @@
+package chart
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
+)
+
+func TestConfigurator_Probes(t *testing.T) {
+	httpProbe := &v1.Probe{
+		ProbeHandler: v1.ProbeHandler{
+			HTTPGet: &v1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt(8080)},
+		},
+	}
+	execProbe := &v1.Probe{
+		ProbeHandler: v1.ProbeHandler{
+			Exec: &v1.ExecAction{Command: []string{"cat", "/tmp/healthy"}},
+		},
+	}
+	data := &ketchv1.KetchYamlData{
+		Healthcheck: &ketchv1.KetchYamlHealthcheck{
+			ReadinessProbe: httpProbe,
+			LivenessProbe:  httpProbe,
+		},
+		Kubernetes: &ketchv1.KetchYamlKubernetesConfig{
+			Processes: map[string]ketchv1.KetchYamlProcessConfig{
+				"web": {
+					Ports: []ketchv1.KetchYamlProcessPortConfig{{Port: 8080}},
+				},
+				"worker": {
+					Healthcheck: &ketchv1.KetchYamlHealthcheck{
+						LivenessProbe: execProbe,
+					},
+				},
+				"cron": {
+					Healthcheck: &ketchv1.KetchYamlHealthcheck{},
+				},
+			},
+		},
+	}
+	tests := []struct {
+		name    string
+		data    *ketchv1.KetchYamlData
+		process string
+		want    Probes
+	}{
+		{
+			name:    "no ketch.yaml",
+			process: "web",
+		},
+		{
+			name:    "process without a healthcheck uses app-wide probes",
+			data:    data,
+			process: "web",
+			want:    Probes{Readiness: httpProbe, Liveness: httpProbe},
+		},
+		{
+			name:    "process not listed in kubernetes.processes uses app-wide probes",
+			data:    data,
+			process: "admin",
+			want:    Probes{Readiness: httpProbe, Liveness: httpProbe},
+		},
+		{
+			name:    "process healthcheck replaces app-wide probes",
+			data:    data,
+			process: "worker",
+			want:    Probes{Liveness: execProbe, ProcessSpecific: true},
+		},
+		{
+			name:    "empty process healthcheck disables probes",
+			data:    data,
+			process: "cron",
+			want:    Probes{ProcessSpecific: true},
+		},
+		{
+			name: "process healthcheck without app-wide probes",
+			data: &ketchv1.KetchYamlData{
+				Kubernetes: &ketchv1.KetchYamlKubernetesConfig{
+					Processes: map[string]ketchv1.KetchYamlProcessConfig{
+						"worker": {Healthcheck: &ketchv1.KetchYamlHealthcheck{StartupProbe: execProbe}},
+					},
+				},
+			},
+			process: "worker",
+			want:    Probes{StartupProbe: execProbe, ProcessSpecific: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewConfigurator(tt.data, Procfile{}, nil, DefaultApplicationPort)
+			got, err := c.Probes(tt.process)
+			require.Nil(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
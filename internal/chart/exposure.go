@@ -0,0 +1,96 @@
+package chart
+
+import (
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+
+	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
+)
+
+// exposedService contains values for populating the exposed_service.yaml and TCP/UDP routes of ingress controllers.
+// Unlike services of deployments, it selects pods of all deployment versions, so its address doesn't change between deployments.
+type exposedService struct {
+	// Name of the Service, "<app>-<process>-exposed".
+	Name                     string                    `json:"name"`
+	Process                  string                    `json:"process"`
+	Version                  ketchv1.DeploymentVersion `json:"version"`
+	Type                     v1.ServiceType            `json:"type"`
+	Ports                    []v1.ServicePort          `json:"ports"`
+	LoadBalancerSourceRanges []string                  `json:"loadBalancerSourceRanges,omitempty"`
+	// Route is set when an ingress controller sends TCP or UDP traffic to the Service.
+	Route *exposedRoute `json:"route,omitempty"`
+}
+
+// exposedRoute describes a TCP or UDP route of an ingress controller.
+type exposedRoute struct {
+	Protocol v1.Protocol `json:"protocol"`
+	// Port is a service port the route sends traffic to.
+	Port int32 `json:"port"`
+	// EntryPoint is a traefik entry point accepting the traffic.
+	EntryPoint string `json:"entryPoint,omitempty"`
+	// ListenPort is a port of the istio ingress gateway accepting the traffic.
+	ListenPort int32 `json:"listenPort,omitempty"`
+}
+
+// ExposedServiceName returns a name of a Service of an exposed process.
+// The "exposed" suffix keeps it apart from "<app>-<process>-<version>" services of deployments.
+func ExposedServiceName(appName, process string) string {
+	return fmt.Sprintf("%s-%s-exposed", appName, process)
+}
+
+// newExposedServices returns a Service for each process of the most recent deployment exposed with a mode other than None.
+func newExposedServices(appName string, deployments []deployment, ingressType ketchv1.IngressControllerType) ([]exposedService, error) {
+	if len(deployments) == 0 {
+		return nil, nil
+	}
+	latest := deployments[len(deployments)-1]
+	var services []exposedService
+	for _, p := range latest.Processes {
+		if p.Exposure == nil || p.Exposure.Mode == ketchv1.ExposureNone {
+			continue
+		}
+		if err := p.Exposure.Validate(ingressType); err != nil {
+			return nil, fmt.Errorf("process %q: %w", p.Name, err)
+		}
+		if !p.hasOpenPort() {
+			return nil, fmt.Errorf("process %q: exposure: process doesn't expose any ports", p.Name)
+		}
+		service := exposedService{
+			Name:    ExposedServiceName(appName, p.Name),
+			Process: p.Name,
+			Version: latest.Version,
+			Type:    v1.ServiceTypeClusterIP,
+			Ports:   append([]v1.ServicePort{}, p.ServicePorts...),
+		}
+		switch p.Exposure.Mode {
+		case ketchv1.ExposureLoadBalancer:
+			service.Type = v1.ServiceTypeLoadBalancer
+			service.LoadBalancerSourceRanges = p.Exposure.LoadBalancerSourceRanges
+		case ketchv1.ExposureNodePort:
+			service.Type = v1.ServiceTypeNodePort
+			service.Ports[0].NodePort = p.Exposure.NodePort
+		case ketchv1.ExposureTCPRoute, ketchv1.ExposureUDPRoute:
+			protocol := v1.ProtocolTCP
+			if p.Exposure.Mode == ketchv1.ExposureUDPRoute {
+				protocol = v1.ProtocolUDP
+			}
+			port := service.Ports[0]
+			portProtocol := port.Protocol
+			if len(portProtocol) == 0 {
+				portProtocol = v1.ProtocolTCP
+			}
+			if portProtocol != protocol {
+				return nil, fmt.Errorf("process %q: exposure: %s requires port %d to use the %s protocol", p.Name, p.Exposure.Mode, port.Port, protocol)
+			}
+			service.Route = &exposedRoute{
+				Protocol:   protocol,
+				Port:       port.Port,
+				EntryPoint: p.Exposure.EntryPoint,
+				ListenPort: p.Exposure.ListenPort,
+			}
+		}
+		services = append(services, service)
+	}
+	return services, nil
+}
@@ -3,6 +3,8 @@ package chart
 import (
 	"fmt"
 	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/pkg/errors"
 
@@ -42,6 +44,94 @@ type ingress struct {
 
 	// Https is a list of https entrypoints.
 	Https []httpsEndpoint `json:"https"`
+
+	// Routes contains routes of cnames that send requests to processes based on a path prefix.
+	// Requests to a cname without routes are sent to the routable process.
+	Routes map[string]cnameRoutes `json:"routes"`
+	// UpstreamReadTimeoutSeconds is how long the ingress controller waits for data from the application.
+	UpstreamReadTimeoutSeconds int32 `json:"upstreamReadTimeoutSeconds,omitempty"`
+	// MeshPolicy is rendered into istio DestinationRules and VirtualServices of the application.
+	MeshPolicy *ketchv1.MeshPolicy `json:"meshPolicy,omitempty"`
+}
+
+// cnameRoutes holds routes of a cname ordered from the longest path prefix.
+type cnameRoutes struct {
+	// UniqueName is a unique and deterministic identifier that can be used to name a k8s resource for this cname.
+	UniqueName string  `json:"uniqueName"`
+	Routes     []route `json:"routes"`
+	// StripPrefix is a path prefix removed from requests before they are sent to the application.
+	StripPrefix string `json:"stripPrefix,omitempty"`
+	// Policies of requests to the cname, a cname with policies gets its own ingress resources.
+	Policies *ingressPolicies `json:"policies,omitempty"`
+	// BackendProtocol is set when all routes of the cname send requests to gRPC ports,
+	// such a cname gets its own ingress resources, because nginx configures a backend protocol per Ingress.
+	BackendProtocol ketchv1.AppProtocol `json:"backendProtocol,omitempty"`
+}
+
+// ingressPolicies holds policies of a cname in a form convenient for templates.
+type ingressPolicies struct {
+	// PathPrefix is the path of the cname, "/" if the cname doesn't have a path.
+	PathPrefix      string               `json:"pathPrefix"`
+	RateLimit       *rateLimit           `json:"rateLimit,omitempty"`
+	CORS            *ketchv1.CORSPolicy  `json:"cors,omitempty"`
+	IPAllowlist     []string             `json:"ipAllowlist,omitempty"`
+	RequestHeaders  map[string]string    `json:"requestHeaders,omitempty"`
+	ResponseHeaders map[string]string    `json:"responseHeaders,omitempty"`
+	Auth            *ketchv1.IngressAuth `json:"auth,omitempty"`
+}
+
+type rateLimit struct {
+	RequestsPerSecond int32 `json:"requestsPerSecond"`
+	Burst             int32 `json:"burst"`
+	// BurstMultiplier is Burst divided by RequestsPerSecond as nginx expects it.
+	BurstMultiplier int32 `json:"burstMultiplier"`
+}
+
+func newIngressPolicies(policies *ketchv1.IngressPolicies, path string) *ingressPolicies {
+	if policies.IsEmpty() {
+		return nil
+	}
+	result := &ingressPolicies{
+		PathPrefix:      path,
+		CORS:            policies.CORS,
+		IPAllowlist:     policies.IPAllowlist,
+		RequestHeaders:  policies.RequestHeaders,
+		ResponseHeaders: policies.ResponseHeaders,
+		Auth:            policies.Auth,
+	}
+	if policies.RateLimit != nil {
+		burst := policies.RateLimit.EffectiveBurst()
+		result.RateLimit = &rateLimit{
+			RequestsPerSecond: policies.RateLimit.RequestsPerSecond,
+			Burst:             burst,
+			BurstMultiplier:   burst / policies.RateLimit.RequestsPerSecond,
+		}
+	}
+	return result
+}
+
+// route sends requests with a path prefix to a process.
+type route struct {
+	PathPrefix string `json:"pathPrefix"`
+	// Process is a name of a process handling the requests.
+	// If empty, the requests are sent to the routable process of each deployment.
+	Process string `json:"process"`
+	// Port is a service port of the process, if zero, the public service port of the process is used.
+	Port int32 `json:"port"`
+	// Rewrite is the path prefix sent to the process instead of PathPrefix when the cname strips its path.
+	Rewrite string `json:"rewrite,omitempty"`
+	// AppProtocol is an application protocol of the port requests are sent to.
+	AppProtocol ketchv1.AppProtocol `json:"appProtocol,omitempty"`
+}
+
+// CertManagerSecretName returns the name of a cert-manager Certificate and of the secret it stores a certificate of a secure cname in.
+func CertManagerSecretName(appName, cname string) string {
+	return fmt.Sprintf("%s-cname-%s", appName, regexp.MustCompile("[^a-z0-9]+").ReplaceAllString(cname, "-"))
+}
+
+// cnameRoutesUniqueName returns a name of k8s resources rendered for routes of the cname.
+func cnameRoutesUniqueName(appName, cname string) string {
+	return fmt.Sprintf("%s-routes-%s", appName, regexp.MustCompile("[^a-z0-9]+").ReplaceAllString(cname, "-"))
 }
 
 func newIngress(app ketchv1.App, ingressController ketchv1.IngressControllerSpec) (*ingress, error) {
@@ -57,14 +147,42 @@ func newIngress(app ketchv1.App, ingressController ketchv1.IngressControllerSpec
 
 	var http []string
 	var https []httpsEndpoint
+	routes := map[string]cnameRoutes{}
 
 	for _, cname := range app.Spec.Ingress.Cnames {
+		if err := cname.Validate(); err != nil {
+			return nil, err
+		}
+		policies := app.Spec.Ingress.Policies.Merge(cname.Policies)
+		if err := policies.Validate(ingressController.IngressType, cname.NormalizedPath()); err != nil {
+			return nil, fmt.Errorf("cname %s: %w", cname.Name, err)
+		}
+		if len(cname.Routes) > 0 || cname.NormalizedPath() != "/" || policies != nil {
+			var stripPrefix string
+			if cname.StripPath {
+				stripPrefix = cname.NormalizedPath()
+			}
+			var rs []route
+			for _, r := range cname.OrderedRoutes("") {
+				rt := route{PathPrefix: r.PathPrefix, Process: r.Process, Port: r.Port}
+				if len(stripPrefix) > 0 {
+					rt.Rewrite = "/" + strings.TrimLeft(strings.TrimPrefix(r.PathPrefix, stripPrefix), "/")
+				}
+				rs = append(rs, rt)
+			}
+			routes[cname.Name] = cnameRoutes{
+				UniqueName:  cnameRoutesUniqueName(app.Name, cname.Name),
+				Routes:      rs,
+				StripPrefix: stripPrefix,
+				Policies:    newIngressPolicies(policies, cname.NormalizedPath()),
+			}
+		}
 		if !cname.Secure {
 			http = append(http, cname.Name)
 			continue
 		}
 
-		if len(ingressController.ClusterIssuer) == 0 {
+		if len(cname.SecretName) == 0 && len(ingressController.ClusterIssuer) == 0 {
 			return nil, errors.New("secure cnames require a Ingress.ClusterIssuer to be specified")
 		}
 
@@ -79,18 +197,157 @@ func newIngress(app ketchv1.App, ingressController ketchv1.IngressControllerSpec
 		} else {
 			https = append(https, httpsEndpoint{
 				Cname:      cname.Name,
-				SecretName: fmt.Sprintf("%s-cname-%s", app.Name, strippedCname),
+				SecretName: CertManagerSecretName(app.Name, cname.Name),
 				UniqueName: fmt.Sprintf("%s-https-%s", app.Name, strippedCname),
 				ManagedBy:  certManager,
 			})
 		}
 	}
-	defaultCname := app.DefaultCname()
-	if defaultCname != nil {
-		http = append(http, *defaultCname)
+	if err := app.Spec.Ingress.ValidateUpstreamReadTimeout(); err != nil {
+		return nil, err
+	}
+	if err := app.Spec.Ingress.MeshPolicy.Validate(ingressController.IngressType); err != nil {
+		return nil, err
+	}
+	if defaultCname := app.DefaultCname(); defaultCname != nil {
+		if policies := app.Spec.Ingress.Policies; !policies.IsEmpty() {
+			if err := policies.Validate(ingressController.IngressType, "/"); err != nil {
+				return nil, fmt.Errorf("cname %s: %w", *defaultCname, err)
+			}
+			routes[*defaultCname] = cnameRoutes{
+				UniqueName: cnameRoutesUniqueName(app.Name, *defaultCname),
+				Routes:     []route{{PathPrefix: "/"}},
+				Policies:   newIngressPolicies(policies, "/"),
+			}
+		}
+		if len(ingressController.DefaultCnameSecretName) > 0 {
+			https = append(https, httpsEndpoint{
+				Cname:      *defaultCname,
+				SecretName: ingressController.DefaultCnameSecretName,
+				UniqueName: fmt.Sprintf("%s-https-%s", app.Name, regex.ReplaceAllString(*defaultCname, "-")),
+				ManagedBy:  user,
+			})
+		} else {
+			http = append(http, *defaultCname)
+		}
 	}
 	return &ingress{
-		Http:  http,
-		Https: https,
+		Http:                       http,
+		Https:                      https,
+		Routes:                     routes,
+		UpstreamReadTimeoutSeconds: app.Spec.Ingress.UpstreamReadTimeoutSeconds,
+		MeshPolicy:                 newMeshPolicy(app.Spec.Ingress.MeshPolicy),
 	}, nil
 }
+
+// newMeshPolicy returns a mesh policy to render, fault injection is dropped unless it is enabled.
+func newMeshPolicy(policy *ketchv1.MeshPolicy) *ketchv1.MeshPolicy {
+	if policy == nil {
+		return nil
+	}
+	result := policy.DeepCopy()
+	if result.FaultInjection != nil && !result.FaultInjection.Enabled {
+		result.FaultInjection = nil
+	}
+	return result
+}
+
+// setAppProtocols sets application protocols of routes from ports of processes of the most recent deployment.
+// A cname whose requests are all sent to gRPC ports gets routes even if it doesn't have any,
+// so nginx can render it as a separate Ingress with a backend protocol.
+func (i *ingress) setAppProtocols(appName string, deployments []deployment, ingressType ketchv1.IngressControllerType) error {
+	if len(deployments) == 0 {
+		return nil
+	}
+	latest := deployments[len(deployments)-1]
+	cnames := append([]string{}, i.Http...)
+	for _, endpoint := range i.Https {
+		cnames = append(cnames, endpoint.Cname)
+	}
+	for _, cname := range cnames {
+		entry, ok := i.Routes[cname]
+		if !ok {
+			entry = cnameRoutes{
+				UniqueName: cnameRoutesUniqueName(appName, cname),
+				Routes:     []route{{PathPrefix: "/"}},
+			}
+		}
+		protocols := map[ketchv1.AppProtocol]struct{}{}
+		for j, r := range entry.Routes {
+			for _, p := range latest.Processes {
+				if p.Name == r.Process || (len(r.Process) == 0 && p.Routable) {
+					entry.Routes[j].AppProtocol = p.appProtocol(r.Port)
+				}
+			}
+			if len(entry.Routes[j].AppProtocol) > 0 {
+				protocols[entry.Routes[j].AppProtocol] = struct{}{}
+			}
+		}
+		if ingressType == ketchv1.NginxIngressControllerType {
+			if _, ok := protocols[ketchv1.HTTP2AppProtocol]; ok {
+				return fmt.Errorf("cname %s: nginx doesn't proxy %s to applications, use %s for gRPC services", cname, ketchv1.HTTP2AppProtocol, ketchv1.GRPCAppProtocol)
+			}
+		}
+		if len(protocols) != 1 {
+			for protocol := range protocols {
+				if protocol.IsGRPC() && ingressType == ketchv1.NginxIngressControllerType {
+					return fmt.Errorf("cname %s: nginx requires all routes of a cname to use the same protocol if one of them uses gRPC", cname)
+				}
+			}
+		} else if protocol := entry.Routes[0].AppProtocol; protocol.IsGRPC() {
+			entry.BackendProtocol = protocol
+		}
+		if ok || len(entry.BackendProtocol) > 0 {
+			i.Routes[cname] = entry
+		}
+	}
+	return nil
+}
+
+// validateRoutes checks that processes the routes send requests to exist in the most recent deployment and expose the ports.
+func (i ingress) validateRoutes(deployments []deployment) error {
+	if len(deployments) == 0 {
+		return nil
+	}
+	latest := deployments[len(deployments)-1]
+	cnames := make([]string, 0, len(i.Routes))
+	for cname := range i.Routes {
+		cnames = append(cnames, cname)
+	}
+	sort.Strings(cnames)
+	for _, cname := range cnames {
+		for _, r := range i.Routes[cname].Routes {
+			if len(r.Process) == 0 {
+				continue
+			}
+			if err := validateRoute(latest, r); err != nil {
+				return fmt.Errorf("cname %s: route %s: %w", cname, r.PathPrefix, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validateRoute(d deployment, r route) error {
+	for _, p := range d.Processes {
+		if p.Name != r.Process {
+			continue
+		}
+		if !p.Exposure.IsHTTPRoutable() {
+			return fmt.Errorf("process %q is exposed as %s and doesn't receive http requests", r.Process, p.Exposure.Mode)
+		}
+		if !p.hasOpenPort() {
+			return fmt.Errorf("process %q doesn't expose any ports", r.Process)
+		}
+		if r.Port == 0 {
+			return nil
+		}
+		for _, port := range p.ServicePorts {
+			if port.Port == r.Port {
+				return nil
+			}
+		}
+		return fmt.Errorf("process %q doesn't expose port %d", r.Process, r.Port)
+	}
+	return fmt.Errorf("process %q not found", r.Process)
+}
@@ -12,11 +12,14 @@ import (
 
 func TestNewIngress(t *testing.T) {
 	tests := []struct {
-		name          string
-		cnames        ketchv1.CnameList
-		clusterIssuer string
-		expected      *ingress
-		expectedError error
+		name                 string
+		cnames               ketchv1.CnameList
+		clusterIssuer        string
+		generateDefaultCname bool
+		controller           ketchv1.IngressControllerSpec
+		policies             *ketchv1.IngressPolicies
+		expected             *ingress
+		expectedError        error
 	}{
 		{
 			name: "happy",
@@ -32,6 +35,7 @@ func TestNewIngress(t *testing.T) {
 					{Cname: "b.name", SecretName: "my-app-cname-b-name", UniqueName: "my-app-https-b-name", ManagedBy: certManager},
 					{Cname: "c.name", SecretName: "c-ssl", UniqueName: "my-app-https-c-name", ManagedBy: user},
 				},
+				Routes: map[string]cnameRoutes{},
 			},
 		},
 		{
@@ -45,9 +49,142 @@ func TestNewIngress(t *testing.T) {
 				},
 			},
 			expected: &ingress{
-				Http: []string{"a.name", "b.name"},
+				Http:   []string{"a.name", "b.name"},
+				Routes: map[string]cnameRoutes{},
 			},
 		},
+		{
+			name: "happy - routes",
+			cnames: ketchv1.CnameList{
+				{
+					Name: "a.name",
+					Routes: []ketchv1.CnameRoute{
+						{PathPrefix: "/admin/", Process: "admin", Port: 9090},
+						{PathPrefix: "/api/v2", Process: "api"},
+					},
+				},
+				{
+					Name:   "admin.name",
+					Routes: []ketchv1.CnameRoute{{PathPrefix: "/", Process: "admin"}},
+				},
+			},
+			expected: &ingress{
+				Http: []string{"a.name", "admin.name"},
+				Routes: map[string]cnameRoutes{
+					"a.name": {
+						UniqueName: "my-app-routes-a-name",
+						Routes: []route{
+							{PathPrefix: "/api/v2", Process: "api"},
+							{PathPrefix: "/admin", Process: "admin", Port: 9090},
+							{PathPrefix: "/"},
+						},
+					},
+					"admin.name": {
+						UniqueName: "my-app-routes-admin-name",
+						Routes:     []route{{PathPrefix: "/", Process: "admin"}},
+					},
+				},
+			},
+		},
+		{
+			name: "happy - secure cname with a secret, no cluster issuer",
+			cnames: ketchv1.CnameList{
+				{Name: "c.name", Secure: true, SecretName: "c-ssl"},
+			},
+			expected: &ingress{
+				Https: []httpsEndpoint{
+					{Cname: "c.name", SecretName: "c-ssl", UniqueName: "my-app-https-c-name", ManagedBy: user},
+				},
+				Routes: map[string]cnameRoutes{},
+			},
+		},
+		{
+			name:                 "happy - default cname from a template",
+			cnames:               ketchv1.CnameList{{Name: "a.name"}},
+			generateDefaultCname: true,
+			controller:           ketchv1.IngressControllerSpec{DefaultCnameTemplate: "{{.App}}.{{.Namespace}}.apps.example.com"},
+			expected: &ingress{
+				Http:   []string{"a.name", "my-app.my-namespace.apps.example.com"},
+				Routes: map[string]cnameRoutes{},
+			},
+		},
+		{
+			name:                 "happy - secure default cname",
+			generateDefaultCname: true,
+			controller: ketchv1.IngressControllerSpec{
+				DefaultCnameTemplate:   "{{.App}}.apps.example.com",
+				DefaultCnameSecretName: "apps-wildcard-tls",
+			},
+			expected: &ingress{
+				Https: []httpsEndpoint{
+					{Cname: "my-app.apps.example.com", SecretName: "apps-wildcard-tls", UniqueName: "my-app-https-my-app-apps-example-com", ManagedBy: user},
+				},
+				Routes: map[string]cnameRoutes{},
+			},
+		},
+		{
+			name: "happy - policies",
+			cnames: ketchv1.CnameList{
+				{Name: "a.name"},
+				{
+					Name:     "b.name",
+					Path:     "/orders",
+					Policies: &ketchv1.IngressPolicies{RateLimit: &ketchv1.RateLimitPolicy{RequestsPerSecond: 10, Burst: 30}},
+				},
+			},
+			generateDefaultCname: true,
+			controller: ketchv1.IngressControllerSpec{
+				IngressType:          ketchv1.NginxIngressControllerType,
+				DefaultCnameTemplate: "{{.App}}.apps.example.com",
+			},
+			policies: &ketchv1.IngressPolicies{IPAllowlist: []string{"10.0.0.0/8"}},
+			expected: &ingress{
+				Http: []string{"a.name", "b.name", "my-app.apps.example.com"},
+				Routes: map[string]cnameRoutes{
+					"a.name": {
+						UniqueName: "my-app-routes-a-name",
+						Routes:     []route{{PathPrefix: "/"}},
+						Policies:   &ingressPolicies{PathPrefix: "/", IPAllowlist: []string{"10.0.0.0/8"}},
+					},
+					"b.name": {
+						UniqueName: "my-app-routes-b-name",
+						Routes:     []route{{PathPrefix: "/orders"}},
+						Policies: &ingressPolicies{
+							PathPrefix:  "/orders",
+							RateLimit:   &rateLimit{RequestsPerSecond: 10, Burst: 30, BurstMultiplier: 3},
+							IPAllowlist: []string{"10.0.0.0/8"},
+						},
+					},
+					"my-app.apps.example.com": {
+						UniqueName: "my-app-routes-my-app-apps-example-com",
+						Routes:     []route{{PathPrefix: "/"}},
+						Policies:   &ingressPolicies{PathPrefix: "/", IPAllowlist: []string{"10.0.0.0/8"}},
+					},
+				},
+			},
+		},
+		{
+			name: "sad - policies not supported by the ingress controller",
+			cnames: ketchv1.CnameList{
+				{Name: "a.name", Path: "/orders"},
+			},
+			controller:    ketchv1.IngressControllerSpec{IngressType: ketchv1.IstioIngressControllerType},
+			policies:      &ketchv1.IngressPolicies{RateLimit: &ketchv1.RateLimitPolicy{RequestsPerSecond: 10}},
+			expectedError: errors.New("cname a.name: rateLimit: the istio ingress controller limits requests of whole hosts, it can't be used with a cname path"),
+		},
+		{
+			name: "sad - duplicate route",
+			cnames: ketchv1.CnameList{
+				{
+					Name: "a.name",
+					Routes: []ketchv1.CnameRoute{
+						{PathPrefix: "/admin", Process: "admin"},
+						{PathPrefix: "/admin/", Process: "web"},
+					},
+				},
+			},
+			expectedError: errors.New("cname a.name: duplicate route /admin/"),
+		},
 		{
 			name: "sad - no cluster issuer",
 			cnames: ketchv1.CnameList{
@@ -61,17 +198,22 @@ func TestNewIngress(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			ingressController := tt.controller
+			ingressController.ClusterIssuer = tt.clusterIssuer
 			app := ketchv1.App{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "my-app",
 				},
 				Spec: ketchv1.AppSpec{
+					Namespace: "my-namespace",
 					Ingress: ketchv1.IngressSpec{
-						Cnames: tt.cnames,
+						Cnames:               tt.cnames,
+						GenerateDefaultCname: tt.generateDefaultCname,
+						Controller:           ingressController,
+						Policies:             tt.policies,
 					},
 				},
 			}
-			ingressController := ketchv1.IngressControllerSpec{ClusterIssuer: tt.clusterIssuer}
 			issuer, err := newIngress(app, ingressController)
 			if tt.expectedError != nil {
 				require.EqualError(t, err, tt.expectedError.Error())
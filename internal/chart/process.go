@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"strings"
 
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
 )
@@ -15,25 +17,42 @@ var (
 )
 
 type process struct {
-	Name               string             `json:"name"`
-	Cmd                []string           `json:"cmd"`
-	Units              int                `json:"units"`
-	Routable           bool               `json:"routable"`
-	ContainerPorts     []v1.ContainerPort `json:"containerPorts"`
-	ServicePorts       []v1.ServicePort   `json:"servicePorts"`
-	PublicServicePort  int32              `json:"publicServicePort,omitempty"`
-	Env                []ketchv1.Env      `json:"env"`
-	HPACurrentReplicas int                `json:"hpaCurrentReplicas,omitempty"`
-
-	SecurityContext      *v1.SecurityContext      `json:"securityContext,omitempty"`
-	ResourceRequirements *v1.ResourceRequirements `json:"resourceRequirements,omitempty"`
-	NodeSelectorTerms    []v1.NodeSelectorTerm    `json:"nodeSelectorTerms,omitempty"`
-	Volumes              []v1.Volume              `json:"volumes,omitempty"`
-	VolumeMounts         []v1.VolumeMount         `json:"volumeMounts,omitempty"`
-	ReadinessProbe       *v1.Probe                `json:"readinessProbe,omitempty"`
-	LivenessProbe        *v1.Probe                `json:"livenessProbe,omitempty"`
-	StartupProbe         *v1.Probe                `json:"startupProbe,omitempty"`
-	Lifecycle            *v1.Lifecycle            `json:"lifecycle,omitempty"`
+	Name              string             `json:"name"`
+	Cmd               []string           `json:"cmd"`
+	Units             int                `json:"units"`
+	Routable          bool               `json:"routable"`
+	ContainerPorts    []v1.ContainerPort `json:"containerPorts"`
+	ServicePorts      []v1.ServicePort   `json:"servicePorts"`
+	PublicServicePort int32              `json:"publicServicePort,omitempty"`
+	// AppProtocol is an application protocol of the public service port, empty for http.
+	AppProtocol ketchv1.AppProtocol `json:"appProtocol,omitempty"`
+	// AppProtocols are application protocols of service ports that set them.
+	AppProtocols map[int32]ketchv1.AppProtocol `json:"appProtocols,omitempty"`
+	// InsecureSkipVerifyPorts are grpcs service ports whose certificates aren't verified.
+	InsecureSkipVerifyPorts map[int32]bool `json:"insecureSkipVerifyPorts,omitempty"`
+	Env                     []ketchv1.Env  `json:"env"`
+	HPACurrentReplicas      int            `json:"hpaCurrentReplicas,omitempty"`
+
+	SecurityContext      *v1.SecurityContext           `json:"securityContext,omitempty"`
+	ResourceRequirements *v1.ResourceRequirements      `json:"resourceRequirements,omitempty"`
+	NodeSelectorTerms    []v1.NodeSelectorTerm         `json:"nodeSelectorTerms,omitempty"`
+	Tolerations          []v1.Toleration               `json:"tolerations,omitempty"`
+	PodAntiAffinity      *v1.PodAntiAffinity           `json:"podAntiAffinity,omitempty"`
+	TopologySpread       []v1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+	PriorityClassName    string                        `json:"priorityClassName,omitempty"`
+	Strategy             *appsv1.DeploymentStrategy    `json:"strategy,omitempty"`
+	MinReadySeconds      *int32                        `json:"minReadySeconds,omitempty"`
+	ProgressDeadline     *int32                        `json:"progressDeadlineSeconds,omitempty"`
+	InitContainers       []v1.Container                `json:"initContainers,omitempty"`
+	Sidecars             []v1.Container                `json:"sidecars,omitempty"`
+	Volumes              []v1.Volume                   `json:"volumes,omitempty"`
+	VolumeMounts         []v1.VolumeMount              `json:"volumeMounts,omitempty"`
+	ReadinessProbe       *v1.Probe                     `json:"readinessProbe,omitempty"`
+	LivenessProbe        *v1.Probe                     `json:"livenessProbe,omitempty"`
+	StartupProbe         *v1.Probe                     `json:"startupProbe,omitempty"`
+	Lifecycle            *v1.Lifecycle                 `json:"lifecycle,omitempty"`
+	// Exposure configures how the process is made reachable, if not set, the process gets a ClusterIP Service.
+	Exposure *ketchv1.ExposureSpec `json:"exposure,omitempty"`
 	// ServiceMetadata contains Labels and Annotations to be added to a k8s Service of this process.
 	ServiceMetadata extraMetadata `json:"serviceMetadata,omitempty"`
 	// DeploymentMetadata contains Labels and Annotations to be added to a k8s Deployment of this process.
@@ -85,24 +104,49 @@ func withCmd(cmd []string) processOption {
 type portConfigurator interface {
 	ContainerPortsForProcess(process string) []v1.ContainerPort
 	ServicePortsForProcess(process string) []v1.ServicePort
-	Probes() (Probes, error)
+	AppProtocolsForProcess(process string) map[int32]ketchv1.AppProtocol
+	InsecureSkipVerifyPortsForProcess(process string) map[int32]bool
+	Probes(process string) (Probes, error)
 }
 
 func withPortsAndProbes(c portConfigurator) processOption {
 	return func(p *process) error {
+		probes, err := c.Probes(p.Name)
+		if err != nil {
+			return err
+		}
 		p.ServicePorts = c.ServicePortsForProcess(p.Name)
 		p.ContainerPorts = c.ContainerPortsForProcess(p.Name)
-		if len(p.ContainerPorts) == 0 || len(p.ServicePorts) == 0 {
-			return nil
+		hasPorts := len(p.ContainerPorts) > 0 && len(p.ServicePorts) > 0
+		// the app-wide healthcheck usually probes a port, so a worker without ports gets only its own probes.
+		if hasPorts || probes.ProcessSpecific {
+			p.LivenessProbe = probes.Liveness
+			p.ReadinessProbe = probes.Readiness
+			p.StartupProbe = probes.StartupProbe
 		}
-		probes, err := c.Probes()
-		if err != nil {
-			return err
+		if !hasPorts {
+			return nil
 		}
 		p.PublicServicePort = p.ServicePorts[0].Port
-		p.LivenessProbe = probes.Liveness
-		p.ReadinessProbe = probes.Readiness
-		p.StartupProbe = probes.StartupProbe
+		p.AppProtocols = c.AppProtocolsForProcess(p.Name)
+		p.AppProtocol = p.AppProtocols[p.PublicServicePort]
+		p.InsecureSkipVerifyPorts = c.InsecureSkipVerifyPortsForProcess(p.Name)
+		return nil
+	}
+}
+
+// withExposure configures how the process is made reachable.
+// It must be applied after withPortsAndProbes, because a process exposed with None doesn't get service ports.
+func withExposure(exposure *ketchv1.ExposureSpec) processOption {
+	return func(p *process) error {
+		p.Exposure = exposure
+		if exposure != nil && exposure.Mode == ketchv1.ExposureNone {
+			p.ServicePorts = nil
+			p.PublicServicePort = 0
+			p.AppProtocol = ""
+			p.AppProtocols = nil
+			p.InsecureSkipVerifyPorts = nil
+		}
 		return nil
 	}
 }
@@ -128,6 +172,97 @@ func withResourceRequirements(rr *v1.ResourceRequirements) processOption {
 	}
 }
 
+// withScheduling configures scheduling options of a process.
+// Topology spread constraints without a label selector get podLabels as their selector.
+func withScheduling(scheduling *ketchv1.SchedulingSpec, podLabels map[string]string) processOption {
+	return func(p *process) error {
+		if scheduling == nil {
+			return nil
+		}
+		p.NodeSelectorTerms = scheduling.NodeSelectorTerms
+		p.Tolerations = scheduling.Tolerations
+		p.PodAntiAffinity = scheduling.PodAntiAffinity
+		p.PriorityClassName = scheduling.PriorityClassName
+		for _, constraint := range scheduling.TopologySpreadConstraints {
+			if constraint.LabelSelector == nil {
+				constraint.LabelSelector = &metav1.LabelSelector{MatchLabels: podLabels}
+			}
+			p.TopologySpread = append(p.TopologySpread, constraint)
+		}
+		return nil
+	}
+}
+
+// withRolloutStrategy configures how pods of a process are replaced during a rollout.
+func withRolloutStrategy(strategy *ketchv1.RolloutStrategy) processOption {
+	return func(p *process) error {
+		if strategy == nil {
+			return nil
+		}
+		if err := strategy.Validate(); err != nil {
+			return fmt.Errorf("process %q: %w", p.Name, err)
+		}
+		p.MinReadySeconds = strategy.MinReadySeconds
+		p.ProgressDeadline = strategy.ProgressDeadlineSeconds
+		switch {
+		case strategy.Type == ketchv1.RecreateStrategy:
+			p.Strategy = &appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType}
+		case strategy.MaxSurge != nil || strategy.MaxUnavailable != nil:
+			p.Strategy = &appsv1.DeploymentStrategy{
+				Type: appsv1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &appsv1.RollingUpdateDeployment{
+					MaxSurge:       strategy.MaxSurge,
+					MaxUnavailable: strategy.MaxUnavailable,
+				},
+			}
+		}
+		return nil
+	}
+}
+
+// withContainers configures init containers and sidecars of a process.
+// Container names must be unique and differ from the name of the app container.
+func withContainers(initContainers, sidecars []ketchv1.ContainerSpec, appContainerName string) processOption {
+	return func(p *process) error {
+		names := map[string]bool{appContainerName: true}
+		convert := func(specs []ketchv1.ContainerSpec) ([]v1.Container, error) {
+			var containers []v1.Container
+			for _, spec := range specs {
+				if len(spec.Name) == 0 || len(spec.Image) == 0 {
+					return nil, fmt.Errorf("process %q: container name and image are required", p.Name)
+				}
+				if names[spec.Name] {
+					return nil, fmt.Errorf("process %q: duplicate container name %q", p.Name, spec.Name)
+				}
+				names[spec.Name] = true
+				container := v1.Container{
+					Name:         spec.Name,
+					Image:        spec.Image,
+					Command:      spec.Command,
+					Args:         spec.Args,
+					VolumeMounts: spec.VolumeMounts,
+				}
+				for _, env := range spec.Env {
+					container.Env = append(container.Env, v1.EnvVar{Name: env.Name, Value: env.Value})
+				}
+				if spec.Resources != nil {
+					container.Resources = *spec.Resources
+				}
+				containers = append(containers, container)
+			}
+			return containers, nil
+		}
+		var err error
+		if p.InitContainers, err = convert(initContainers); err != nil {
+			return err
+		}
+		if p.Sidecars, err = convert(sidecars); err != nil {
+			return err
+		}
+		return nil
+	}
+}
+
 func withVolumes(volumes []v1.Volume) processOption {
 	return func(p *process) error {
 		p.Volumes = volumes
@@ -250,6 +385,17 @@ func (p process) hasOpenPort() bool {
 	return len(p.ContainerPorts) > 0 && len(p.ServicePorts) > 0
 }
 
+// appProtocol returns an application protocol of a service port, the public service port is used if port is zero.
+func (p process) appProtocol(port int32) ketchv1.AppProtocol {
+	if port == 0 {
+		port = p.PublicServicePort
+	}
+	if protocol, ok := p.AppProtocols[port]; ok {
+		return protocol
+	}
+	return ketchv1.HTTPAppProtocol
+}
+
 func (p process) portEnvVariables() []ketchv1.Env {
 	if len(p.ContainerPorts) == 0 {
 		return nil
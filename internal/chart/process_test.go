@@ -8,6 +8,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
@@ -19,7 +20,7 @@ type mockConfigurator struct {
 	containerPorts map[string][]v1.ContainerPort
 }
 
-func (m mockConfigurator) Probes() (Probes, error) {
+func (m mockConfigurator) Probes(process string) (Probes, error) {
 	return Probes{}, nil
 }
 
@@ -27,6 +28,14 @@ func (m mockConfigurator) ServicePortsForProcess(process string) []v1.ServicePor
 	return m.servicePorts[process]
 }
 
+func (m mockConfigurator) AppProtocolsForProcess(process string) map[int32]ketchv1.AppProtocol {
+	return nil
+}
+
+func (m mockConfigurator) InsecureSkipVerifyPortsForProcess(process string) map[int32]bool {
+	return nil
+}
+
 func (m mockConfigurator) ContainerPortsForProcess(process string) []v1.ContainerPort {
 	return m.containerPorts[process]
 }
@@ -465,3 +474,127 @@ func Test_withLabels(t *testing.T) {
 		})
 	}
 }
+
+func Test_withRolloutStrategy(t *testing.T) {
+	one := intstr.FromInt(1)
+	zero := intstr.FromInt(0)
+	quarter := intstr.FromString("25%")
+	seconds := func(s int32) *int32 { return &s }
+	tests := []struct {
+		name     string
+		strategy *ketchv1.RolloutStrategy
+		want     process
+		wantErr  string
+	}{
+		{
+			name: "no strategy",
+		},
+		{
+			name: "rolling update",
+			strategy: &ketchv1.RolloutStrategy{
+				MaxSurge:                &one,
+				MaxUnavailable:          &quarter,
+				MinReadySeconds:         seconds(10),
+				ProgressDeadlineSeconds: seconds(300),
+			},
+			want: process{
+				Strategy: &appsv1.DeploymentStrategy{
+					Type:          appsv1.RollingUpdateDeploymentStrategyType,
+					RollingUpdate: &appsv1.RollingUpdateDeployment{MaxSurge: &one, MaxUnavailable: &quarter},
+				},
+				MinReadySeconds:  seconds(10),
+				ProgressDeadline: seconds(300),
+			},
+		},
+		{
+			name:     "recreate",
+			strategy: &ketchv1.RolloutStrategy{Type: ketchv1.RecreateStrategy},
+			want: process{
+				Strategy: &appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+			},
+		},
+		{
+			name:     "only minReadySeconds",
+			strategy: &ketchv1.RolloutStrategy{MinReadySeconds: seconds(5)},
+			want: process{
+				MinReadySeconds: seconds(5),
+			},
+		},
+		{
+			name:     "recreate with maxSurge",
+			strategy: &ketchv1.RolloutStrategy{Type: ketchv1.RecreateStrategy, MaxSurge: &one},
+			wantErr:  `process "": maxSurge and maxUnavailable can't be used with the Recreate strategy`,
+		},
+		{
+			name:     "maxSurge and maxUnavailable are 0",
+			strategy: &ketchv1.RolloutStrategy{MaxSurge: &zero, MaxUnavailable: &zero},
+			wantErr:  `process "": maxSurge and maxUnavailable can't both be 0`,
+		},
+		{
+			name:     "progressDeadlineSeconds less than minReadySeconds",
+			strategy: &ketchv1.RolloutStrategy{MinReadySeconds: seconds(30), ProgressDeadlineSeconds: seconds(10)},
+			wantErr:  `process "": progressDeadlineSeconds must be greater than minReadySeconds`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := process{}
+			err := withRolloutStrategy(tt.strategy)(&p)
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.want, p)
+		})
+	}
+}
+
+func Test_withContainers(t *testing.T) {
+	tests := []struct {
+		name           string
+		initContainers []ketchv1.ContainerSpec
+		sidecars       []ketchv1.ContainerSpec
+		want           process
+		wantErr        string
+	}{
+		{
+			name:           "init containers and sidecars",
+			initContainers: []ketchv1.ContainerSpec{{Name: "migrate", Image: "app:v1", Command: []string{"migrate"}}},
+			sidecars:       []ketchv1.ContainerSpec{{Name: "proxy", Image: "proxy:v1", Env: []ketchv1.Env{{Name: "PORT", Value: "5432"}}}},
+			want: process{
+				Name:           "web",
+				InitContainers: []v1.Container{{Name: "migrate", Image: "app:v1", Command: []string{"migrate"}}},
+				Sidecars:       []v1.Container{{Name: "proxy", Image: "proxy:v1", Env: []v1.EnvVar{{Name: "PORT", Value: "5432"}}}},
+			},
+		},
+		{
+			name:     "sidecar named as the app container",
+			sidecars: []ketchv1.ContainerSpec{{Name: "app-web-1", Image: "proxy:v1"}},
+			wantErr:  `process "web": duplicate container name "app-web-1"`,
+		},
+		{
+			name:           "duplicate names",
+			initContainers: []ketchv1.ContainerSpec{{Name: "proxy", Image: "proxy:v1"}},
+			sidecars:       []ketchv1.ContainerSpec{{Name: "proxy", Image: "proxy:v1"}},
+			wantErr:        `process "web": duplicate container name "proxy"`,
+		},
+		{
+			name:     "missing image",
+			sidecars: []ketchv1.ContainerSpec{{Name: "proxy"}},
+			wantErr:  `process "web": container name and image are required`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := process{Name: "web"}
+			err := withContainers(tt.initContainers, tt.sidecars, "app-web-1")(&p)
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.want, p)
+		})
+	}
+}
@@ -30,8 +30,12 @@ import (
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	v1 "k8s.io/api/core/v1"
 	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -105,6 +109,9 @@ var (
 // +kubebuilder:rbac:groups="networking.istio.io",resources=gateways,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="networking.istio.io",resources=virtualservices,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="networking.istio.io",resources=destinationrules,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="networking.istio.io",resources=envoyfilters,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="security.istio.io",resources=authorizationpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="security.istio.io",resources=requestauthentications,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="cert-manager.io",resources=certificates,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=clusterroles,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=clusterrolebindings,verbs=get;list;watch;create;update;patch;delete
@@ -113,9 +120,20 @@ var (
 // +kubebuilder:rbac:groups="traefik.containo.us",resources=traefikservices,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="traefik.containo.us",resources=traefikservices/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups="traefik.containo.us",resources=middlewares,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="traefik.containo.us",resources=ingressroutetcps;ingressrouteudps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="traefik.containo.us",resources=serverstransports,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="traefik.io",resources=ingressroutes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="traefik.io",resources=ingressroutes/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="traefik.io",resources=traefikservices,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="traefik.io",resources=traefikservices/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="traefik.io",resources=middlewares,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="traefik.io",resources=ingressroutetcps;ingressrouteudps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="traefik.io",resources=serverstransports,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="gateway.networking.k8s.io",resources=httproutes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch;update;delete;list;watch
 // +kubebuilder:rbac:groups=coordination.k8s.io,resources=leases,verbs=get;list;create;update
 // +kubebuilder:rbac:groups="autoscaling",resources=horizontalpodautoscalers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="policy",resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
 
 func (r *AppReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := r.Log.WithValues("app", req.NamespacedName)
@@ -124,17 +142,18 @@ func (r *AppReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 	if err := r.Get(ctx, req.NamespacedName, &app); err != nil {
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
-	if app.Spec.Ingress.Controller.IngressType == "" || app.Spec.Ingress.Controller.ServiceEndpoint == "" || app.Spec.Ingress.Controller.ClassName == "" {
-		ingressControllerSpec, err := ketchv1.GetIngressControllerSpec(ctx, r.Client)
+	// the defaulting webhook can't configure the ingress controller of apps created before its configmap
+	// or when webhooks are disabled, so the controller fills it in as well.
+	if !app.Spec.Ingress.Controller.IsConfigured() {
+		ingressControllerSpec, err := ketchv1.GetIngressControllerSpec(ctx, r.Client, app.Spec.Ingress.Controller.Profile)
 		// permit notFound error, leaving ingress controller empty
 		if client.IgnoreNotFound(err) != nil {
 			return ctrl.Result{}, err
 		}
 		if ingressControllerSpec != nil {
-			app.Spec.Ingress.Controller = *ingressControllerSpec
+			app.Spec.Ingress.Controller.SetDefaults(*ingressControllerSpec)
 		}
 	}
-
 	if !controllerutil.ContainsFinalizer(&app, ketchv1.KetchFinalizer) {
 		controllerutil.AddFinalizer(&app, ketchv1.KetchFinalizer)
 		if err := r.Update(ctx, &app); err != nil {
@@ -172,6 +191,8 @@ func (r *AppReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 		app.SetCondition(ketchv1.Scheduled, v1.ConditionTrue, "", metav1.NewTime(time.Now()))
 	}
 
+	app.Status.Cnames = r.cnameStatuses(ctx, &app)
+
 	if err := r.Status().Update(context.Background(), &app); err != nil {
 		if k8sErrors.IsConflict(err) {
 			// we don't want to create an event with this conflict error and show it to the user.
@@ -193,7 +214,22 @@ func (r *AppReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.R
 		// set default timeout
 		result = ctrl.Result{RequeueAfter: reconcileTimeout}
 	}
-	return result, err
+	return requeueForCertificates(result, app.Status.Cnames), err
+}
+
+// requeueForCertificates makes the app be reconciled again soon while certificates of its cnames aren't ready,
+// the controller doesn't watch cert-manager certificates, so their statuses are refreshed only by reconciling.
+func requeueForCertificates(result ctrl.Result, statuses []ketchv1.CnameStatus) ctrl.Result {
+	for _, status := range statuses {
+		if status.CertificateReady {
+			continue
+		}
+		if result.RequeueAfter == 0 || result.RequeueAfter > certificateCheckInterval {
+			return ctrl.Result{RequeueAfter: certificateCheckInterval}
+		}
+		return result
+	}
+	return result
 }
 
 func hpaTargetMap(app *ketchv1.App, hpaList autoscalingv2.HorizontalPodAutoscalerList) map[string]autoscalingv2.HorizontalPodAutoscaler {
@@ -219,6 +255,72 @@ func hpaTargetMap(app *ketchv1.App, hpaList autoscalingv2.HorizontalPodAutoscale
 	return hpaTargets
 }
 
+// traefikAPIGroup returns an API group of traefik resources to be created for an app.
+// Unless the group is configured explicitly, traefik.io is used if the cluster serves it
+// and the legacy traefik.containo.us group otherwise.
+// Both groups are served by Traefik since v2.10, so helm replaces legacy resources of existing apps with traefik.io ones
+// and the new IngressRoutes are created before the old ones are removed.
+func (r *AppReconciler) traefikAPIGroup(controller ketchv1.IngressControllerSpec) string {
+	if controller.IngressType != ketchv1.TraefikIngressControllerType || len(controller.TraefikAPIGroup) > 0 {
+		return controller.TraefikAPIGroup
+	}
+	gk := schema.GroupKind{Group: ketchv1.TraefikAPIGroup, Kind: "IngressRoute"}
+	if _, err := r.RESTMapper().RESTMapping(gk, "v1alpha1"); meta.IsNoMatchError(err) {
+		return ketchv1.LegacyTraefikAPIGroup
+	}
+	return ketchv1.TraefikAPIGroup
+}
+
+// certificateGVK is the kind of cert-manager certificates issued for secure cnames.
+var certificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+
+// cnameStatuses returns the state of cert-manager certificates of the app's secure cnames.
+// Cnames with a user provided secret are skipped because cert-manager doesn't manage their certificates.
+func (r *AppReconciler) cnameStatuses(ctx context.Context, app *ketchv1.App) []ketchv1.CnameStatus {
+	namespace := app.Spec.Namespace
+	if app.Spec.Ingress.Controller.IngressType == ketchv1.IstioIngressControllerType {
+		// istio reads certificates from secrets in the namespace of its ingress gateway.
+		namespace = "istio-system"
+	}
+	var statuses []ketchv1.CnameStatus
+	for _, cname := range app.Spec.Ingress.Cnames {
+		if !cname.Secure || len(cname.SecretName) > 0 {
+			continue
+		}
+		status := ketchv1.CnameStatus{
+			Name:       cname.Name,
+			SecretName: chart.CertManagerSecretName(app.Name, cname.Name),
+		}
+		certificate := unstructured.Unstructured{}
+		certificate.SetGroupVersionKind(certificateGVK)
+		if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: status.SecretName}, &certificate); err != nil {
+			status.LastError = fmt.Sprintf("failed to get certificate: %v", err)
+			statuses = append(statuses, status)
+			continue
+		}
+		status.LastError = "waiting for the certificate to be issued"
+		conditions, _, _ := unstructured.NestedSlice(certificate.Object, "status", "conditions")
+		for _, c := range conditions {
+			condition, ok := c.(map[string]interface{})
+			if !ok || condition["type"] != "Ready" {
+				continue
+			}
+			status.CertificateReady = condition["status"] == string(v1.ConditionTrue)
+			status.LastError = ""
+			if !status.CertificateReady {
+				status.LastError, _ = condition["message"].(string)
+			}
+		}
+		if notAfter, found, _ := unstructured.NestedString(certificate.Object, "status", "notAfter"); found {
+			if expires, err := time.Parse(time.RFC3339, notAfter); err == nil {
+				status.CertificateExpires = &metav1.Time{Time: expires}
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
 type appReconcileResult struct {
 	useTimeout bool
 	err        error
@@ -373,7 +475,8 @@ func (r *AppReconciler) reconcile(ctx context.Context, app *ketchv1.App, logger
 	appChrt, err := chart.New(app,
 		chart.WithExposedPorts(app.ExposedPorts()),
 		chart.WithTemplates(*tpls),
-		chart.WithHPAMap(hpaMap))
+		chart.WithHPAMap(hpaMap),
+		chart.WithTraefikAPIGroup(r.traefikAPIGroup(app.Spec.Ingress.Controller)))
 	if err != nil {
 		return appReconcileResult{err: err}
 	}
@@ -500,7 +603,7 @@ func (r *AppReconciler) watchDeployEvents(ctx context.Context, app *ketchv1.App,
 	}
 
 	// wait for Deployment Generation
-	timeout := time.After(DefaultPodRunningTimeout)
+	timeout := time.After(deployTimeout(app, process.Name))
 	for wl.ObservedGeneration < wl.Generation {
 		wl, err = cli.Get(ctx)
 		if isCanceledError(err) {
@@ -764,6 +867,19 @@ func newInvalidPodPhaseError(ctx context.Context, cli kubernetes.Interface, pod
 	return retErr
 }
 
+// deployTimeout returns how long to wait for a rollout of the process to finish.
+// It's progressDeadlineSeconds of the process's rollout strategy if set, otherwise DefaultPodRunningTimeout.
+func deployTimeout(app *ketchv1.App, processName string) time.Duration {
+	if len(app.Spec.Deployments) == 0 {
+		return DefaultPodRunningTimeout
+	}
+	strategy := app.Spec.Deployments[len(app.Spec.Deployments)-1].RolloutStrategy(processName)
+	if strategy == nil || strategy.ProgressDeadlineSeconds == nil {
+		return DefaultPodRunningTimeout
+	}
+	return time.Duration(*strategy.ProgressDeadlineSeconds) * time.Second
+}
+
 // check if timeout has expired
 func timeoutExpired(t *metav1.Time, now time.Time) bool {
 	return t.Add(reconcileTimeout).Before(now)
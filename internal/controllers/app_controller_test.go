@@ -14,7 +14,9 @@ import (
 	autoscalingv2 "k8s.io/api/autoscaling/v2"
 	v1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -931,3 +933,172 @@ func TestUpdateNamespaceLabelsForIngress(t *testing.T) {
 		})
 	}
 }
+
+func Test_deployTimeout(t *testing.T) {
+	deadline := int32(120)
+	tests := []struct {
+		name string
+		app  *ketchv1.App
+		want time.Duration
+	}{
+		{
+			name: "no deployments",
+			app:  &ketchv1.App{},
+			want: DefaultPodRunningTimeout,
+		},
+		{
+			name: "no rollout strategy",
+			app: &ketchv1.App{Spec: ketchv1.AppSpec{Deployments: []ketchv1.AppDeploymentSpec{
+				{Processes: []ketchv1.ProcessSpec{{Name: "web"}}},
+			}}},
+			want: DefaultPodRunningTimeout,
+		},
+		{
+			name: "progressDeadlineSeconds of the latest deployment",
+			app: &ketchv1.App{Spec: ketchv1.AppSpec{Deployments: []ketchv1.AppDeploymentSpec{
+				{Processes: []ketchv1.ProcessSpec{{Name: "web"}}},
+				{Processes: []ketchv1.ProcessSpec{{Name: "web", Strategy: &ketchv1.RolloutStrategy{ProgressDeadlineSeconds: &deadline}}}},
+			}}},
+			want: 2 * time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, deployTimeout(tt.app, "web"))
+		})
+	}
+}
+
+func TestAppReconciler_traefikAPIGroup(t *testing.T) {
+	restMapper := func(groups ...string) meta.RESTMapper {
+		mapper := meta.NewDefaultRESTMapper(nil)
+		for _, group := range groups {
+			mapper.Add(schema.GroupVersionKind{Group: group, Version: "v1alpha1", Kind: "IngressRoute"}, meta.RESTScopeNamespace)
+		}
+		return mapper
+	}
+	tests := []struct {
+		name       string
+		controller ketchv1.IngressControllerSpec
+		restMapper meta.RESTMapper
+		want       string
+	}{
+		{
+			name:       "cluster serves both groups",
+			controller: ketchv1.IngressControllerSpec{IngressType: ketchv1.TraefikIngressControllerType},
+			restMapper: restMapper(ketchv1.TraefikAPIGroup, ketchv1.LegacyTraefikAPIGroup),
+			want:       ketchv1.TraefikAPIGroup,
+		},
+		{
+			name:       "cluster serves legacy group only",
+			controller: ketchv1.IngressControllerSpec{IngressType: ketchv1.TraefikIngressControllerType},
+			restMapper: restMapper(ketchv1.LegacyTraefikAPIGroup),
+			want:       ketchv1.LegacyTraefikAPIGroup,
+		},
+		{
+			name:       "group is configured explicitly",
+			controller: ketchv1.IngressControllerSpec{IngressType: ketchv1.TraefikIngressControllerType, TraefikAPIGroup: ketchv1.LegacyTraefikAPIGroup},
+			restMapper: restMapper(ketchv1.TraefikAPIGroup, ketchv1.LegacyTraefikAPIGroup),
+			want:       ketchv1.LegacyTraefikAPIGroup,
+		},
+		{
+			name:       "not traefik",
+			controller: ketchv1.IngressControllerSpec{IngressType: ketchv1.NginxIngressControllerType},
+			restMapper: restMapper(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := AppReconciler{
+				Client: ctrlFake.NewClientBuilder().WithRESTMapper(tt.restMapper).Build(),
+			}
+			require.Equal(t, tt.want, r.traefikAPIGroup(tt.controller))
+		})
+	}
+}
+
+func TestAppReconciler_cnameStatuses(t *testing.T) {
+	certificate := func(name, namespace string, status map[string]interface{}) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": name, "namespace": namespace},
+			"status":   status,
+		}}
+		obj.SetGroupVersionKind(certificateGVK)
+		return obj
+	}
+	ready := certificate("go-app-cname-www-theketch-io", "ketch-go-app", map[string]interface{}{
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "Ready", "status": "True", "message": "Certificate is up to date and has not expired"},
+		},
+		"notAfter": "2030-01-01T00:00:00Z",
+	})
+	failed := certificate("go-app-cname-api-theketch-io", "ketch-go-app", map[string]interface{}{
+		"conditions": []interface{}{
+			map[string]interface{}{"type": "Ready", "status": "False", "message": "Issuing certificate as Secret does not exist"},
+		},
+	})
+	pending := certificate("go-app-cname-admin-theketch-io", "ketch-go-app", map[string]interface{}{})
+	expires := metav1.NewTime(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	app := &ketchv1.App{
+		ObjectMeta: metav1.ObjectMeta{Name: "go-app"},
+		Spec: ketchv1.AppSpec{
+			Namespace: "ketch-go-app",
+			Ingress: ketchv1.IngressSpec{
+				Cnames: ketchv1.CnameList{
+					{Name: "theketch.io"},
+					{Name: "www.theketch.io", Secure: true},
+					{Name: "api.theketch.io", Secure: true},
+					{Name: "admin.theketch.io", Secure: true},
+					{Name: "docs.theketch.io", Secure: true},
+					{Name: "own.theketch.io", Secure: true, SecretName: "own-tls"},
+				},
+			},
+		},
+	}
+	r := AppReconciler{
+		Client: ctrlFake.NewClientBuilder().WithRuntimeObjects(ready, failed, pending).Build(),
+	}
+	want := []ketchv1.CnameStatus{
+		{Name: "www.theketch.io", SecretName: "go-app-cname-www-theketch-io", CertificateReady: true, CertificateExpires: &expires},
+		{Name: "api.theketch.io", SecretName: "go-app-cname-api-theketch-io", LastError: "Issuing certificate as Secret does not exist"},
+		{Name: "admin.theketch.io", SecretName: "go-app-cname-admin-theketch-io", LastError: "waiting for the certificate to be issued"},
+		{Name: "docs.theketch.io", SecretName: "go-app-cname-docs-theketch-io", LastError: `failed to get certificate: certificates.cert-manager.io "go-app-cname-docs-theketch-io" not found`},
+	}
+	require.Equal(t, want, r.cnameStatuses(context.Background(), app))
+}
+
+func TestAppReconciler_certificateBecomesReady(t *testing.T) {
+	certificate := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "go-app-cname-www-theketch-io", "namespace": "ketch-go-app"},
+	}}
+	certificate.SetGroupVersionKind(certificateGVK)
+	app := &ketchv1.App{
+		ObjectMeta: metav1.ObjectMeta{Name: "go-app"},
+		Spec: ketchv1.AppSpec{
+			Namespace: "ketch-go-app",
+			Ingress: ketchv1.IngressSpec{
+				Cnames: ketchv1.CnameList{{Name: "www.theketch.io", Secure: true}},
+			},
+		},
+	}
+	r := AppReconciler{
+		Client: ctrlFake.NewClientBuilder().WithRuntimeObjects(certificate).Build(),
+	}
+
+	statuses := r.cnameStatuses(context.Background(), app)
+	require.False(t, statuses[0].CertificateReady)
+	require.Equal(t, ctrl.Result{RequeueAfter: certificateCheckInterval}, requeueForCertificates(ctrl.Result{}, statuses))
+	require.Equal(t, ctrl.Result{RequeueAfter: certificateCheckInterval}, requeueForCertificates(ctrl.Result{RequeueAfter: reconcileTimeout}, statuses))
+	require.Equal(t, ctrl.Result{RequeueAfter: time.Second}, requeueForCertificates(ctrl.Result{RequeueAfter: time.Second}, statuses))
+
+	require.Nil(t, unstructured.SetNestedSlice(certificate.Object, []interface{}{
+		map[string]interface{}{"type": "Ready", "status": "True"},
+	}, "status", "conditions"))
+	require.Nil(t, r.Update(context.Background(), certificate))
+
+	statuses = r.cnameStatuses(context.Background(), app)
+	require.True(t, statuses[0].CertificateReady)
+	require.Equal(t, ctrl.Result{}, requeueForCertificates(ctrl.Result{}, statuses))
+	require.Equal(t, ctrl.Result{RequeueAfter: reconcileTimeout}, requeueForCertificates(ctrl.Result{RequeueAfter: reconcileTimeout}, statuses))
+}
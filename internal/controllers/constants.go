@@ -6,4 +6,6 @@ const (
 	KetchNamespace = "ketch-system"
 	// reconcileTimeout is the default timeout to trigger Operator reconcile
 	reconcileTimeout = 10 * time.Minute
+	// certificateCheckInterval is how often an app is reconciled while cert-manager certificates of its cnames aren't ready.
+	certificateCheckInterval = 30 * time.Second
 )
@@ -24,8 +24,8 @@ import (
 
 const (
 	defaultTrafficWeight = 100
-	minimumSteps         = 2
-	maximumSteps         = 100
+	minimumSteps         = ketchv1.MinCanarySteps
+	maximumSteps         = ketchv1.MaxCanarySteps
 	defaultProcFile      = "Procfile"
 )
 
@@ -34,6 +34,7 @@ type Client interface {
 	Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error
 	Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error
 	Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error
+	List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error
 }
 
 type SourceBuilderFn func(context.Context, *build.CreateImageFromSourceRequest, ...build.Option) error
@@ -82,6 +83,7 @@ func getAppWithUpdater(ctx context.Context, client Client, cs *ChangeSet) (*ketc
 			app.Spec.Ingress = ketchv1.IngressSpec{
 				GenerateDefaultCname: generateDefaultCName,
 				Cnames:               cname,
+				Controller:           app.Spec.Ingress.Controller,
 			}
 			return client.Create(ctx, app)
 		}, nil
@@ -183,6 +185,36 @@ func getUpdatedApp(ctx context.Context, client Client, cs *ChangeSet) (*ketchv1.
 			return err
 		}
 
+		scheduling, err := cs.getScheduling()
+		if err := assign(err, func() error {
+			app.Spec.Scheduling = scheduling
+			changed = true
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		profile, err := cs.getIngressProfile()
+		if err := assign(err, func() error {
+			if profile == app.Spec.Ingress.Controller.Profile && len(app.Spec.Ingress.Controller.IngressType) > 0 {
+				return nil
+			}
+			// the webhook only fills in an unconfigured controller and ignores missing profiles,
+			// a profile chosen with --ingress-profile replaces the controller and must exist.
+			controller, err := ketchv1.GetIngressControllerSpec(ctx, client, profile)
+			if apierrors.IsNotFound(err) {
+				return fmt.Errorf("ingress profile %q not found", profile)
+			}
+			if err != nil {
+				return err
+			}
+			app.Spec.Ingress.Controller = *controller
+			changed = true
+			return nil
+		}); err != nil {
+			return err
+		}
+
 		secret, err := cs.getDockerRegistrySecret()
 		if err := assign(err, func() error {
 			app.Spec.DockerRegistry.SecretName = secret
@@ -215,6 +247,10 @@ func deployImage(ctx context.Context, svc *Services, app *ketchv1.App, params *C
 	if err != nil {
 		return err
 	}
+	probes, _ := params.getProbes()
+	if ketchYaml, err = withProbes(ketchYaml, probes); err != nil {
+		return err
+	}
 
 	if len(app.Spec.Ingress.Controller.ClusterIssuer) == 0 && params.hasSecureCnames() {
 		return errors.New("secure cnames require a framework.Ingress.ClusterIssuer to be specified")
@@ -263,6 +299,8 @@ func deployImage(ctx context.Context, svc *Services, app *ketchv1.App, params *C
 	units, _ := params.getUnits()
 	version, _ := params.getVersion()
 	process, _ := params.getProcess()
+	targetProcess, _ := params.getTargetProcess()
+	resources, _ := params.getResources()
 
 	currentTime := time.Now()
 
@@ -282,6 +320,8 @@ func deployImage(ctx context.Context, svc *Services, app *ketchv1.App, params *C
 		version:           version,
 		process:           process,
 		processes:         params.processes,
+		targetProcess:     targetProcess,
+		resources:         resources,
 		volume:            volume,
 		volumes:           volumes,
 		volumeMounts:      volumeMounts,
@@ -304,6 +344,29 @@ func deployImage(ctx context.Context, svc *Services, app *ketchv1.App, params *C
 	return nil
 }
 
+// mergeResources returns a copy of current with the requests and limits from changes applied.
+func mergeResources(current, changes *v1.ResourceRequirements) *v1.ResourceRequirements {
+	var merged *v1.ResourceRequirements
+	if current != nil {
+		merged = current.DeepCopy()
+	} else {
+		merged = &v1.ResourceRequirements{}
+	}
+	for name, quantity := range changes.Requests {
+		if merged.Requests == nil {
+			merged.Requests = v1.ResourceList{}
+		}
+		merged.Requests[name] = quantity
+	}
+	for name, quantity := range changes.Limits {
+		if merged.Limits == nil {
+			merged.Limits = v1.ResourceList{}
+		}
+		merged.Limits[name] = quantity
+	}
+	return merged
+}
+
 func makeProcfile(cfg *registryv1.ConfigFile) (*chart.Procfile, error) {
 	if val, ok := cfg.Config.Labels["io.buildpacks.build.metadata"]; ok {
 		// the above label contains an escaped json string of build details
@@ -339,11 +402,28 @@ type updateAppCRDRequest struct {
 	version           int
 	process           string
 	processes         *[]ketchv1.ProcessSpec
+	targetProcess     string
+	resources         *v1.ResourceRequirements
 	volume            string
 	volumes           []v1.Volume
 	volumeMounts      []v1.VolumeMount
 }
 
+// hasResources returns true if the request changes resources of any process.
+func (r updateAppCRDRequest) hasResources() bool {
+	if r.resources != nil {
+		return true
+	}
+	if r.processes != nil {
+		for _, process := range *r.processes {
+			if process.Resources != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func updateAppCRD(ctx context.Context, svc *Services, appName string, args updateAppCRDRequest) (*ketchv1.App, error) {
 	var updated ketchv1.App
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
@@ -364,6 +444,9 @@ func updateAppCRD(ctx context.Context, svc *Services, appName string, args updat
 
 		// allow user to update units on canary deployments
 		if updated.Spec.Canary.Active {
+			if args.hasResources() {
+				return errors.New("resources can't be changed while a canary deployment is active, deploy them after the canary is finished")
+			}
 			if args.units > 0 {
 				s := ketchv1.NewSelector(args.version, args.process)
 				if err := updated.SetUnits(s, args.units); err != nil {
@@ -383,6 +466,19 @@ func updateAppCRD(ctx context.Context, svc *Services, appName string, args updat
 			}
 		}
 
+		if args.targetProcess != "" {
+			if _, ok := args.procFile.Processes[args.targetProcess]; !ok {
+				return fmt.Errorf("%w: %q", ketchv1.ErrProcessNotFound, args.targetProcess)
+			}
+		}
+		if args.processes != nil {
+			for _, process := range *args.processes {
+				if _, ok := args.procFile.Processes[process.Name]; !ok {
+					return fmt.Errorf("%w: %q", ketchv1.ErrProcessNotFound, process.Name)
+				}
+			}
+		}
+
 		processes := make([]ketchv1.ProcessSpec, 0, len(args.procFile.Processes))
 		for _, processName := range args.procFile.SortedNames() {
 			cmd := args.procFile.Processes[processName]
@@ -396,6 +492,55 @@ func updateAppCRD(ctx context.Context, svc *Services, appName string, args updat
 				ps.VolumeMounts = args.volumeMounts
 			}
 
+			// process-level env variables, resources, scheduling options, disruption budgets, rollout strategies
+			// and additional containers are kept
+			// between deployments unless they are explicitly changed.
+			if len(updated.Spec.Deployments) > 0 {
+				for _, previousProcess := range updated.Spec.Deployments[len(updated.Spec.Deployments)-1].Processes {
+					if previousProcess.Name == processName {
+						ps.Env = previousProcess.Env
+						ps.Resources = previousProcess.Resources
+						ps.Scheduling = previousProcess.Scheduling
+						ps.DisruptionBudget = previousProcess.DisruptionBudget
+						ps.Strategy = previousProcess.Strategy
+						ps.InitContainers = previousProcess.InitContainers
+						ps.Sidecars = previousProcess.Sidecars
+					}
+				}
+			}
+
+			if args.resources != nil && (args.targetProcess == "" || args.targetProcess == processName) {
+				ps.Resources = mergeResources(ps.Resources, args.resources)
+			}
+			if args.processes != nil {
+				for _, process := range *args.processes {
+					if process.Name != processName {
+						continue
+					}
+					if process.Resources != nil {
+						ps.Resources = process.Resources
+					}
+					if process.Scheduling != nil {
+						ps.Scheduling = process.Scheduling
+					}
+					if process.DisruptionBudget != nil {
+						ps.DisruptionBudget = process.DisruptionBudget
+					}
+					if process.Strategy != nil {
+						ps.Strategy = process.Strategy
+					}
+					if process.InitContainers != nil {
+						ps.InitContainers = process.InitContainers
+					}
+					if process.Sidecars != nil {
+						ps.Sidecars = process.Sidecars
+					}
+				}
+			}
+			if err := validateResources(ps.Resources); err != nil {
+				return fmt.Errorf("process %q: %w", processName, err)
+			}
+
 			if usePreviousDeploymentSpecs {
 				for _, previousProcess := range updated.Spec.Deployments[0].Processes {
 					// if the process names for the new and previous deployments match update units to
@@ -474,6 +619,9 @@ func updateAppCRD(ctx context.Context, svc *Services, appName string, args updat
 				}
 			}
 		}
+		if err := updated.Validate(); err != nil {
+			return err
+		}
 		return svc.Client.Update(ctx, &updated)
 	})
 	return &updated, err
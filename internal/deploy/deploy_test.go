@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	registryv1 "github.com/google/go-containerregistry/pkg/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -89,6 +91,14 @@ func (m *mockClient) Update(ctx context.Context, obj client.Object, opts ...clie
 	panic("unhandled type")
 }
 
+func (m *mockClient) List(_ context.Context, list client.ObjectList, _ ...client.ListOption) error {
+	switch list.(type) {
+	case *ketchv1.AppList:
+		return nil
+	}
+	panic("unhandled type")
+}
+
 func Test_updateAppCRD(t *testing.T) {
 	type args struct {
 		ctx     context.Context
@@ -291,6 +301,135 @@ func Test_updateAppCRD(t *testing.T) {
 				require.Equal(t, mock.app.Spec.Deployments[0].Version, ketchv1.DeploymentVersion(1))
 			},
 		},
+		{
+			name: "resources are merged into the target process and kept for other processes",
+			args: args{
+				ctx:     context.Background(),
+				appName: "test-app",
+				args: updateAppCRDRequest{
+					image: "test/pack-test:latest",
+					procFile: &chart.Procfile{
+						Processes:           map[string][]string{"web": {"web"}, "worker": {"worker"}},
+						RoutableProcessName: "web",
+					},
+					configFile: &registryv1.ConfigFile{
+						Config: registryv1.Config{
+							ExposedPorts: make(map[string]struct{}),
+						},
+					},
+					targetProcess: "web",
+					resources: &v1.ResourceRequirements{
+						Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("512Mi")},
+					},
+				},
+				svc: &Services{
+					Client: func() *mockClient {
+						m := newMockClient()
+						m.app.Spec.DeploymentsCount = 1
+						m.app.Spec.Deployments = []ketchv1.AppDeploymentSpec{
+							{
+								Image:   "shipa/go-sample:latest",
+								Version: 1,
+								Processes: []ketchv1.ProcessSpec{
+									{
+										Name: "web",
+										Cmd:  []string{"web"},
+										Resources: &v1.ResourceRequirements{
+											Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("250m")},
+										},
+									},
+									{
+										Name: "worker",
+										Cmd:  []string{"worker"},
+										Resources: &v1.ResourceRequirements{
+											Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")},
+										},
+									},
+								},
+							},
+						}
+						return m
+					}(),
+				},
+			},
+			validate: func(t *testing.T, mock *mockClient) {
+				require.Equal(t, &v1.ResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("250m")},
+					Limits:   v1.ResourceList{v1.ResourceMemory: resource.MustParse("512Mi")},
+				}, mock.app.Spec.Deployments[0].Processes[0].Resources)
+				require.Equal(t, &v1.ResourceRequirements{
+					Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")},
+				}, mock.app.Spec.Deployments[0].Processes[1].Resources)
+			},
+		},
+		{
+			name: "resources for unknown process",
+			args: args{
+				ctx:     context.Background(),
+				appName: "test-app",
+				args: updateAppCRDRequest{
+					image: "test/pack-test:latest",
+					procFile: &chart.Procfile{
+						Processes:           map[string][]string{"web": {"web"}},
+						RoutableProcessName: "web",
+					},
+					targetProcess: "worker",
+					resources: &v1.ResourceRequirements{
+						Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("512Mi")},
+					},
+				},
+				svc: &Services{
+					Client: newMockClient(),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "resources of application.yaml processes for unknown process",
+			args: args{
+				ctx:     context.Background(),
+				appName: "test-app",
+				args: updateAppCRDRequest{
+					image: "test/pack-test:latest",
+					procFile: &chart.Procfile{
+						Processes:           map[string][]string{"web": {"web"}},
+						RoutableProcessName: "web",
+					},
+					processes: &[]ketchv1.ProcessSpec{
+						{
+							Name: "worker",
+							Resources: &v1.ResourceRequirements{
+								Limits: v1.ResourceList{v1.ResourceMemory: resource.MustParse("512Mi")},
+							},
+						},
+					},
+				},
+				svc: &Services{
+					Client: newMockClient(),
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "resources while canary is active",
+			args: args{
+				ctx:     context.Background(),
+				appName: "test-app",
+				args: updateAppCRDRequest{
+					resources: &v1.ResourceRequirements{
+						Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("250m")},
+					},
+				},
+				svc: &Services{
+					Client: func() *mockClient {
+						m := newMockClient()
+						m.app.Spec.Canary.Active = true
+						return m
+					}(),
+				},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -11,6 +11,7 @@ import (
 
 	"github.com/spf13/pflag"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes"
 	"sigs.k8s.io/yaml"
@@ -43,6 +44,21 @@ const (
 	FlagUnits              = "units"
 	FlagVersion            = "unit-version"
 	FlagProcess            = "unit-process"
+	FlagTargetProcess      = "process"
+	FlagCPU                = "cpu"
+	FlagMemory             = "memory"
+	FlagCPULimit           = "cpu-limit"
+	FlagMemoryLimit        = "memory-limit"
+	FlagReadinessHTTP      = "readiness-http"
+	FlagReadinessTCP       = "readiness-tcp"
+	FlagReadinessExec      = "readiness-exec"
+	FlagLivenessHTTP       = "liveness-http"
+	FlagLivenessTCP        = "liveness-tcp"
+	FlagLivenessExec       = "liveness-exec"
+	FlagStartupHTTP        = "startup-http"
+	FlagStartupTCP         = "startup-tcp"
+	FlagStartupExec        = "startup-exec"
+	FlagIngressProfile     = "ingress-profile"
 
 	FlagAppShort         = "a"
 	FlagImageShort       = "i"
@@ -104,6 +120,24 @@ type Options struct {
 	Units   int
 	Version int
 	Process string
+
+	TargetProcess string
+	CPU           string
+	Memory        string
+	CPULimit      string
+	MemoryLimit   string
+
+	ReadinessHTTP string
+	ReadinessTCP  string
+	ReadinessExec string
+	LivenessHTTP  string
+	LivenessTCP   string
+	LivenessExec  string
+	StartupHTTP   string
+	StartupTCP    string
+	StartupExec   string
+
+	IngressProfile string
 }
 
 type ChangeSet struct {
@@ -131,12 +165,25 @@ type ChangeSet struct {
 
 	appVersion    *string
 	appType       *string
+	scheduling    *ketchv1.SchedulingSpec
 	processes     *[]ketchv1.ProcessSpec
 	ketchYamlData *ketchv1.KetchYamlData
 	cname         *ketchv1.CnameList
 	units         *int
 	version       *int
 	process       *string
+
+	targetProcess *string
+	cpu           *string
+	memory        *string
+	cpuLimit      *string
+	memoryLimit   *string
+
+	// probeFlags contains values of the probe flags that have been set, keyed by flag name.
+	probeFlags map[string]string
+	probes     *Probes
+
+	ingressProfile *string
 }
 
 func (o Options) GetChangeSet(flags *pflag.FlagSet) *ChangeSet {
@@ -211,12 +258,49 @@ func (o Options) GetChangeSet(flags *pflag.FlagSet) *ChangeSet {
 		FlagProcess: func(c *ChangeSet) {
 			c.process = &o.Process
 		},
+		FlagTargetProcess: func(c *ChangeSet) {
+			c.targetProcess = &o.TargetProcess
+		},
+		FlagCPU: func(c *ChangeSet) {
+			c.cpu = &o.CPU
+		},
+		FlagMemory: func(c *ChangeSet) {
+			c.memory = &o.Memory
+		},
+		FlagCPULimit: func(c *ChangeSet) {
+			c.cpuLimit = &o.CPULimit
+		},
+		FlagMemoryLimit: func(c *ChangeSet) {
+			c.memoryLimit = &o.MemoryLimit
+		},
+		FlagIngressProfile: func(c *ChangeSet) {
+			c.ingressProfile = &o.IngressProfile
+		},
 	}
 	for k, f := range m {
 		if flags.Changed(k) {
 			f(&cs)
 		}
 	}
+	probeValues := map[string]string{
+		FlagReadinessHTTP: o.ReadinessHTTP,
+		FlagReadinessTCP:  o.ReadinessTCP,
+		FlagReadinessExec: o.ReadinessExec,
+		FlagLivenessHTTP:  o.LivenessHTTP,
+		FlagLivenessTCP:   o.LivenessTCP,
+		FlagLivenessExec:  o.LivenessExec,
+		FlagStartupHTTP:   o.StartupHTTP,
+		FlagStartupTCP:    o.StartupTCP,
+		FlagStartupExec:   o.StartupExec,
+	}
+	for flag, value := range probeValues {
+		if flags.Changed(flag) {
+			if cs.probeFlags == nil {
+				cs.probeFlags = make(map[string]string)
+			}
+			cs.probeFlags[flag] = value
+		}
+	}
 	return &cs
 }
 
@@ -227,6 +311,16 @@ func (c *ChangeSet) getDescription() (string, error) {
 	return *c.description, nil
 }
 
+func (c *ChangeSet) getIngressProfile() (string, error) {
+	if c.ingressProfile == nil {
+		return "", newMissingError(FlagIngressProfile)
+	}
+	if err := ketchv1.ValidateIngressProfile(*c.ingressProfile); err != nil {
+		return "", err
+	}
+	return *c.ingressProfile, nil
+}
+
 func (c *ChangeSet) getYamlPath() (string, error) {
 	if c.ketchYamlFileName == nil {
 		return "", newMissingError(FlagKetchYaml)
@@ -385,6 +479,74 @@ func (c *ChangeSet) getProcess() (string, error) {
 	return *c.process, nil
 }
 
+// getTargetProcess returns the process the per-process settings like resources are applied to.
+// An empty string means all processes of the app.
+func (c *ChangeSet) getTargetProcess() (string, error) {
+	if c.targetProcess == nil {
+		return "", nil
+	}
+	if c.cpu == nil && c.memory == nil && c.cpuLimit == nil && c.memoryLimit == nil {
+		return "", fmt.Errorf("%w %s must be used with one of %s, %s, %s or %s flags",
+			newInvalidUsageError(FlagTargetProcess), FlagTargetProcess, FlagCPU, FlagMemory, FlagCPULimit, FlagMemoryLimit)
+	}
+	return *c.targetProcess, nil
+}
+
+// getResources returns the resource requirements set with the cpu and memory flags.
+func (c *ChangeSet) getResources() (*v1.ResourceRequirements, error) {
+	if c.cpu == nil && c.memory == nil && c.cpuLimit == nil && c.memoryLimit == nil {
+		return nil, newMissingError(FlagCPU)
+	}
+	resources := &v1.ResourceRequirements{}
+	items := []struct {
+		flag  string
+		value *string
+		name  v1.ResourceName
+		list  *v1.ResourceList
+	}{
+		{flag: FlagCPU, value: c.cpu, name: v1.ResourceCPU, list: &resources.Requests},
+		{flag: FlagMemory, value: c.memory, name: v1.ResourceMemory, list: &resources.Requests},
+		{flag: FlagCPULimit, value: c.cpuLimit, name: v1.ResourceCPU, list: &resources.Limits},
+		{flag: FlagMemoryLimit, value: c.memoryLimit, name: v1.ResourceMemory, list: &resources.Limits},
+	}
+	for _, item := range items {
+		if item.value == nil {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(*item.value)
+		if err != nil || quantity.Sign() <= 0 {
+			return nil, fmt.Errorf("%w %s must be a positive quantity like 100m or 128Mi",
+				newInvalidValueError(item.flag), item.flag)
+		}
+		if *item.list == nil {
+			*item.list = v1.ResourceList{}
+		}
+		(*item.list)[item.name] = quantity
+	}
+	if err := validateResources(resources); err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
+
+// validateResources checks that requests don't exceed limits.
+func validateResources(resources *v1.ResourceRequirements) error {
+	if resources == nil {
+		return nil
+	}
+	for name, request := range resources.Requests {
+		limit, ok := resources.Limits[name]
+		if !ok {
+			continue
+		}
+		if request.Cmp(limit) > 0 {
+			return fmt.Errorf("%w %s request %s must be less than or equal to %s limit %s",
+				newInvalidValueError(string(name)), name, request.String(), name, limit.String())
+		}
+	}
+	return nil
+}
+
 func (c *ChangeSet) getVolumeName() (string, error) {
 	if c.volume == nil {
 		return "", newMissingError(FlagVolume)
@@ -473,6 +635,13 @@ func (c *ChangeSet) getRunAsUser() (int64, error) {
 	return *c.runAsUser, nil
 }
 
+func (c *ChangeSet) getScheduling() (*ketchv1.SchedulingSpec, error) {
+	if c.scheduling == nil {
+		return nil, newMissingError("scheduling")
+	}
+	return c.scheduling, nil
+}
+
 func (c *ChangeSet) getBuildPacks() ([]string, error) {
 	if c.buildPacks == nil {
 		return nil, newMissingError(FlagBuildPacks)
@@ -480,6 +649,50 @@ func (c *ChangeSet) getBuildPacks() ([]string, error) {
 	return *c.buildPacks, nil
 }
 
+// probeFlags describes which probe and which kind of probe each probe flag defines.
+var probeFlags = []struct {
+	flag  string
+	kind  string
+	probe func(p *Probes) **ProbeShorthand
+}{
+	{FlagReadinessHTTP, "http", func(p *Probes) **ProbeShorthand { return &p.Readiness }},
+	{FlagReadinessTCP, "tcp", func(p *Probes) **ProbeShorthand { return &p.Readiness }},
+	{FlagReadinessExec, "exec", func(p *Probes) **ProbeShorthand { return &p.Readiness }},
+	{FlagLivenessHTTP, "http", func(p *Probes) **ProbeShorthand { return &p.Liveness }},
+	{FlagLivenessTCP, "tcp", func(p *Probes) **ProbeShorthand { return &p.Liveness }},
+	{FlagLivenessExec, "exec", func(p *Probes) **ProbeShorthand { return &p.Liveness }},
+	{FlagStartupHTTP, "http", func(p *Probes) **ProbeShorthand { return &p.Startup }},
+	{FlagStartupTCP, "tcp", func(p *Probes) **ProbeShorthand { return &p.Startup }},
+	{FlagStartupExec, "exec", func(p *Probes) **ProbeShorthand { return &p.Startup }},
+}
+
+// getProbes returns probes defined in the application yaml with the probes set by flags replacing them.
+func (c *ChangeSet) getProbes() (*Probes, error) {
+	if c.probes == nil && len(c.probeFlags) == 0 {
+		return nil, newMissingError(FlagReadinessHTTP)
+	}
+	flagProbes := &Probes{}
+	for _, pf := range probeFlags {
+		value, ok := c.probeFlags[pf.flag]
+		if !ok {
+			continue
+		}
+		target := pf.probe(flagProbes)
+		if *target != nil {
+			return nil, fmt.Errorf("%w only one of http, tcp and exec can be set for a probe", newInvalidUsageError(pf.flag))
+		}
+		probe, err := parseProbeFlag(pf.kind, value)
+		if err == nil {
+			_, err = probe.probe()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w %s", newInvalidValueError(pf.flag), err)
+		}
+		*target = probe
+	}
+	return c.probes.merge(flagProbes), nil
+}
+
 func (c *ChangeSet) getKetchYaml() (*ketchv1.KetchYamlData, error) {
 	if c.ketchYamlData != nil {
 		return c.ketchYamlData, nil
@@ -518,6 +731,13 @@ func (c *ChangeSet) getKetchYaml() (*ketchv1.KetchYamlData, error) {
 	if err = yaml.Unmarshal(content, data, decodeOpts...); err != nil {
 		return nil, err
 	}
+	if data.Kubernetes != nil {
+		for name, process := range data.Kubernetes.Processes {
+			if err := process.Strategy.Validate(); err != nil {
+				return nil, fmt.Errorf("%w process %q: %s", newInvalidValueError(FlagKetchYaml), name, err)
+			}
+		}
+	}
 	return data, nil
 }
 
@@ -7,6 +7,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 func intRef(i int) *int {
@@ -310,3 +311,143 @@ func TestChangeSet_getRunAsUser(t *testing.T) {
 		})
 	}
 }
+
+func TestChangeSet_getResources(t *testing.T) {
+	tests := []struct {
+		name    string
+		set     ChangeSet
+		want    *v1.ResourceRequirements
+		wantErr string
+	}{
+		{
+			name:    "no resource flags",
+			set:     ChangeSet{},
+			wantErr: `"cpu" missing`,
+		},
+		{
+			name: "requests and limits",
+			set:  ChangeSet{cpu: stringRef("250m"), memory: stringRef("128Mi"), memoryLimit: stringRef("512Mi")},
+			want: &v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse("250m"),
+					v1.ResourceMemory: resource.MustParse("128Mi"),
+				},
+				Limits: v1.ResourceList{
+					v1.ResourceMemory: resource.MustParse("512Mi"),
+				},
+			},
+		},
+		{
+			name:    "invalid quantity",
+			set:     ChangeSet{cpuLimit: stringRef("a lot")},
+			wantErr: `"cpu-limit" invalid value cpu-limit must be a positive quantity like 100m or 128Mi`,
+		},
+		{
+			name:    "request greater than limit",
+			set:     ChangeSet{cpu: stringRef("2"), cpuLimit: stringRef("500m")},
+			wantErr: `"cpu" invalid value cpu request 2 must be less than or equal to cpu limit 500m`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.set.getResources()
+			if len(tt.wantErr) > 0 {
+				require.NotNil(t, err)
+				require.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestChangeSet_getTargetProcess(t *testing.T) {
+	cs := ChangeSet{targetProcess: stringRef("web")}
+	_, err := cs.getTargetProcess()
+	require.Equal(t, `"process" used improperly process must be used with one of cpu, memory, cpu-limit or memory-limit flags`, err.Error())
+
+	cs.cpu = stringRef("100m")
+	process, err := cs.getTargetProcess()
+	require.Nil(t, err)
+	require.Equal(t, "web", process)
+}
+
+func TestChangeSet_getProbes(t *testing.T) {
+	tests := []struct {
+		name    string
+		set     ChangeSet
+		want    *Probes
+		wantErr string
+	}{
+		{
+			name:    "no probes",
+			set:     ChangeSet{},
+			wantErr: `"readiness-http" missing`,
+		},
+		{
+			name: "probe flags",
+			set: ChangeSet{probeFlags: map[string]string{
+				FlagReadinessHTTP: "/healthz:8080,period=10",
+				FlagStartupExec:   "cat /tmp/ready",
+			}},
+			want: &Probes{
+				Readiness: &ProbeShorthand{HTTP: "/healthz:8080", PeriodSeconds: 10},
+				Startup:   &ProbeShorthand{Exec: "cat /tmp/ready"},
+			},
+		},
+		{
+			name: "flags override yaml probes",
+			set: ChangeSet{
+				probes: &Probes{
+					Readiness: &ProbeShorthand{HTTP: "/ready:8080"},
+					Liveness:  &ProbeShorthand{TCP: "8080"},
+				},
+				probeFlags: map[string]string{FlagLivenessHTTP: "/healthz:8080"},
+			},
+			want: &Probes{
+				Readiness: &ProbeShorthand{HTTP: "/ready:8080"},
+				Liveness:  &ProbeShorthand{HTTP: "/healthz:8080"},
+			},
+		},
+		{
+			name: "two kinds of the same probe",
+			set: ChangeSet{probeFlags: map[string]string{
+				FlagLivenessHTTP: "/healthz:8080",
+				FlagLivenessTCP:  "8080",
+			}},
+			wantErr: `"liveness-tcp" used improperly only one of http, tcp and exec can be set for a probe`,
+		},
+		{
+			name:    "invalid target",
+			set:     ChangeSet{probeFlags: map[string]string{FlagReadinessHTTP: "/healthz"}},
+			wantErr: `"readiness-http" invalid value http probe "/healthz" should have PATH:PORT format`,
+		},
+		{
+			name: "exec command with commas",
+			set: ChangeSet{probeFlags: map[string]string{
+				FlagLivenessExec: `sh -c 'curl -s localhost:8080/health | grep -q "ok,ready"',period=10`,
+			}},
+			want: &Probes{
+				Liveness: &ProbeShorthand{Exec: `sh -c 'curl -s localhost:8080/health | grep -q "ok,ready"'`, PeriodSeconds: 10},
+			},
+		},
+		{
+			name:    "invalid option",
+			set:     ChangeSet{probeFlags: map[string]string{FlagReadinessTCP: "8080,retries=3"}},
+			wantErr: `"readiness-tcp" invalid value unknown option "retries=3", expected one of delay, failure, period, success, timeout`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.set.getProbes()
+			if len(tt.wantErr) > 0 {
+				require.NotNil(t, err)
+				require.Equal(t, tt.wantErr, err.Error())
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/shlex"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
+)
+
+// Probes contains shorthand definitions of readiness, liveness and startup probes.
+type Probes struct {
+	Readiness *ProbeShorthand `json:"readiness,omitempty"`
+	Liveness  *ProbeShorthand `json:"liveness,omitempty"`
+	Startup   *ProbeShorthand `json:"startup,omitempty"`
+}
+
+// ProbeShorthand is a compact definition of a probe.
+// Exactly one of HTTP, TCP and Exec must be set.
+type ProbeShorthand struct {
+	// HTTP is a path and a port of an http endpoint in the PATH:PORT format, e.g. /healthz:8080.
+	HTTP string `json:"http,omitempty"`
+	// TCP is a port to open a tcp connection to.
+	TCP string `json:"tcp,omitempty"`
+	// Exec is a command to run inside the container, arguments are separated by spaces
+	// and can be quoted or escaped like in a shell, e.g. sh -c 'test -f /tmp/ready'.
+	Exec string `json:"exec,omitempty"`
+
+	InitialDelaySeconds int32 `json:"initialDelaySeconds,omitempty"`
+	PeriodSeconds       int32 `json:"periodSeconds,omitempty"`
+	TimeoutSeconds      int32 `json:"timeoutSeconds,omitempty"`
+	SuccessThreshold    int32 `json:"successThreshold,omitempty"`
+	FailureThreshold    int32 `json:"failureThreshold,omitempty"`
+}
+
+// probeFlagOptions maps the options accepted after the target of a probe flag to the corresponding fields.
+var probeFlagOptions = map[string]func(p *ProbeShorthand) *int32{
+	"delay":   func(p *ProbeShorthand) *int32 { return &p.InitialDelaySeconds },
+	"period":  func(p *ProbeShorthand) *int32 { return &p.PeriodSeconds },
+	"timeout": func(p *ProbeShorthand) *int32 { return &p.TimeoutSeconds },
+	"success": func(p *ProbeShorthand) *int32 { return &p.SuccessThreshold },
+	"failure": func(p *ProbeShorthand) *int32 { return &p.FailureThreshold },
+}
+
+// parseProbeFlag parses a value of a probe flag like --readiness-http.
+// The value is a target optionally followed by comma separated thresholds, e.g. "/healthz:8080,period=10,failure=3".
+func parseProbeFlag(kind, value string) (*ProbeShorthand, error) {
+	parts := splitProbeFlag(value)
+	probe := &ProbeShorthand{}
+	switch kind {
+	case "http":
+		probe.HTTP = parts[0]
+	case "tcp":
+		probe.TCP = parts[0]
+	case "exec":
+		probe.Exec = parts[0]
+	}
+	for _, option := range parts[1:] {
+		name, number, found := strings.Cut(option, "=")
+		field, ok := probeFlagOptions[name]
+		if !found || !ok {
+			return nil, fmt.Errorf("unknown option %q, expected one of delay, failure, period, success, timeout", option)
+		}
+		n, err := strconv.ParseInt(number, 10, 32)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("option %q must be a non-negative number", name)
+		}
+		*field(probe) = int32(n)
+	}
+	return probe, nil
+}
+
+// splitProbeFlag splits a value of a probe flag on commas which aren't quoted or escaped,
+// so an exec command can contain commas, e.g. "sh -c 'echo a,b',period=10".
+func splitProbeFlag(value string) []string {
+	var parts []string
+	var quote rune
+	escaped := false
+	start := 0
+	for i, r := range value {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == ',':
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, value[start:])
+}
+
+// probe converts the shorthand to a kubernetes probe.
+func (p *ProbeShorthand) probe() (*v1.Probe, error) {
+	if p == nil {
+		return nil, nil
+	}
+	probe := &v1.Probe{
+		InitialDelaySeconds: p.InitialDelaySeconds,
+		PeriodSeconds:       p.PeriodSeconds,
+		TimeoutSeconds:      p.TimeoutSeconds,
+		SuccessThreshold:    p.SuccessThreshold,
+		FailureThreshold:    p.FailureThreshold,
+	}
+	handlers := 0
+	if len(p.HTTP) > 0 {
+		handlers++
+		idx := strings.LastIndex(p.HTTP, ":")
+		if idx < 0 {
+			return nil, fmt.Errorf("http probe %q should have PATH:PORT format", p.HTTP)
+		}
+		port, err := probePort(p.HTTP[idx+1:])
+		if err != nil {
+			return nil, err
+		}
+		path := p.HTTP[:idx]
+		if len(path) == 0 {
+			path = "/"
+		}
+		if !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("http probe path %q should start with /", path)
+		}
+		probe.HTTPGet = &v1.HTTPGetAction{Path: path, Port: port}
+	}
+	if len(p.TCP) > 0 {
+		handlers++
+		port, err := probePort(p.TCP)
+		if err != nil {
+			return nil, err
+		}
+		probe.TCPSocket = &v1.TCPSocketAction{Port: port}
+	}
+	if len(p.Exec) > 0 {
+		handlers++
+		command, err := shlex.Split(p.Exec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exec probe command %q: %w", p.Exec, err)
+		}
+		if len(command) == 0 {
+			return nil, errors.New("exec probe command is required")
+		}
+		probe.Exec = &v1.ExecAction{Command: command}
+	}
+	if handlers != 1 {
+		return nil, errors.New("a probe should have exactly one of http, tcp and exec")
+	}
+	return probe, nil
+}
+
+func probePort(value string) (intstr.IntOrString, error) {
+	if port, err := strconv.Atoi(value); err == nil {
+		if port < 1 || port > 65535 {
+			return intstr.IntOrString{}, fmt.Errorf("invalid probe port %d", port)
+		}
+		return intstr.FromInt(port), nil
+	}
+	if len(value) == 0 {
+		return intstr.IntOrString{}, errors.New("probe port is required")
+	}
+	// a named container port.
+	return intstr.FromString(value), nil
+}
+
+// merge returns probes with the probes set in override replacing the ones of p.
+func (p *Probes) merge(override *Probes) *Probes {
+	if p == nil {
+		return override
+	}
+	if override == nil {
+		return p
+	}
+	result := *p
+	if override.Readiness != nil {
+		result.Readiness = override.Readiness
+	}
+	if override.Liveness != nil {
+		result.Liveness = override.Liveness
+	}
+	if override.Startup != nil {
+		result.Startup = override.Startup
+	}
+	return &result
+}
+
+func (p *Probes) validate() error {
+	_, err := withProbes(nil, p)
+	return err
+}
+
+// withProbes returns a copy of ketchYaml with the given probes replacing the corresponding probes of its healthcheck.
+func withProbes(ketchYaml *ketchv1.KetchYamlData, probes *Probes) (*ketchv1.KetchYamlData, error) {
+	if probes == nil {
+		return ketchYaml, nil
+	}
+	readiness, err := probes.Readiness.probe()
+	if err != nil {
+		return nil, fmt.Errorf("readiness: %w", err)
+	}
+	liveness, err := probes.Liveness.probe()
+	if err != nil {
+		return nil, fmt.Errorf("liveness: %w", err)
+	}
+	startup, err := probes.Startup.probe()
+	if err != nil {
+		return nil, fmt.Errorf("startup: %w", err)
+	}
+	result := &ketchv1.KetchYamlData{}
+	if ketchYaml != nil {
+		result = ketchYaml.DeepCopy()
+	}
+	if result.Healthcheck == nil {
+		result.Healthcheck = &ketchv1.KetchYamlHealthcheck{}
+	}
+	if readiness != nil {
+		result.Healthcheck.ReadinessProbe = readiness
+	}
+	if liveness != nil {
+		result.Healthcheck.LivenessProbe = liveness
+	}
+	if startup != nil {
+		result.Healthcheck.StartupProbe = startup
+	}
+	return result, nil
+}
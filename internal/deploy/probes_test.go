@@ -0,0 +1,126 @@
+package deploy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
+)
+
+func TestProbeShorthand_probe(t *testing.T) {
+	tests := []struct {
+		name    string
+		probe   *ProbeShorthand
+		want    *v1.Probe
+		wantErr string
+	}{
+		{
+			name: "no probe",
+		},
+		{
+			name:  "http",
+			probe: &ProbeShorthand{HTTP: "/healthz:8080", PeriodSeconds: 10, FailureThreshold: 3},
+			want: &v1.Probe{
+				ProbeHandler:     v1.ProbeHandler{HTTPGet: &v1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt(8080)}},
+				PeriodSeconds:    10,
+				FailureThreshold: 3,
+			},
+		},
+		{
+			name:  "http without path, named port",
+			probe: &ProbeShorthand{HTTP: ":http"},
+			want: &v1.Probe{
+				ProbeHandler: v1.ProbeHandler{HTTPGet: &v1.HTTPGetAction{Path: "/", Port: intstr.FromString("http")}},
+			},
+		},
+		{
+			name:  "tcp",
+			probe: &ProbeShorthand{TCP: "5432", InitialDelaySeconds: 5},
+			want: &v1.Probe{
+				ProbeHandler:        v1.ProbeHandler{TCPSocket: &v1.TCPSocketAction{Port: intstr.FromInt(5432)}},
+				InitialDelaySeconds: 5,
+			},
+		},
+		{
+			name:  "exec",
+			probe: &ProbeShorthand{Exec: "cat /tmp/healthy"},
+			want: &v1.Probe{
+				ProbeHandler: v1.ProbeHandler{Exec: &v1.ExecAction{Command: []string{"cat", "/tmp/healthy"}}},
+			},
+		},
+		{
+			name:  "exec with quoted arguments",
+			probe: &ProbeShorthand{Exec: `sh -c 'test -f /tmp/ready && echo "a, b"' --verbose\ mode`},
+			want: &v1.Probe{
+				ProbeHandler: v1.ProbeHandler{Exec: &v1.ExecAction{Command: []string{"sh", "-c", `test -f /tmp/ready && echo "a, b"`, "--verbose mode"}}},
+			},
+		},
+		{
+			name:    "exec with an unterminated quote",
+			probe:   &ProbeShorthand{Exec: `sh -c 'test -f /tmp/ready`},
+			wantErr: `invalid exec probe command "sh -c 'test -f /tmp/ready": EOF found when expecting closing quote`,
+		},
+		{
+			name:    "several handlers",
+			probe:   &ProbeShorthand{TCP: "5432", Exec: "true"},
+			wantErr: "a probe should have exactly one of http, tcp and exec",
+		},
+		{
+			name:    "invalid port",
+			probe:   &ProbeShorthand{TCP: "70000"},
+			wantErr: "invalid probe port 70000",
+		},
+		{
+			name:    "invalid path",
+			probe:   &ProbeShorthand{HTTP: "healthz:8080"},
+			wantErr: `http probe path "healthz" should start with /`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.probe.probe()
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWithProbes(t *testing.T) {
+	ketchYaml := &ketchv1.KetchYamlData{
+		Hooks: &ketchv1.KetchYamlHooks{Restart: ketchv1.KetchYamlRestartHooks{Before: []string{"migrate"}}},
+		Healthcheck: &ketchv1.KetchYamlHealthcheck{
+			LivenessProbe:  &v1.Probe{PeriodSeconds: 30},
+			ReadinessProbe: &v1.Probe{PeriodSeconds: 30},
+		},
+	}
+	got, err := withProbes(ketchYaml, &Probes{Readiness: &ProbeShorthand{TCP: "8080"}})
+	require.Nil(t, err)
+	require.Equal(t, &ketchv1.KetchYamlData{
+		Hooks: &ketchv1.KetchYamlHooks{Restart: ketchv1.KetchYamlRestartHooks{Before: []string{"migrate"}}},
+		Healthcheck: &ketchv1.KetchYamlHealthcheck{
+			LivenessProbe:  &v1.Probe{PeriodSeconds: 30},
+			ReadinessProbe: &v1.Probe{ProbeHandler: v1.ProbeHandler{TCPSocket: &v1.TCPSocketAction{Port: intstr.FromInt(8080)}}},
+		},
+	}, got)
+	// the original ketch.yaml is not modified.
+	require.Equal(t, int32(30), ketchYaml.Healthcheck.ReadinessProbe.PeriodSeconds)
+
+	got, err = withProbes(nil, &Probes{Startup: &ProbeShorthand{Exec: "true"}})
+	require.Nil(t, err)
+	require.Equal(t, &ketchv1.KetchYamlData{
+		Healthcheck: &ketchv1.KetchYamlHealthcheck{
+			StartupProbe: &v1.Probe{ProbeHandler: v1.ProbeHandler{Exec: &v1.ExecAction{Command: []string{"true"}}}},
+		},
+	}, got)
+
+	got, err = withProbes(ketchYaml, nil)
+	require.Nil(t, err)
+	require.Equal(t, ketchYaml, got)
+}
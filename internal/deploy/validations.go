@@ -7,6 +7,8 @@ import (
 	"os"
 	"path"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
 	kerrs "github.com/theketchio/ketch/internal/errors"
 	"github.com/theketchio/ketch/internal/validation"
@@ -118,6 +120,27 @@ func validateDeploy(cs *ChangeSet, app *ketchv1.App) error {
 		}
 	}
 
+	_, err = cs.getProbes()
+	if !isMissing(err) {
+		if !isValid(err) {
+			return err
+		}
+	}
+
+	// Resources Validations
+
+	_, err = cs.getTargetProcess()
+	if !isValid(err) {
+		return err
+	}
+
+	_, err = cs.getResources()
+	if !isMissing(err) {
+		if !isValid(err) {
+			return err
+		}
+	}
+
 	// Volume Validations
 
 	_, err = cs.getVolumeName()
@@ -193,6 +216,19 @@ func validateCreateApp(ctx context.Context, client Client, appName string, cs *C
 	if _, err := cs.getImage(); err != nil {
 		return err
 	}
+	if cs.cname != nil {
+		var apps ketchv1.AppList
+		if err := client.List(ctx, &apps); err != nil {
+			return err
+		}
+		app := ketchv1.App{
+			ObjectMeta: metav1.ObjectMeta{Name: appName},
+			Spec:       ketchv1.AppSpec{Ingress: ketchv1.IngressSpec{Cnames: *cs.cname}},
+		}
+		if err := app.CnameConflict(apps.Items); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
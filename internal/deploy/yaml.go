@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	v1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/yaml"
 
 	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
@@ -27,11 +28,30 @@ type Application struct {
 	BuildPacks     []string  `json:"buildPacks,omitempty"`
 	Processes      []Process `json:"processes,omitempty"`
 	CName          *CName    `json:"cname,omitempty"`
+	// Scheduling contains default scheduling options for all processes.
+	Scheduling *ketchv1.SchedulingSpec `json:"scheduling,omitempty"`
+	// Probes contains readiness, liveness and startup probes, they replace the probes defined in ketch.yaml.
+	Probes *Probes `json:"probes,omitempty"`
+	// IngressProfile is a name of an ingress profile the app is exposed with, the default profile is used if not set.
+	IngressProfile *string `json:"ingressProfile,omitempty"`
 }
 
 type Process struct {
-	Name  string `json:"name"`  // required
-	Units *int   `json:"units"` // default 1
+	Name      string                   `json:"name"`  // required
+	Units     *int                     `json:"units"` // default 1
+	Resources *v1.ResourceRequirements `json:"resources,omitempty"`
+	// Cmd is a command of the process, processes are run with their own commands only when deploying from a sourcePath.
+	Cmd string `json:"cmd,omitempty"`
+	// Scheduling overrides the app-level scheduling options for this process.
+	Scheduling *ketchv1.SchedulingSpec `json:"scheduling,omitempty"`
+	// DisruptionBudget configures a PodDisruptionBudget of this process.
+	DisruptionBudget *ketchv1.DisruptionBudgetSpec `json:"disruptionBudget,omitempty"`
+	// Strategy configures how pods of this process are replaced during a rollout.
+	Strategy *ketchv1.RolloutStrategy `json:"strategy,omitempty"`
+	// InitContainers run to completion before the app container of this process is started.
+	InitContainers []ketchv1.ContainerSpec `json:"initContainers,omitempty"`
+	// Sidecars run alongside the app container of this process.
+	Sidecars []ketchv1.ContainerSpec `json:"sidecars,omitempty"`
 }
 
 type Port struct {
@@ -50,13 +70,17 @@ type Restart struct {
 }
 
 type CName struct {
-	DNSName string `json:"dnsName"`
-	Secure  bool   `json:"secure"`
+	DNSName   string               `json:"dnsName"`
+	Secure    bool                 `json:"secure"`
+	Path      string               `json:"path,omitempty"`
+	StripPath bool                 `json:"stripPath,omitempty"`
+	Routes    []ketchv1.CnameRoute `json:"routes,omitempty"`
+	// Auth requires requests to the cname to be authenticated.
+	Auth *ketchv1.IngressAuth `json:"auth,omitempty"`
 }
 
 const (
-	defaultVersion  = "v1"
-	defaultAppUnit  = 1
+	defaultAppUnit  = ketchv1.DefaultNumberOfUnits
 	typeApplication = "Application"
 )
 
@@ -84,10 +108,20 @@ func (o *Options) GetChangeSetFromYaml(filename string) (*ChangeSet, error) {
 	var processes []ketchv1.ProcessSpec
 	if application.Processes != nil {
 		for _, process := range application.Processes {
+			// units and resources can be changed when deploying an image, commands come from a Procfile of the source.
+			if len(process.Cmd) > 0 && o.AppSourcePath == "" {
+				return nil, errors.New("running defined processes require a sourcePath")
+			}
 			processes = append(processes, ketchv1.ProcessSpec{
-				Name:  process.Name,
-				Units: process.Units,
-				Env:   envs,
+				Name:             process.Name,
+				Units:            process.Units,
+				Env:              envs,
+				Resources:        process.Resources,
+				Scheduling:       process.Scheduling,
+				DisruptionBudget: process.DisruptionBudget,
+				Strategy:         process.Strategy,
+				InitContainers:   process.InitContainers,
+				Sidecars:         process.Sidecars,
 			})
 		}
 
@@ -101,6 +135,9 @@ func (o *Options) GetChangeSetFromYaml(filename string) (*ChangeSet, error) {
 		namespace:            application.Namespace,
 		dockerRegistrySecret: application.RegistrySecret,
 		builder:              application.Builder,
+		scheduling:           application.Scheduling,
+		probes:               application.Probes,
+		ingressProfile:       application.IngressProfile,
 		timeout:              &o.Timeout,
 		wait:                 &o.Wait,
 	}
@@ -108,7 +145,17 @@ func (o *Options) GetChangeSetFromYaml(filename string) (*ChangeSet, error) {
 		c.sourcePath = &o.AppSourcePath
 	}
 	if application.CName != nil {
-		c.cname = &ketchv1.CnameList{{Name: application.CName.DNSName, Secure: application.CName.Secure}}
+		cname := ketchv1.Cname{
+			Name:      application.CName.DNSName,
+			Secure:    application.CName.Secure,
+			Path:      application.CName.Path,
+			StripPath: application.CName.StripPath,
+			Routes:    application.CName.Routes,
+		}
+		if application.CName.Auth != nil {
+			cname.Policies = &ketchv1.IngressPolicies{Auth: application.CName.Auth}
+		}
+		c.cname = &ketchv1.CnameList{cname}
 	}
 	if application.Environment != nil {
 		c.envs = &application.Environment
@@ -124,10 +171,8 @@ func (o *Options) GetChangeSetFromYaml(filename string) (*ChangeSet, error) {
 }
 
 // apply defaults sets default values for a ChangeSet
+// The app version is defaulted by the App mutating webhook.
 func (c *ChangeSet) applyDefaults() {
-	if c.appVersion == nil {
-		c.appVersion = conversions.StrPtr(defaultVersion)
-	}
 	if c.appType == nil {
 		c.appType = conversions.StrPtr(typeApplication)
 	}
@@ -135,6 +180,8 @@ func (c *ChangeSet) applyDefaults() {
 
 	if c.processes != nil {
 		for i := range *c.processes {
+			// a process listed without units is reset to the default rather than keeping units of the previous deployment,
+			// so it isn't left to the webhook which only fills in missing values.
 			if (*c.processes)[i].Units == nil {
 				(*c.processes)[i].Units = conversions.IntPtr(defaultAppUnit)
 			}
@@ -153,8 +200,31 @@ func (c *ChangeSet) validate() error {
 	if c.appName == "" {
 		return errors.New("missing required field name")
 	}
-	if c.sourcePath == nil && c.processes != nil {
-		return errors.New("running defined processes require a sourcePath")
+	if err := c.probes.validate(); err != nil {
+		return fmt.Errorf("probes: %w", err)
+	}
+	if c.cname != nil {
+		for _, cname := range *c.cname {
+			if err := cname.Validate(); err != nil {
+				return err
+			}
+			if err := cname.Policies.Validate("", cname.NormalizedPath()); err != nil {
+				return fmt.Errorf("cname %s: %w", cname.Name, err)
+			}
+		}
+	}
+	if c.processes != nil {
+		for _, process := range *c.processes {
+			if err := validateResources(process.Resources); err != nil {
+				return fmt.Errorf("process %q: %w", process.Name, err)
+			}
+			if err := process.DisruptionBudget.Validate(); err != nil {
+				return fmt.Errorf("process %q: %w", process.Name, err)
+			}
+			if err := process.Strategy.Validate(); err != nil {
+				return fmt.Errorf("process %q: %w", process.Name, err)
+			}
+		}
 	}
 	return nil
 }
@@ -173,21 +243,36 @@ func GetApplicationFromKetchApp(app ketchv1.App) *Application {
 		application.Image = conversions.StrPtr(deployment.Image)
 		for _, process := range deployment.Processes {
 			application.Processes = append(application.Processes, Process{
-				Name:  process.Name,
-				Units: process.Units,
+				Name:             process.Name,
+				Units:            process.Units,
+				Resources:        process.Resources,
+				Scheduling:       process.Scheduling,
+				DisruptionBudget: process.DisruptionBudget,
+				Strategy:         process.Strategy,
+				InitContainers:   process.InitContainers,
+				Sidecars:         process.Sidecars,
 			})
 		}
 	}
 
 	if len(app.Spec.Ingress.Cnames) > 0 {
 		application.CName = &CName{
-			DNSName: app.Spec.Ingress.Cnames[0].Name,
-			Secure:  app.Spec.Ingress.Cnames[0].Secure,
+			DNSName:   app.Spec.Ingress.Cnames[0].Name,
+			Secure:    app.Spec.Ingress.Cnames[0].Secure,
+			Path:      app.Spec.Ingress.Cnames[0].Path,
+			StripPath: app.Spec.Ingress.Cnames[0].StripPath,
+			Routes:    app.Spec.Ingress.Cnames[0].Routes,
+		}
+		if policies := app.Spec.Ingress.Cnames[0].Policies; policies != nil {
+			application.CName.Auth = policies.Auth
 		}
 	}
 	if app.Spec.Description != "" {
 		application.Description = &app.Spec.Description
 	}
+	if app.Spec.Ingress.Controller.Profile != "" {
+		application.IngressProfile = &app.Spec.Ingress.Controller.Profile
+	}
 	if app.Spec.DockerRegistry.SecretName != "" {
 		application.RegistrySecret = &app.Spec.DockerRegistry.SecretName
 	}
@@ -197,6 +282,9 @@ func GetApplicationFromKetchApp(app ketchv1.App) *Application {
 	if len(app.Spec.BuildPacks) > 0 {
 		application.BuildPacks = app.Spec.BuildPacks
 	}
+	if app.Spec.Scheduling != nil {
+		application.Scheduling = app.Spec.Scheduling
+	}
 	var environment []string
 	for _, env := range app.Spec.Env {
 		environment = append(environment, fmt.Sprintf("%s=%s", env.Name, env.Value))
@@ -5,13 +5,17 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
 	"github.com/theketchio/ketch/internal/utils/conversions"
 )
 
 func TestGetChangeSetFromYaml(t *testing.T) {
+	minAvailable := intstr.FromString("50%")
 	tests := []struct {
 		description string
 		yaml        string
@@ -36,6 +40,12 @@ environment:
 processes:
   - name: web
     units: 1
+    resources:
+      requests:
+        cpu: 250m
+        memory: 128Mi
+      limits:
+        memory: 512Mi
   - name: worker
     units: 1
 cname:
@@ -73,6 +83,15 @@ cname:
 								Value: "bar",
 							},
 						},
+						Resources: &corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{
+								corev1.ResourceCPU:    resource.MustParse("250m"),
+								corev1.ResourceMemory: resource.MustParse("128Mi"),
+							},
+							Limits: corev1.ResourceList{
+								corev1.ResourceMemory: resource.MustParse("512Mi"),
+							},
+						},
 					},
 					{
 						Name:  "worker",
@@ -104,7 +123,6 @@ image: gcr.io/kubernetes/sample-app:latest`,
 				yamlStrictDecoding: true,
 				image:              conversions.StrPtr("gcr.io/kubernetes/sample-app:latest"),
 				namespace:          conversions.StrPtr("mynamespace"),
-				appVersion:         conversions.StrPtr("v1"),
 				appType:            conversions.StrPtr("Application"),
 				timeout:            conversions.StrPtr(""),
 				wait:               conversions.BoolPtr(false),
@@ -128,6 +146,36 @@ processes:
 			options: &Options{},
 			errStr:  "running defined processes require a sourcePath",
 		},
+		{
+			description: "success - resources of processes without sourcePath",
+			yaml: `name: test
+namespace: mynamespace
+image: gcr.io/kubernetes/sample-app:latest
+processes:
+  - name: web
+    resources:
+      requests:
+        cpu: 250m`,
+			options: &Options{},
+			changeSet: &ChangeSet{
+				appName:            "test",
+				yamlStrictDecoding: true,
+				image:              conversions.StrPtr("gcr.io/kubernetes/sample-app:latest"),
+				namespace:          conversions.StrPtr("mynamespace"),
+				timeout:            conversions.StrPtr(""),
+				wait:               conversions.BoolPtr(false),
+				processes: &[]ketchv1.ProcessSpec{
+					{
+						Name:  "web",
+						Units: conversions.IntPtr(1),
+						Resources: &corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("250m")},
+						},
+					},
+				},
+				appType: conversions.StrPtr("Application"),
+			},
+		},
 		{
 			description: "success - use appUnits as process.units when units are not specified",
 			yaml: `version: v1
@@ -182,12 +230,298 @@ image: gcr.io/kubernetes/sample-app:latest
 				yamlStrictDecoding: true,
 				image:              conversions.StrPtr("gcr.io/kubernetes/sample-app:latest"),
 				namespace:          conversions.StrPtr("mynamespace"),
-				appVersion:         conversions.StrPtr("v1"),
 				appType:            conversions.StrPtr("Application"),
 				timeout:            conversions.StrPtr(""),
 				wait:               conversions.BoolPtr(false),
 			},
 		},
+		{
+			description: "success - ingress profile",
+			yaml: `name: test
+namespace: mynamespace
+image: gcr.io/kubernetes/sample-app:latest
+ingressProfile: internal
+`,
+			options: &Options{},
+			changeSet: &ChangeSet{
+				appName:            "test",
+				yamlStrictDecoding: true,
+				image:              conversions.StrPtr("gcr.io/kubernetes/sample-app:latest"),
+				namespace:          conversions.StrPtr("mynamespace"),
+				appType:            conversions.StrPtr("Application"),
+				timeout:            conversions.StrPtr(""),
+				wait:               conversions.BoolPtr(false),
+				ingressProfile:     conversions.StrPtr("internal"),
+			},
+		},
+		{
+			description: "success - cname auth",
+			yaml: `name: test
+namespace: mynamespace
+image: gcr.io/kubernetes/sample-app:latest
+cname:
+  dnsName: tools.theketch.io
+  auth:
+    forwardAuth:
+      url: https://oauth2.theketch.io/oauth2/auth
+      responseHeaders:
+        - X-Auth-Request-Email
+`,
+			options: &Options{},
+			changeSet: &ChangeSet{
+				appName:            "test",
+				yamlStrictDecoding: true,
+				image:              conversions.StrPtr("gcr.io/kubernetes/sample-app:latest"),
+				namespace:          conversions.StrPtr("mynamespace"),
+				appType:            conversions.StrPtr("Application"),
+				timeout:            conversions.StrPtr(""),
+				wait:               conversions.BoolPtr(false),
+				cname: &ketchv1.CnameList{{
+					Name: "tools.theketch.io",
+					Policies: &ketchv1.IngressPolicies{Auth: &ketchv1.IngressAuth{ForwardAuth: &ketchv1.ForwardAuth{
+						URL:             "https://oauth2.theketch.io/oauth2/auth",
+						ResponseHeaders: []string{"X-Auth-Request-Email"},
+					}}},
+				}},
+			},
+		},
+		{
+			description: "error - cname with two auth methods",
+			yaml: `name: test
+namespace: mynamespace
+image: gcr.io/kubernetes/sample-app:latest
+cname:
+  dnsName: tools.theketch.io
+  auth:
+    basicAuthSecret: tools-users
+    forwardAuth:
+      url: https://oauth2.theketch.io/oauth2/auth
+`,
+			options: &Options{},
+			errStr:  "cname tools.theketch.io: auth: exactly one of basicAuthSecret, forwardAuth and jwt must be set",
+		},
+		{
+			description: "validation error - resource request greater than limit",
+			yaml: `name: test
+namespace: mynamespace
+image: gcr.io/kubernetes/sample-app:latest
+processes:
+  - name: web
+    resources:
+      requests:
+        memory: 1Gi
+      limits:
+        memory: 512Mi`,
+			options: &Options{
+				AppSourcePath: ".",
+			},
+			errStr: `process "web": "memory" invalid value memory request 1Gi must be less than or equal to memory limit 512Mi`,
+		},
+		{
+			description: "success - scheduling",
+			yaml: `name: test
+namespace: mynamespace
+image: gcr.io/kubernetes/sample-app:latest
+scheduling:
+  priorityClassName: low-priority
+  tolerations:
+    - key: spot
+      operator: Exists
+      effect: NoSchedule
+  topologySpreadConstraints:
+    - maxSkew: 1
+      topologyKey: topology.kubernetes.io/zone
+      whenUnsatisfiable: ScheduleAnyway
+processes:
+  - name: web
+    scheduling:
+      priorityClassName: high-priority`,
+			options: &Options{
+				AppSourcePath: ".",
+			},
+			changeSet: &ChangeSet{
+				appName:            "test",
+				yamlStrictDecoding: true,
+				sourcePath:         conversions.StrPtr("."),
+				image:              conversions.StrPtr("gcr.io/kubernetes/sample-app:latest"),
+				namespace:          conversions.StrPtr("mynamespace"),
+				appType:            conversions.StrPtr("Application"),
+				timeout:            conversions.StrPtr(""),
+				wait:               conversions.BoolPtr(false),
+				scheduling: &ketchv1.SchedulingSpec{
+					PriorityClassName: "low-priority",
+					Tolerations: []corev1.Toleration{
+						{Key: "spot", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+					},
+					TopologySpreadConstraints: []corev1.TopologySpreadConstraint{
+						{MaxSkew: 1, TopologyKey: "topology.kubernetes.io/zone", WhenUnsatisfiable: corev1.ScheduleAnyway},
+					},
+				},
+				processes: &[]ketchv1.ProcessSpec{
+					{
+						Name:       "web",
+						Units:      conversions.IntPtr(1),
+						Scheduling: &ketchv1.SchedulingSpec{PriorityClassName: "high-priority"},
+					},
+				},
+			},
+		},
+		{
+			description: "success - disruption budget",
+			yaml: `name: test
+namespace: mynamespace
+image: gcr.io/kubernetes/sample-app:latest
+processes:
+  - name: web
+    units: 3
+    disruptionBudget:
+      minAvailable: 50%`,
+			options: &Options{
+				AppSourcePath: ".",
+			},
+			changeSet: &ChangeSet{
+				appName:            "test",
+				yamlStrictDecoding: true,
+				sourcePath:         conversions.StrPtr("."),
+				image:              conversions.StrPtr("gcr.io/kubernetes/sample-app:latest"),
+				namespace:          conversions.StrPtr("mynamespace"),
+				appType:            conversions.StrPtr("Application"),
+				timeout:            conversions.StrPtr(""),
+				wait:               conversions.BoolPtr(false),
+				processes: &[]ketchv1.ProcessSpec{
+					{
+						Name:  "web",
+						Units: conversions.IntPtr(3),
+						DisruptionBudget: &ketchv1.DisruptionBudgetSpec{
+							MinAvailable: &minAvailable,
+						},
+					},
+				},
+			},
+		},
+		{
+			description: "error - disruption budget with minAvailable and maxUnavailable",
+			yaml: `name: test
+namespace: mynamespace
+image: gcr.io/kubernetes/sample-app:latest
+processes:
+  - name: web
+    disruptionBudget:
+      minAvailable: 1
+      maxUnavailable: 1`,
+			options: &Options{
+				AppSourcePath: ".",
+			},
+			errStr: `process "web": only one of minAvailable and maxUnavailable can be set`,
+		},
+		{
+			description: "success - init containers and sidecars",
+			yaml: `name: test
+namespace: mynamespace
+image: gcr.io/kubernetes/sample-app:latest
+processes:
+  - name: web
+    initContainers:
+      - name: migrate
+        image: gcr.io/kubernetes/sample-app:latest
+        command: ["./migrate"]
+    sidecars:
+      - name: proxy
+        image: gcr.io/cloudsql-docker/gce-proxy:1.33
+        env:
+          - name: INSTANCE
+            value: project:region:db`,
+			options: &Options{
+				AppSourcePath: ".",
+			},
+			changeSet: &ChangeSet{
+				appName:            "test",
+				yamlStrictDecoding: true,
+				sourcePath:         conversions.StrPtr("."),
+				image:              conversions.StrPtr("gcr.io/kubernetes/sample-app:latest"),
+				namespace:          conversions.StrPtr("mynamespace"),
+				appType:            conversions.StrPtr("Application"),
+				timeout:            conversions.StrPtr(""),
+				wait:               conversions.BoolPtr(false),
+				processes: &[]ketchv1.ProcessSpec{
+					{
+						Name:  "web",
+						Units: conversions.IntPtr(1),
+						InitContainers: []ketchv1.ContainerSpec{
+							{Name: "migrate", Image: "gcr.io/kubernetes/sample-app:latest", Command: []string{"./migrate"}},
+						},
+						Sidecars: []ketchv1.ContainerSpec{
+							{Name: "proxy", Image: "gcr.io/cloudsql-docker/gce-proxy:1.33", Env: []ketchv1.Env{{Name: "INSTANCE", Value: "project:region:db"}}},
+						},
+					},
+				},
+			},
+		},
+		{
+			description: "success - probes",
+			yaml: `name: test
+namespace: mynamespace
+image: gcr.io/kubernetes/sample-app:latest
+probes:
+  readiness:
+    http: /healthz:8080
+    periodSeconds: 10
+  liveness:
+    tcp: "8080"`,
+			options: &Options{},
+			changeSet: &ChangeSet{
+				appName:            "test",
+				yamlStrictDecoding: true,
+				image:              conversions.StrPtr("gcr.io/kubernetes/sample-app:latest"),
+				namespace:          conversions.StrPtr("mynamespace"),
+				appType:            conversions.StrPtr("Application"),
+				timeout:            conversions.StrPtr(""),
+				wait:               conversions.BoolPtr(false),
+				probes: &Probes{
+					Readiness: &ProbeShorthand{HTTP: "/healthz:8080", PeriodSeconds: 10},
+					Liveness:  &ProbeShorthand{TCP: "8080"},
+				},
+			},
+		},
+		{
+			description: "error - invalid probe",
+			yaml: `name: test
+namespace: mynamespace
+image: gcr.io/kubernetes/sample-app:latest
+probes:
+  readiness:
+    http: /healthz`,
+			options: &Options{},
+			errStr:  `probes: readiness: http probe "/healthz" should have PATH:PORT format`,
+		},
+		{
+			description: "error - recreate strategy with maxSurge",
+			yaml: `name: test
+namespace: mynamespace
+image: gcr.io/kubernetes/sample-app:latest
+processes:
+  - name: web
+    strategy:
+      type: Recreate
+      maxSurge: 1`,
+			options: &Options{
+				AppSourcePath: ".",
+			},
+			errStr: `process "web": maxSurge and maxUnavailable can't be used with the Recreate strategy`,
+		},
+		{
+			description: "error - invalid cname route",
+			yaml: `name: test
+namespace: mynamespace
+image: gcr.io/kubernetes/sample-app:latest
+cname:
+  dnsName: test.theketch.io
+  routes:
+    - pathPrefix: admin
+      process: admin`,
+			options: &Options{},
+			errStr:  `cname test.theketch.io: invalid path prefix "admin", it should start with / and contain only letters, digits and -._~%/ characters`,
+		},
 		{
 			description: "error - malformed envvar",
 			yaml: `name: test
@@ -265,7 +599,9 @@ func TestGetApplicationFromKetchApp(t *testing.T) {
 							Version: ketchv1.DeploymentVersion(3),
 							Image:   "gcr.io/shipa-ci/sample-go-app:latest",
 							Processes: []ketchv1.ProcessSpec{
-								{Name: "process-1", Units: conversions.IntPtr(1)},
+								{Name: "process-1", Units: conversions.IntPtr(1), Resources: &corev1.ResourceRequirements{
+									Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+								}},
 								{Name: "process-2", Units: conversions.IntPtr(2)},
 								{Name: "process-3", Units: conversions.IntPtr(1)},
 							},
@@ -296,6 +632,9 @@ func TestGetApplicationFromKetchApp(t *testing.T) {
 					{
 						Name:  "process-1",
 						Units: conversions.IntPtr(1),
+						Resources: &corev1.ResourceRequirements{
+							Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")},
+						},
 					},
 					{
 						Name:  "process-2",
@@ -2,6 +2,7 @@ package mocks
 
 import (
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	dynamicFake "k8s.io/client-go/dynamic/fake"
 	"k8s.io/client-go/kubernetes"
@@ -29,7 +30,8 @@ type Configuration struct {
 	DynamicClientObjects []runtime.Object
 	StorageInstance      templates.Client
 
-	ctrlClient client.Client
+	ctrlClient    client.Client
+	dynamicClient dynamic.Interface
 }
 
 func (cfg *Configuration) Client() client.Client {
@@ -53,5 +55,11 @@ func (cfg *Configuration) KubernetesClient() kubernetes.Interface {
 
 // DynamicClient returns kubernetes dynamic client. It's used to work with CRDs for which we don't have go types like ClusterIssuer.
 func (cfg *Configuration) DynamicClient() dynamic.Interface {
-	return dynamicFake.NewSimpleDynamicClient(runtime.NewScheme(), cfg.DynamicClientObjects...)
+	if cfg.dynamicClient == nil {
+		listKinds := map[schema.GroupVersionResource]string{
+			{Group: "cert-manager.io", Version: "v1", Resource: "clusterissuers"}: "ClusterIssuerList",
+		}
+		cfg.dynamicClient = dynamicFake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, cfg.DynamicClientObjects...)
+	}
+	return cfg.dynamicClient
 }
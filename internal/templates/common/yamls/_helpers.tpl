@@ -6,6 +6,7 @@ the dict must have the following entries:
     "metadataItems": []MetadataItem{},    // a list of requests to add metadata
     "kind": "<kind>",                   // all metadataItems with target.kind equals <kind> will be added
     "apiVersion": "<apiVersion>",       // all metadataItems with target.apiVersion equals <kind> will be added
+    "apiVersionAliases": []string{},    // optional, all metadataItems with target.apiVersion in the list will be added too
 }
 
 This is an example of usage:
@@ -23,7 +24,7 @@ theketch.io/metadata-item-kind: {{ $.kind }}
 theketch.io/metadata-item-apiVersion: {{ $.apiVersion }}
 {{- range $_, $item := $.metadataItems }}
   {{- if eq $item.target.kind $.kind }}
-    {{- if eq $item.target.apiVersion $.apiVersion }}
+    {{- if or (eq $item.target.apiVersion $.apiVersion) (has $item.target.apiVersion ($.apiVersionAliases | default list)) }}
         {{- range $key, $value := $item.apply }}
 {{ $key }}: {{ $value | quote }}
         {{- end }}
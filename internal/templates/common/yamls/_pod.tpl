@@ -7,6 +7,10 @@
       {{- if .root.app.securityContext }}
       securityContext:
 {{ .root.app.securityContext | toYaml | indent 8 }}
+      {{- end }}
+      {{- if .process.initContainers }}
+      initContainers:
+{{ .process.initContainers | toYaml | indent 8 }}
       {{- end }}
       containers:
         - name: {{ .root.app.name }}-{{ .process.name }}-{{ .deployment.version }}
@@ -53,6 +57,9 @@
           startupProbe:
 {{ .process.startupProbe | toYaml | indent 12 }}
           {{- end }}
+        {{- if .process.sidecars }}
+{{ .process.sidecars | toYaml | indent 8 }}
+        {{- end }}
       {{- if .deployment.imagePullSecrets }}
       imagePullSecrets:
 {{ .deployment.imagePullSecrets | toYaml | indent 12}}
@@ -61,11 +68,28 @@
       volumes:
 {{ .process.volumes | toYaml | indent 12 }}
       {{- end }}
-      {{- if .process.nodeSelectorTerms }}
+      {{- if or .process.nodeSelectorTerms .process.podAntiAffinity }}
       affinity:
+        {{- if .process.nodeSelectorTerms }}
         nodeAffinity:
           requiredDuringSchedulingIgnoredDuringExecution:
             nodeSelectorTerms:
 {{ .process.nodeSelectorTerms | toYaml | indent 14 }}
+        {{- end }}
+        {{- if .process.podAntiAffinity }}
+        podAntiAffinity:
+{{ .process.podAntiAffinity | toYaml | indent 10 }}
+        {{- end }}
+      {{- end }}
+      {{- if .process.tolerations }}
+      tolerations:
+{{ .process.tolerations | toYaml | indent 8 }}
+      {{- end }}
+      {{- if .process.topologySpreadConstraints }}
+      topologySpreadConstraints:
+{{ .process.topologySpreadConstraints | toYaml | indent 8 }}
+      {{- end }}
+      {{- if .process.priorityClassName }}
+      priorityClassName: {{ .process.priorityClassName }}
       {{- end }}
 {{- end }}
\ No newline at end of file
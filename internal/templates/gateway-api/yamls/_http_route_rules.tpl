@@ -0,0 +1,40 @@
+{{- /* renders a parentRef of a listener of the shared Gateway, expects a dict with "sectionName" and "Values" keys */ -}}
+{{- define "ketch.gatewayapi.parentRef" }}
+- name: {{ .Values.ingressController.gatewayName | quote }}
+  {{- with .Values.ingressController.gatewayNamespace }}
+  namespace: {{ . | quote }}
+  {{- end }}
+  sectionName: {{ .sectionName }}
+{{- end }}
+
+{{- define "ketch.gatewayapi.rules" }}
+{{- $values := .Values }}
+{{- $routes := list (dict "pathPrefix" "/" "process" "") }}
+{{- with index $values.app.ingress.routes .cname }}{{ $routes = .routes }}{{ end }}
+{{- range $_, $route := $routes }}
+- matches:
+  - path:
+      type: PathPrefix
+      value: {{ $route.pathPrefix | quote }}
+  {{- if $route.rewrite }}
+  filters:
+  - type: URLRewrite
+    urlRewrite:
+      path:
+        type: ReplacePrefixMatch
+        replacePrefixMatch: {{ $route.rewrite | quote }}
+  {{- end }}
+  backendRefs:
+  {{- range $_, $deployment := $values.app.deployments }}
+  {{- if gt $deployment.routingSettings.weight 0.0 }}
+  {{- range $_, $process := $deployment.processes }}
+  {{- if or (eq $process.name $route.process) (and (not $route.process) $process.routable) }}
+  - name: {{ printf "%s-%s-%v" $values.app.name $process.name $deployment.version }}
+    port: {{ $route.port | default $process.publicServicePort }}
+    weight: {{ $deployment.routingSettings.weight }}
+  {{- end }}
+  {{- end }}
+  {{- end }}
+  {{- end }}
+{{- end }}
+{{- end }}
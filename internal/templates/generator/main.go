@@ -12,14 +12,15 @@ import (
 )
 
 type YamlFile struct {
-	Name    string
-	Traefik bool
-	Istio   bool
-	Nginx   bool
-	Common  bool
-	Job     bool
-	CronJob bool
-	Content string
+	Name       string
+	Traefik    bool
+	Istio      bool
+	Nginx      bool
+	GatewayAPI bool
+	Common     bool
+	Job        bool
+	CronJob    bool
+	Content    string
 }
 
 type context struct {
@@ -36,6 +37,7 @@ type Yamls struct {
 	TraefikYamls map[string]string
 	IstioYamls map[string]string
 	NginxYamls map[string]string
+	GatewayAPIYamls map[string]string
 	JobYamls map[string]string
 	CronJobYamls map[string]string
 }
@@ -64,6 +66,14 @@ var GeneratedYamls = Yamls{
 {{ $yaml.Content }},
 {{- end }}
 {{- end }}
+},
+  GatewayAPIYamls: map[string]string {
+{{- range $_, $yaml := .Yamls }}
+{{- if or $yaml.GatewayAPI $yaml.Common }} 
+    "{{ $yaml.Name }}": 
+{{ $yaml.Content }},
+{{- end }}
+{{- end }}
 },
   JobYamls: map[string]string {
 {{- range $_, $yaml := .Yamls }}
@@ -91,6 +101,7 @@ func main() {
 	yamls = append(yamls, readDir("traefik")...)
 	yamls = append(yamls, readDir("istio")...)
 	yamls = append(yamls, readDir("nginx")...)
+	yamls = append(yamls, readDir("gateway-api")...)
 	yamls = append(yamls, readDir("job")...)
 	yamls = append(yamls, readDir("cronjob")...)
 
@@ -134,14 +145,15 @@ func readDir(dir string) []YamlFile {
 			panic(err)
 		}
 		yamls = append(yamls, YamlFile{
-			Name:    info.Name(),
-			Traefik: dir == "traefik",
-			Istio:   dir == "istio",
-			Nginx:   dir == "nginx",
-			Common:  dir == "common",
-			Job:     dir == "job",
-			CronJob: dir == "cronjob",
-			Content: fmt.Sprintf("`%s`", string(content)),
+			Name:       info.Name(),
+			Traefik:    dir == "traefik",
+			Istio:      dir == "istio",
+			Nginx:      dir == "nginx",
+			GatewayAPI: dir == "gateway-api",
+			Common:     dir == "common",
+			Job:        dir == "job",
+			CronJob:    dir == "cronjob",
+			Content:    fmt.Sprintf("`%s`", string(content)),
 		})
 	}
 	return yamls
@@ -0,0 +1,17 @@
+{{- /* renders weighted destinations of a cname route, expects a dict with "route" and "Values" keys */ -}}
+{{- define "ketch.istio.routeDestinations" }}
+{{- $route := .route }}
+{{- range $_, $deployment := .Values.app.deployments }}
+{{- range $_, $process := $deployment.processes }}
+{{- if or (eq $process.name $route.process) (and (not $route.process) $process.routable) }}{{- if gt $deployment.routingSettings.weight 0.0}}
+        - destination:
+            host: {{ printf "%s-%s-%v" $.Values.app.name $process.name $deployment.version }}
+            port:
+              number: {{ $route.port | default $process.publicServicePort }}
+            subset: "v{{ $deployment.version }}"
+          weight: {{$deployment.routingSettings.weight}}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- end }}
@@ -0,0 +1,95 @@
+{{- /* renders CORS and header policies of a cname route, expects policies of the cname */ -}}
+{{- define "ketch.istio.routePolicies" }}
+{{- with .cors }}
+      corsPolicy:
+        allowOrigins:
+        {{- range $_, $origin := .allowOrigins }}
+        {{- if eq $origin "*" }}
+        - regex: ".*"
+        {{- else }}
+        - exact: {{ $origin | quote }}
+        {{- end }}
+        {{- end }}
+        {{- with .allowMethods }}
+        allowMethods:
+        {{- range $_, $method := . }}
+        - {{ $method | quote }}
+        {{- end }}
+        {{- end }}
+        {{- with .allowHeaders }}
+        allowHeaders:
+        {{- range $_, $header := . }}
+        - {{ $header | quote }}
+        {{- end }}
+        {{- end }}
+        {{- if .allowCredentials }}
+        allowCredentials: true
+        {{- end }}
+        {{- with .maxAge }}
+        maxAge: "{{ . }}s"
+        {{- end }}
+{{- end }}
+{{- if or .requestHeaders .responseHeaders }}
+      headers:
+        {{- with .requestHeaders }}
+        request:
+          set:
+            {{- range $name, $value := . }}
+            {{ $name | quote }}: {{ $value | quote }}
+            {{- end }}
+        {{- end }}
+        {{- with .responseHeaders }}
+        response:
+          set:
+            {{- range $name, $value := . }}
+            {{ $name | quote }}: {{ $value | quote }}
+            {{- end }}
+        {{- end }}
+{{- end }}
+{{- end }}
+
+{{- /* renders timeout, retries and fault injection of a VirtualService http route, expects the mesh policy of the app */ -}}
+{{- define "ketch.istio.meshRoutePolicy" }}
+{{- with .requestTimeoutSeconds }}
+      timeout: {{ . }}s
+{{- end }}
+{{- with .retries }}
+      retries:
+        attempts: {{ .attempts }}
+        {{- with .perTryTimeoutSeconds }}
+        perTryTimeout: {{ . }}s
+        {{- end }}
+        {{- with .retryOn }}
+        retryOn: {{ join "," . | quote }}
+        {{- end }}
+{{- end }}
+{{- with .faultInjection }}
+      fault:
+        {{- with .delay }}
+        delay:
+          percentage:
+            value: {{ .percentage }}
+          fixedDelay: {{ .fixedDelaySeconds }}s
+        {{- end }}
+        {{- with .abort }}
+        abort:
+          percentage:
+            value: {{ .percentage }}
+          httpStatus: {{ .httpStatus }}
+        {{- end }}
+{{- end }}
+{{- end }}
+
+{{- /* renders the operation of an AuthorizationPolicy rule matching requests to a cname, expects a dict with "cname" and "pathPrefix" keys */ -}}
+{{- define "ketch.istio.policyOperation" }}
+    to:
+    - operation:
+        hosts:
+        - {{ .cname | quote }}
+        - {{ printf "%s:*" .cname | quote }}
+        {{- if ne .pathPrefix "/" }}
+        paths:
+        - {{ .pathPrefix | quote }}
+        - {{ printf "%s/*" .pathPrefix | quote }}
+        {{- end }}
+{{- end }}
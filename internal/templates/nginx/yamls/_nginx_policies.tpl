@@ -0,0 +1,62 @@
+{{/*
+ketch.nginx.policyAnnotations renders annotations of an Ingress implementing ingress policies of a cname.
+Request and response headers are set by a configuration snippet, it requires "allow-snippet-annotations" to be enabled.
+*/}}
+{{- define "ketch.nginx.policyAnnotations" -}}
+{{- with .rateLimit }}
+nginx.ingress.kubernetes.io/limit-rps: {{ .requestsPerSecond | quote }}
+nginx.ingress.kubernetes.io/limit-burst-multiplier: {{ .burstMultiplier | quote }}
+{{- end }}
+{{- with .cors }}
+nginx.ingress.kubernetes.io/enable-cors: "true"
+nginx.ingress.kubernetes.io/cors-allow-origin: {{ join ", " .allowOrigins | quote }}
+{{- with .allowMethods }}
+nginx.ingress.kubernetes.io/cors-allow-methods: {{ join ", " . | quote }}
+{{- end }}
+{{- with .allowHeaders }}
+nginx.ingress.kubernetes.io/cors-allow-headers: {{ join ", " . | quote }}
+{{- end }}
+nginx.ingress.kubernetes.io/cors-allow-credentials: {{ .allowCredentials | default false | quote }}
+{{- with .maxAge }}
+nginx.ingress.kubernetes.io/cors-max-age: {{ . | quote }}
+{{- end }}
+{{- end }}
+{{- with .ipAllowlist }}
+nginx.ingress.kubernetes.io/whitelist-source-range: {{ join "," . | quote }}
+{{- end }}
+{{- with .auth }}
+{{- if .basicAuthSecret }}
+nginx.ingress.kubernetes.io/auth-type: basic
+nginx.ingress.kubernetes.io/auth-secret: {{ .basicAuthSecret | quote }}
+nginx.ingress.kubernetes.io/auth-realm: "Authentication Required"
+{{- end }}
+{{- with .forwardAuth }}
+nginx.ingress.kubernetes.io/auth-url: {{ .url | quote }}
+{{- with .signInURL }}
+nginx.ingress.kubernetes.io/auth-signin: {{ . | quote }}
+{{- end }}
+{{- with .responseHeaders }}
+nginx.ingress.kubernetes.io/auth-response-headers: {{ join "," . | quote }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- if or .requestHeaders .responseHeaders }}
+nginx.ingress.kubernetes.io/configuration-snippet: |
+  {{- range $name, $value := .requestHeaders }}
+  more_set_input_headers "{{ $name }}: {{ $value }}";
+  {{- end }}
+  {{- range $name, $value := .responseHeaders }}
+  more_set_headers "{{ $name }}: {{ $value }}";
+  {{- end }}
+{{- end }}
+{{- end -}}
+
+{{/*
+ketch.nginx.upstreamAnnotations renders annotations of an Ingress configuring connections to the application, expects the ingress values.
+*/}}
+{{- define "ketch.nginx.upstreamAnnotations" -}}
+{{- with .upstreamReadTimeoutSeconds }}
+nginx.ingress.kubernetes.io/proxy-read-timeout: {{ . | quote }}
+nginx.ingress.kubernetes.io/proxy-send-timeout: {{ . | quote }}
+{{- end }}
+{{- end -}}
@@ -75,6 +75,9 @@ var (
 	NginxDefaultTemplates = Templates{
 		Yamls: GeneratedYamls.NginxYamls,
 	}
+	GatewayAPIDefaultTemplates = Templates{
+		Yamls: GeneratedYamls.GatewayAPIYamls,
+	}
 	JobTemplates = Templates{
 		Yamls: GeneratedYamls.JobYamls,
 	}
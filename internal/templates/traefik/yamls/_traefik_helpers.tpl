@@ -0,0 +1,98 @@
+{{/*
+ketch.traefik.apiVersion renders an apiVersion of traefik resources.
+traefik.io is used unless the ingress controller is configured to use the legacy traefik.containo.us group.
+*/}}
+{{- define "ketch.traefik.apiVersion" -}}
+{{ $.Values.ingressController.traefikAPIGroup | default "traefik.io" }}/v1alpha1
+{{- end -}}
+
+{{/*
+ketch.traefik.metadataAnnotations renders annotations of an IngressRoute.
+Annotations targeting IngressRoutes of both traefik API groups are applied,
+so they survive migration from traefik.containo.us to traefik.io.
+*/}}
+{{- define "ketch.traefik.metadataAnnotations" -}}
+{{- $apiVersion := include "ketch.traefik.apiVersion" $ }}
+{{- $data := dict "kind" "IngressRoute" "apiVersion" $apiVersion "apiVersionAliases" (list "traefik.io/v1alpha1" "traefik.containo.us/v1alpha1") "metadataItems" $.Values.app.metadataAnnotations }}
+{{- include "ketch.renderMetadata" $data }}
+{{- end -}}
+
+{{/*
+ketch.traefik.routeMiddlewares renders middlewares of a route to a cname, expects routes of the cname.
+Requests are filtered by the IP allowlist, the rate limit and authentication before headers are set and the path prefix is stripped.
+*/}}
+{{- define "ketch.traefik.routeMiddlewares" -}}
+{{- if or .stripPrefix .policies }}
+    middlewares:
+    {{- with .policies }}
+    {{- if .ipAllowlist }}
+    - name: {{ $.uniqueName }}-ip-allowlist
+    {{- end }}
+    {{- if .rateLimit }}
+    - name: {{ $.uniqueName }}-rate-limit
+    {{- end }}
+    {{- if .auth }}
+    - name: {{ $.uniqueName }}-auth
+    {{- end }}
+    {{- if or .cors .requestHeaders .responseHeaders }}
+    - name: {{ $.uniqueName }}-headers
+    {{- end }}
+    {{- end }}
+    {{- if .stripPrefix }}
+    - name: {{ .uniqueName }}-strip-prefix
+    {{- end }}
+{{- end }}
+{{- end -}}
+
+{{/*
+ketch.traefik.middlewareLabels renders labels of a Middleware.
+*/}}
+{{- define "ketch.traefik.middlewareLabels" -}}
+{{ $.Values.app.group }}/app-name: {{ $.Values.app.name | quote }}
+{{- with (last $.Values.app.deployments) }}
+{{ $.Values.app.group }}/app-deployment-version: {{ .version | quote }}
+app.kubernetes.io/version: {{ .version | quote }}
+{{- end }}
+app.kubernetes.io/name: {{ $.Values.app.name | quote }}
+app.kubernetes.io/instance: {{ $.Values.app.name | quote }}
+{{- end -}}
+
+{{/*
+ketch.traefik.serversTransportSpec renders a spec of a ServersTransport used to connect to the application,
+it is empty if the default transport of traefik can be used.
+Certificates of gRPC over TLS backends aren't verified if any of their ports sets insecureSkipVerify,
+a ServersTransport is shared by all services of the app.
+traefik has no read timeout, so the upstream read timeout only limits the time to response headers.
+*/}}
+{{- define "ketch.traefik.serversTransportSpec" -}}
+{{- $insecure := false }}
+{{- range $_, $deployment := $.Values.app.deployments }}
+{{- range $_, $process := $deployment.processes }}
+{{- if $process.insecureSkipVerifyPorts }}{{ $insecure = true }}{{ end }}
+{{- end }}
+{{- end }}
+{{- if $insecure }}
+insecureSkipVerify: true
+{{- end }}
+{{- with $.Values.app.ingress.upstreamReadTimeoutSeconds }}
+forwardingTimeouts:
+  responseHeaderTimeout: {{ . }}s
+  idleConnTimeout: {{ . }}s
+{{- end }}
+{{- end -}}
+
+{{/*
+ketch.traefik.serviceOptions renders options of a service of an IngressRoute, expects a dict with a route, a process and the root context.
+HTTP/2 and gRPC requests are sent to the application with h2c.
+*/}}
+{{- define "ketch.traefik.serviceOptions" -}}
+{{- $protocol := .route.appProtocol | default .process.appProtocol }}
+{{- if or (eq $protocol "http2") (eq $protocol "grpc") }}
+scheme: h2c
+{{- else if eq $protocol "grpcs" }}
+scheme: https
+{{- end }}
+{{- if include "ketch.traefik.serversTransportSpec" .root }}
+serversTransport: {{ .root.Values.app.name }}-servers-transport
+{{- end }}
+{{- end -}}
@@ -4,6 +4,10 @@ func IntPtr(i int) *int {
 	return &i
 }
 
+func Int32Ptr(i int32) *int32 {
+	return &i
+}
+
 func StrPtr(s string) *string {
 	return &s
 }
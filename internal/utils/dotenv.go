@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
+)
+
+var (
+	dotEnvNameRegex       = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+	dotEnvPlainValueRegex = regexp.MustCompile(`^[A-Za-z0-9_./:@%+,=?&-]*$`)
+)
+
+// ParseDotEnv reads environment variables in the .env format.
+// Blank lines and lines starting with '#' are ignored, an optional "export " prefix is allowed.
+// Values can be unquoted, single-quoted (taken literally) or double-quoted (supporting \n, \r, \t, \" and \\ escapes).
+// Quoted values can span multiple lines.
+func ParseDotEnv(r io.Reader) ([]ketchv1.Env, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	content := strings.ReplaceAll(string(data), "\r\n", "\n")
+	var envs []ketchv1.Env
+	line := 1
+	for len(content) > 0 {
+		var raw string
+		raw, content = cutLine(content)
+		startLine := line
+		line++
+
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		trimmed = strings.TrimPrefix(trimmed, "export ")
+		name, value, found := strings.Cut(trimmed, "=")
+		if !found {
+			return nil, fmt.Errorf("line %d: env variables should have NAME=VALUE format", startLine)
+		}
+		name = strings.TrimSpace(name)
+		if !dotEnvNameRegex.MatchString(name) {
+			return nil, fmt.Errorf("line %d: invalid env variable name %q", startLine, name)
+		}
+		value = strings.TrimLeft(value, " \t")
+
+		if len(value) > 0 && (value[0] == '"' || value[0] == '\'') {
+			quote := value[0]
+			// a quoted value may continue on the following lines until the closing quote.
+			value = value[1:]
+			for {
+				if end := closingQuote(value, quote); end >= 0 {
+					rest := strings.TrimSpace(value[end+1:])
+					if rest != "" && !strings.HasPrefix(rest, "#") {
+						return nil, fmt.Errorf("line %d: unexpected characters after the closing quote", startLine)
+					}
+					value = value[:end]
+					break
+				}
+				if len(content) == 0 {
+					return nil, fmt.Errorf("line %d: unterminated quoted value", startLine)
+				}
+				var next string
+				next, content = cutLine(content)
+				line++
+				value += "\n" + next
+			}
+			if quote == '"' {
+				value = unescapeDotEnvValue(value)
+			}
+		} else {
+			if idx := strings.Index(value, " #"); idx >= 0 {
+				value = value[:idx]
+			}
+			value = strings.TrimSpace(value)
+		}
+		envs = append(envs, ketchv1.Env{Name: name, Value: value})
+	}
+	return envs, nil
+}
+
+// WriteDotEnv writes environment variables in the .env format, one variable per line.
+// Values are quoted when required so that ParseDotEnv returns them unchanged.
+func WriteDotEnv(w io.Writer, envs []ketchv1.Env) error {
+	bw := bufio.NewWriter(w)
+	for _, env := range envs {
+		if _, err := fmt.Fprintf(bw, "%s=%s\n", env.Name, quoteDotEnvValue(env.Value)); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func cutLine(content string) (string, string) {
+	line, rest, _ := strings.Cut(content, "\n")
+	return line, rest
+}
+
+// closingQuote returns the index of the closing quote or -1 if the value doesn't contain it.
+func closingQuote(value string, quote byte) int {
+	for i := 0; i < len(value); i++ {
+		switch {
+		case quote == '"' && value[i] == '\\':
+			i++
+		case value[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+func unescapeDotEnvValue(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 't':
+			b.WriteByte('\t')
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
+
+func quoteDotEnvValue(value string) string {
+	if dotEnvPlainValueRegex.MatchString(value) {
+		return value
+	}
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		`$`, `\$`,
+		"\n", `\n`,
+		"\r", `\r`,
+		"\t", `\t`,
+	)
+	return `"` + replacer.Replace(value) + `"`
+}
@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
+)
+
+func TestParseDotEnv(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []ketchv1.Env
+		wantErr string
+	}{
+		{
+			name: "comments, blank lines and export prefix",
+			content: `# database settings
+DB_HOST=localhost
+
+export DB_PORT=5432
+DB_URL=postgres://localhost?sslmode=disable # trailing comment
+`,
+			want: []ketchv1.Env{
+				{Name: "DB_HOST", Value: "localhost"},
+				{Name: "DB_PORT", Value: "5432"},
+				{Name: "DB_URL", Value: "postgres://localhost?sslmode=disable"},
+			},
+		},
+		{
+			name: "quoted values",
+			content: `GREETING="hello \"world\"\n"
+LITERAL='no \n escapes # here'
+EMPTY=
+SPACED = "  padded  "
+`,
+			want: []ketchv1.Env{
+				{Name: "GREETING", Value: "hello \"world\"\n"},
+				{Name: "LITERAL", Value: `no \n escapes # here`},
+				{Name: "EMPTY", Value: ""},
+				{Name: "SPACED", Value: "  padded  "},
+			},
+		},
+		{
+			name:    "multiline values",
+			content: "KEY=\"-----BEGIN KEY-----\r\nabc\r\n-----END KEY-----\"\r\nSINGLE='a\nb'\nAFTER=1",
+			want: []ketchv1.Env{
+				{Name: "KEY", Value: "-----BEGIN KEY-----\nabc\n-----END KEY-----"},
+				{Name: "SINGLE", Value: "a\nb"},
+				{Name: "AFTER", Value: "1"},
+			},
+		},
+		{
+			name:    "missing equal sign",
+			content: "FOO=bar\nBAZ\n",
+			wantErr: "line 2: env variables should have NAME=VALUE format",
+		},
+		{
+			name:    "invalid name",
+			content: "1FOO=bar\n",
+			wantErr: `line 1: invalid env variable name "1FOO"`,
+		},
+		{
+			name:    "unterminated quote",
+			content: "FOO=\"bar\nBAZ=qux\n",
+			wantErr: "line 1: unterminated quoted value",
+		},
+		{
+			name:    "garbage after quote",
+			content: "FOO=\"bar\" baz\n",
+			wantErr: "line 1: unexpected characters after the closing quote",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDotEnv(strings.NewReader(tt.content))
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestWriteDotEnv(t *testing.T) {
+	envs := []ketchv1.Env{
+		{Name: "PLAIN", Value: "postgres://db:5432/app?sslmode=disable"},
+		{Name: "EMPTY", Value: ""},
+		{Name: "SPACES", Value: "hello world"},
+		{Name: "QUOTES", Value: `say "hi" $HOME \o/`},
+		{Name: "MULTILINE", Value: "line1\nline2\ttab"},
+		{Name: "HASH", Value: "#not-a-comment"},
+	}
+	buf := &bytes.Buffer{}
+	require.Nil(t, WriteDotEnv(buf, envs))
+	require.Equal(t, `PLAIN=postgres://db:5432/app?sslmode=disable
+EMPTY=
+SPACES="hello world"
+QUOTES="say \"hi\" \$HOME \\o/"
+MULTILINE="line1\nline2\ttab"
+HASH="#not-a-comment"
+`, buf.String())
+
+	got, err := ParseDotEnv(buf)
+	require.Nil(t, err)
+	require.Equal(t, envs, got)
+}
@@ -8,12 +8,13 @@ import (
 )
 
 // MakeEnvironments takes an array of name value pairs delimited by '=' and convert
-// them to an array of Env structures.
+// them to an array of Env structures. Only the first '=' separates the name from the value,
+// so values may contain '=' themselves.
 func MakeEnvironments(envs []string) ([]ketchv1.Env, error) {
 	splittedEnvs := make([]ketchv1.Env, 0, len(envs))
 	for _, env := range envs {
-		parts := strings.Split(env, "=")
-		if len(parts) != 2 {
+		parts := strings.SplitN(env, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
 			return nil, errors.New("env variables should have NAME=VALUE format")
 		}
 		splittedEnvs = append(splittedEnvs,
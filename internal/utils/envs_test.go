@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
+)
+
+func TestMakeEnvironments(t *testing.T) {
+	tests := []struct {
+		name    string
+		envs    []string
+		want    []ketchv1.Env
+		wantErr string
+	}{
+		{
+			name: "simple values",
+			envs: []string{"FOO=bar", " BAZ = qux "},
+			want: []ketchv1.Env{{Name: "FOO", Value: "bar"}, {Name: "BAZ", Value: "qux"}},
+		},
+		{
+			name: "value with equal signs",
+			envs: []string{"DATABASE_URL=postgres://db?sslmode=disable", "TOKEN=abc=="},
+			want: []ketchv1.Env{{Name: "DATABASE_URL", Value: "postgres://db?sslmode=disable"}, {Name: "TOKEN", Value: "abc=="}},
+		},
+		{
+			name: "empty value",
+			envs: []string{"FOO="},
+			want: []ketchv1.Env{{Name: "FOO", Value: ""}},
+		},
+		{
+			name:    "no equal sign",
+			envs:    []string{"FOO"},
+			wantErr: "env variables should have NAME=VALUE format",
+		},
+		{
+			name:    "no name",
+			envs:    []string{"=bar"},
+			wantErr: "env variables should have NAME=VALUE format",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MakeEnvironments(tt.envs)
+			if len(tt.wantErr) > 0 {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
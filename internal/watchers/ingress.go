@@ -19,6 +19,7 @@ package watchers
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	ketchv1 "github.com/theketchio/ketch/internal/api/v1beta1"
@@ -27,7 +28,6 @@ import (
 	"github.com/go-logr/logr"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
@@ -50,9 +50,9 @@ var (
 	informerResyncPeriod = time.Minute * 5
 )
 
-// NewIngressWatcher creates a IngressWatcher instance
+// NewIngressWatcher creates a IngressWatcher instance.
+// It watches configmaps of all ingress profiles, the default ketch-ingress configmap and ketch-ingress-<profile> ones.
 func NewIngressWatcher(clientSet kubernetes.Interface, client client.Client, logger logr.Logger) *IngressWatcher {
-	fieldSelector := labels.Set(map[string]string{"metadata.name": ketchv1.IngressConfigmapName}).AsSelector()
 	return &IngressWatcher{
 		clientSet:  clientSet,
 		client:     client,
@@ -61,11 +61,9 @@ func NewIngressWatcher(clientSet kubernetes.Interface, client client.Client, log
 		retryDelay: time.Second,
 		sharedIndexInformer: cache.NewSharedIndexInformer(&cache.ListWatch{
 			ListFunc: func(options metav1.ListOptions) (runtime.Object, error) {
-				options.FieldSelector = fieldSelector.String()
 				return clientSet.CoreV1().ConfigMaps(ketchv1.IngressConfigmapNamespace).List(context.Background(), options)
 			},
 			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
-				options.FieldSelector = fieldSelector.String()
 				return clientSet.CoreV1().ConfigMaps(ketchv1.IngressConfigmapNamespace).Watch(context.Background(), options)
 			},
 		},
@@ -85,12 +83,16 @@ func (i *IngressWatcher) Inform(ctx context.Context) error {
 			stop <- struct{}{}
 		}
 	}()
-	i.sharedIndexInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc: func(obj interface{}) {
-			i.handleAddUpdateIngressConfigmap(ctx, obj)
-		},
-		UpdateFunc: func(oldObj, newObj interface{}) {
-			i.handleAddUpdateIngressConfigmap(ctx, newObj)
+	// the informer lists all configmaps of the namespace, other configmaps are dropped before they reach the handler.
+	i.sharedIndexInformer.AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: isIngressConfigmap,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				i.handleAddUpdateIngressConfigmap(ctx, obj)
+			},
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				i.handleAddUpdateIngressConfigmap(ctx, newObj)
+			},
 		},
 	})
 	go i.sharedIndexInformer.Run(stop)
@@ -100,12 +102,19 @@ func (i *IngressWatcher) Inform(ctx context.Context) error {
 	return nil
 }
 
-func (i *IngressWatcher) handleAddUpdateIngressConfigmap(ctx context.Context, obj interface{}) {
+// isIngressConfigmap returns true if the object is a configmap of an ingress profile.
+func isIngressConfigmap(obj interface{}) bool {
 	configmap, ok := obj.(*v1.ConfigMap)
 	if !ok { // not configmap
-		return
+		return false
 	}
-	if configmap.Name != ketchv1.IngressConfigmapName { // not ingress configmap
+	_, ok = ketchv1.IngressProfileFromConfigmapName(configmap.Name)
+	return ok
+}
+
+func (i *IngressWatcher) handleAddUpdateIngressConfigmap(ctx context.Context, obj interface{}) {
+	configmap, ok := obj.(*v1.ConfigMap)
+	if !ok { // not configmap
 		return
 	}
 	ingressControllerSpec := ketchv1.NewIngressControllerSpec(*configmap)
@@ -125,9 +134,13 @@ func (i *IngressWatcher) updateAppsIngress(ctx context.Context, ingressControlle
 	}
 
 	for _, app := range appList.Items {
+		if app.Spec.Ingress.Controller.Profile != ingressControllerSpec.Profile {
+			// the app is bound to another ingress profile.
+			continue
+		}
 		app.Spec.Ingress.Controller = ingressControllerSpec
-		if !isSupported(app.Spec.Ingress.Controller.IngressType) {
-			return fmt.Errorf("unsupported value. supported values: traefik, istio, nginx")
+		if !app.Spec.Ingress.Controller.IngressType.IsSupported() {
+			return fmt.Errorf("unsupported value. supported values: %s", supportedIngressControllerTypes())
 		}
 		i.logger.Info("updating app ingress controller", "app", app.Name, "ingress controller", ingressControllerSpec)
 		if err := i.client.Update(ctx, &app); err != nil {
@@ -138,8 +151,10 @@ func (i *IngressWatcher) updateAppsIngress(ctx context.Context, ingressControlle
 	return nil
 }
 
-func isSupported(ingressType ketchv1.IngressControllerType) bool {
-	return ingressType == ketchv1.NginxIngressControllerType ||
-		ingressType == ketchv1.IstioIngressControllerType ||
-		ingressType == ketchv1.TraefikIngressControllerType
+func supportedIngressControllerTypes() string {
+	types := make([]string, 0, len(ketchv1.SupportedIngressControllerTypes))
+	for _, t := range ketchv1.SupportedIngressControllerTypes {
+		types = append(types, t.String())
+	}
+	return strings.Join(types, ", ")
 }
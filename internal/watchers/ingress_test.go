@@ -207,7 +207,7 @@ func Test_handleAddUpdateIngressConfigmap(t *testing.T) {
 				ObjectMeta: metav1.ObjectMeta{Name: ketchv1.IngressConfigmapName},
 				Data:       map[string]string{},
 			},
-			expected: "unsupported value. supported values: traefik, istio, nginx",
+			expected: "unsupported value. supported values: traefik, istio, nginx, gateway-api",
 		},
 	}
 
@@ -224,6 +224,41 @@ func Test_handleAddUpdateIngressConfigmap(t *testing.T) {
 	}
 }
 
+func Test_isIngressConfigmap(t *testing.T) {
+	tests := []struct {
+		description string
+		obj         interface{}
+		want        bool
+	}{
+		{
+			description: "default ingress configmap",
+			obj:         &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: ketchv1.IngressConfigmapName}},
+			want:        true,
+		},
+		{
+			description: "ingress profile configmap",
+			obj:         &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: ketchv1.IngressConfigmapName + "-internal"}},
+			want:        true,
+		},
+		{
+			description: "unrelated configmap",
+			obj:         &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "kube-root-ca.crt"}},
+		},
+		{
+			description: "not a configmap",
+			obj:         &v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: ketchv1.IngressConfigmapName}},
+		},
+		{
+			description: "no object",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			require.Equal(t, tc.want, isIngressConfigmap(tc.obj))
+		})
+	}
+}
+
 func TestUpdateAppIngress(t *testing.T) {
 	defaultObjects := []client.Object{
 		&ketchv1.App{
@@ -256,7 +291,11 @@ func TestUpdateAppIngress(t *testing.T) {
 		{
 			description:           "fail at missing ingress controller fields",
 			ingressControllerSpec: ketchv1.IngressControllerSpec{},
-			expectedErr:           "unsupported value. supported values: traefik, istio, nginx",
+			expectedErr:           "unsupported value. supported values: traefik, istio, nginx, gateway-api",
+		},
+		{
+			description:           "apps of other profiles are skipped",
+			ingressControllerSpec: ketchv1.IngressControllerSpec{Profile: "internal"},
 		},
 		{
 			description: "success",